@@ -0,0 +1,127 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/aggregate"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/tablecolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+)
+
+// setupAggregationOutput is the counterpart of setupParserOutput used when --group-by or --agg are given: instead
+// of printing events, the parser aggregates them and periodically outputs the results as a table
+func setupAggregationOutput(
+	fe frontends.Frontend,
+	parser parser.Parser,
+	outputModeName string,
+	outputModeParams string,
+	filters []string,
+	groupBy []string,
+	aggregations []string,
+	interval time.Duration,
+) error {
+	if len(filters) > 0 {
+		err := parser.SetFilters(filters)
+		if err != nil {
+			return fmt.Errorf("setting filters: %w", err)
+		}
+	}
+
+	spec, err := aggregate.NewSpec(groupBy, aggregations)
+	if err != nil {
+		return err
+	}
+
+	parser.SetLogCallback(fe.Logf)
+
+	var callback func([]*aggregate.Row)
+
+	switch outputModeName {
+	default:
+		return fmt.Errorf("output mode %q is not supported when aggregating events", outputModeName)
+	case OutputModeColumns:
+		unitsOpts, err := unitsOptions()
+		if err != nil {
+			return err
+		}
+
+		formatter := textcolumns.NewFormatter(spec.Columns().GetColumnMap(), unitsOpts...)
+		if outputModeParams != "" {
+			if err := formatter.SetShowColumns(strings.Split(outputModeParams, ",")); err != nil {
+				return err
+			}
+		}
+
+		callback = func(rows []*aggregate.Row) {
+			fe.Clear()
+			fe.Output(formatter.FormatHeader())
+			for _, row := range rows {
+				fe.Output(formatter.FormatEntry(row))
+			}
+		}
+
+		// Print first header while we wait for input
+		if fe.IsTerminal() {
+			fe.Clear()
+			fe.Output(formatter.FormatHeader())
+		}
+	case OutputModeCSV, OutputModeTSV, OutputModeMarkdown:
+		format := tableOutputModes[outputModeName]
+		formatter := tablecolumns.NewFormatter(spec.Columns().GetColumnMap(), tablecolumns.WithFormat(format))
+		if outputModeParams != "" {
+			if err := formatter.SetShowColumns(strings.Split(outputModeParams, ",")); err != nil {
+				return err
+			}
+		}
+
+		// CSV and TSV rows of all intervals are appended to the same table, Markdown starts a new table every time
+		fe.Output(formatter.FormatHeader())
+		first := true
+		callback = func(rows []*aggregate.Row) {
+			if !first && format == tablecolumns.FormatMarkdown {
+				fe.Output("")
+				fe.Output(formatter.FormatHeader())
+			}
+			first = false
+			for _, row := range rows {
+				fe.Output(formatter.FormatEntry(row))
+			}
+		}
+	case OutputModeJSON, OutputModeJSONPretty, OutputModeYAML:
+		printFn := printEventAsJSONFn(fe)
+		switch outputModeName {
+		case OutputModeJSONPretty:
+			printFn = printEventAsJSONPrettyFn(fe)
+		case OutputModeYAML:
+			printFn = printEventAsYAMLFn(fe)
+		}
+
+		callback = func(rows []*aggregate.Row) {
+			out := make([]map[string]any, 0, len(rows))
+			for _, row := range rows {
+				out = append(out, spec.RowToMap(row))
+			}
+			printFn(out)
+		}
+	}
+
+	return parser.EnableAggregation(fe.GetContext(), interval, spec, callback)
+}
@@ -0,0 +1,195 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	k8syaml "sigs.k8s.io/yaml"
+
+	cols "github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/jsonschema"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+// catalogExport is the machine-readable representation of all gadgets, operators and params known to a runtime
+type catalogExport struct {
+	Runtime   *runtimeExport    `json:"runtime"`
+	Operators []*operatorExport `json:"operators"`
+	Gadgets   []*gadgetExport   `json:"gadgets"`
+}
+
+type runtimeExport struct {
+	GlobalParams params.ParamDescs `json:"globalParams"`
+	Params       params.ParamDescs `json:"params"`
+}
+
+type operatorExport struct {
+	Name         string            `json:"name"`
+	Description  string            `json:"description"`
+	GlobalParams params.ParamDescs `json:"globalParams,omitempty"`
+}
+
+type gadgetExport struct {
+	Category       string                `json:"category"`
+	Name           string                `json:"name"`
+	Type           string                `json:"type"`
+	Description    string                `json:"description"`
+	Params         params.ParamDescs     `json:"params"`
+	OperatorParams params.DescCollection `json:"operatorParams,omitempty"`
+
+	// Schema describes the events of the gadget as they are printed by '-o json'; it's only available for gadgets
+	// that emit events and are known to this binary
+	Schema *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+// catalogRow is used to print the catalog using columns
+type catalogRow struct {
+	Category    string `column:"category"`
+	Name        string `column:"name"`
+	Type        string `column:"type"`
+	Description string `column:"description,width:60"`
+}
+
+// exportCatalog builds a catalogExport from the catalog of the given runtime
+func exportCatalog(runtime runtime.Runtime) (*catalogExport, error) {
+	catalog, err := runtime.GetCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("getting catalog: %w", err)
+	}
+	if catalog == nil {
+		return nil, fmt.Errorf("runtime doesn't provide a catalog")
+	}
+
+	export := &catalogExport{
+		Runtime: &runtimeExport{
+			GlobalParams: runtime.GlobalParamDescs(),
+			Params:       runtime.ParamDescs(),
+		},
+		Operators: make([]*operatorExport, 0, len(catalog.Operators)),
+		Gadgets:   make([]*gadgetExport, 0, len(catalog.Gadgets)),
+	}
+
+	for _, operatorInfo := range catalog.Operators {
+		operator := &operatorExport{
+			Name:        operatorInfo.Name,
+			Description: operatorInfo.Description,
+		}
+		if op := operators.GetRaw(operatorInfo.Name); op != nil {
+			operator.GlobalParams = op.GlobalParamDescs()
+		}
+		export.Operators = append(export.Operators, operator)
+	}
+
+	for _, gadgetInfo := range catalog.Gadgets {
+		gadget := &gadgetExport{
+			Category:       gadgetInfo.Category,
+			Name:           gadgetInfo.Name,
+			Type:           gadgetInfo.Type,
+			Description:    gadgetInfo.Description,
+			Params:         gadgetInfo.Params,
+			OperatorParams: gadgetInfo.OperatorParamsCollection,
+		}
+
+		// Add information that is only available locally
+		if gadgetDesc := gadgetregistry.Get(gadgetInfo.Category, gadgetInfo.Name); gadgetDesc != nil {
+			parser := gadgetDesc.Parser()
+			gadget.Params = append(append(params.ParamDescs{}, gadget.Params...), gadgets.GadgetParams(gadgetDesc, parser)...)
+			if parser != nil {
+				gadget.Schema = parser.JSONSchema(gadgetInfo.Category + " " + gadgetInfo.Name)
+			}
+		}
+
+		export.Gadgets = append(export.Gadgets, gadget)
+	}
+
+	sort.Slice(export.Operators, func(i, j int) bool {
+		return export.Operators[i].Name < export.Operators[j].Name
+	})
+	sort.Slice(export.Gadgets, func(i, j int) bool {
+		if export.Gadgets[i].Category != export.Gadgets[j].Category {
+			return export.Gadgets[i].Category < export.Gadgets[j].Category
+		}
+		return export.Gadgets[i].Name < export.Gadgets[j].Name
+	})
+
+	return export, nil
+}
+
+func addCatalogCommand(rootCmd *cobra.Command, runtime runtime.Runtime) {
+	var outputMode string
+
+	cmd := &cobra.Command{
+		Use:          "catalog",
+		Short:        "Show all available gadgets, operators and their params",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			export, err := exportCatalog(runtime)
+			if err != nil {
+				return err
+			}
+
+			var out []byte
+			switch outputMode {
+			case OutputModeColumns:
+				rows := make([]*catalogRow, 0, len(export.Gadgets))
+				for _, gadget := range export.Gadgets {
+					rows = append(rows, &catalogRow{
+						Category:    gadget.Category,
+						Name:        gadget.Name,
+						Type:        gadget.Type,
+						Description: gadget.Description,
+					})
+				}
+				formatter := textcolumns.NewFormatter(cols.MustCreateColumns[catalogRow]().GetColumnMap())
+				formatter.SetAutoScale(false)
+				formatter.AdjustWidthsToContent(rows, true, textcolumns.GetTerminalWidth(), true)
+				out = []byte(formatter.FormatTable(rows))
+			case OutputModeJSON:
+				out, err = json.Marshal(export)
+			case OutputModeJSONPretty:
+				out, err = json.MarshalIndent(export, "", "  ")
+			case OutputModeYAML:
+				out, err = k8syaml.Marshal(export)
+			default:
+				return fmt.Errorf("invalid output mode %q: expected %s, %s, %s or %s",
+					outputMode, OutputModeColumns, OutputModeJSON, OutputModeJSONPretty, OutputModeYAML)
+			}
+			if err != nil {
+				return fmt.Errorf("marshaling catalog: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(
+		&outputMode,
+		"output", "o",
+		OutputModeColumns,
+		fmt.Sprintf("Output format (%s, %s, %s, %s)", OutputModeColumns, OutputModeJSON, OutputModeJSONPretty, OutputModeYAML),
+	)
+
+	rootCmd.AddCommand(cmd)
+}
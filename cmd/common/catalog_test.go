@@ -0,0 +1,144 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/jsonschema"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+type catalogTestEvent struct {
+	Comm  string `json:"comm" column:"comm" columnDesc:"Command name"`
+	Bytes uint64 `json:"bytes" column:"bytes,unit:bytes"`
+}
+
+type catalogTestGadget struct{}
+
+func (g *catalogTestGadget) Name() string             { return "catalogtest" }
+func (g *catalogTestGadget) Description() string      { return "Gadget used to test the catalog" }
+func (g *catalogTestGadget) Category() string         { return gadgets.CategoryTop }
+func (g *catalogTestGadget) Type() gadgets.GadgetType { return gadgets.TypeTraceIntervals }
+func (g *catalogTestGadget) EventPrototype() any      { return &catalogTestEvent{} }
+
+func (g *catalogTestGadget) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{
+		{Key: "mode", DefaultValue: "a", PossibleValues: []string{"a", "b"}},
+	}
+}
+
+func (g *catalogTestGadget) Parser() parser.Parser {
+	return parser.NewParser[catalogTestEvent](columns.MustCreateColumns[catalogTestEvent]())
+}
+
+type catalogTestRuntime struct {
+	runtime.Runtime
+}
+
+func (r *catalogTestRuntime) GlobalParamDescs() params.ParamDescs {
+	return params.ParamDescs{{Key: "global"}}
+}
+
+func (r *catalogTestRuntime) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{{Key: "node"}}
+}
+
+func (r *catalogTestRuntime) GetCatalog() (*runtime.Catalog, error) {
+	return &runtime.Catalog{
+		Gadgets: []*runtime.GadgetInfo{
+			runtime.GadgetInfoFromGadgetDesc(&catalogTestGadget{}),
+			// Only known to the remote side
+			{Category: gadgets.CategoryTrace, Name: "remote", Type: string(gadgets.TypeTrace)},
+		},
+		Operators: []*runtime.OperatorInfo{{Name: "unknown", Description: "unknown operator"}},
+	}, nil
+}
+
+func init() {
+	gadgetregistry.Register(&catalogTestGadget{})
+}
+
+func TestExportCatalog(t *testing.T) {
+	export, err := exportCatalog(&catalogTestRuntime{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "global", export.Runtime.GlobalParams[0].Key)
+	assert.Equal(t, "node", export.Runtime.Params[0].Key)
+	require.Len(t, export.Operators, 1)
+	assert.Equal(t, "unknown", export.Operators[0].Name)
+
+	require.Len(t, export.Gadgets, 2)
+
+	gadget := export.Gadgets[0]
+	assert.Equal(t, gadgets.CategoryTop, gadget.Category)
+	assert.Equal(t, "catalogtest", gadget.Name)
+
+	keys := make([]string, 0)
+	for _, p := range gadget.Params {
+		keys = append(keys, p.Key)
+	}
+	assert.Equal(t, []string{"mode", gadgets.ParamInterval, gadgets.ParamMaxRows, gadgets.ParamSortBy}, keys)
+	assert.Equal(t, []string{"a", "b"}, gadget.Params[0].PossibleValues)
+
+	require.NotNil(t, gadget.Schema)
+	assert.Equal(t, "top catalogtest", gadget.Schema.Title)
+	require.Contains(t, gadget.Schema.Properties, "comm")
+	assert.Equal(t, "Command name", gadget.Schema.Properties["comm"].Description)
+	assert.Equal(t, jsonschema.Type{"integer"}, gadget.Schema.Properties["bytes"].Type)
+	assert.Equal(t, columns.UnitBytes, gadget.Schema.Properties["bytes"].Unit)
+
+	// Gadgets unknown to this binary are still exported, but without a schema
+	assert.Equal(t, "remote", export.Gadgets[1].Name)
+	assert.Nil(t, export.Gadgets[1].Schema)
+}
+
+func TestCatalogCommand(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "test"}
+	addCatalogCommand(rootCmd, &catalogTestRuntime{})
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(append([]string{"catalog"}, args...))
+		err := rootCmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("-o", "json")
+	require.NoError(t, err)
+	export := &catalogExport{}
+	require.NoError(t, json.Unmarshal([]byte(out), export))
+	assert.Len(t, export.Gadgets, 2)
+
+	out, err = run("-o", "columns")
+	require.NoError(t, err)
+	assert.Contains(t, out, "CATEGORY")
+	assert.Contains(t, out, "catalogtest")
+
+	_, err = run("-o", "foo")
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+)
+
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// colorColumns are the columns that get a color depending on their value, so that interleaved events of different
+// pods and containers can be told apart more easily
+var colorColumns = []string{"namespace", "pod", "container"}
+
+var colorMode string
+
+func addColorFlag(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().StringVar(
+		&colorMode,
+		"color",
+		ColorAuto,
+		fmt.Sprintf("Colorize the columns output: %s, %s or %s. %s disables colors if the output is not a terminal or NO_COLOR is set",
+			ColorAuto, ColorAlways, ColorNever, ColorAuto),
+	)
+}
+
+// colorOptions returns the textcolumns options to colorize the output of the given gadget according to the --color
+// flag
+func colorOptions(fe frontends.Frontend, gadgetDesc gadgets.GadgetDesc) ([]textcolumns.Option, error) {
+	switch colorMode {
+	case ColorAuto:
+		if !fe.IsTerminal() || os.Getenv("NO_COLOR") != "" {
+			return nil, nil
+		}
+	case ColorAlways:
+	case ColorNever:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid value %q for --color: expected %s, %s or %s", colorMode, ColorAuto, ColorAlways, ColorNever)
+	}
+
+	opts := []textcolumns.Option{
+		textcolumns.WithColors(true),
+		textcolumns.WithColorColumns(colorColumns),
+	}
+	if colorRules, ok := gadgetDesc.(gadgets.GadgetColorRules); ok {
+		opts = append(opts, textcolumns.WithColorRules(colorRules.ColorRules()))
+	}
+	return opts, nil
+}
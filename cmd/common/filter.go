@@ -0,0 +1,67 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/filter"
+)
+
+const filterUsage = `Filter rules
+  A filter can match any column using the following syntax
+    columnName:value       - matches, if the content of columnName equals exactly value
+    columnName:!value      - matches, if the content of columnName does not equal exactly value
+    columnName:>=value     - matches, if the content of columnName is greater than or equal to the value
+    columnName:>value      - matches, if the content of columnName is greater than the value
+    columnName:<=value     - matches, if the content of columnName is less than or equal to the value
+    columnName:<value      - matches, if the content of columnName is less than the value
+    columnName:~value      - matches, if the content of columnName matches the regular expression 'value'
+                             see [https://github.com/google/re2/wiki/Syntax] for more information on the syntax
+  Alternatively, an expression can be used to combine rules across columns, e.g.
+    comm == "curl" && (port in (80,443) || dst ~ "10.0.0.0/8")
+  Supported operators: ==, !=, <, <=, >, >=, ~ (regular expression or CIDR), !~, in (...), contains,
+  startswith and endswith; rules can be combined using &&, || and ! and grouped using parentheses
+`
+
+// filtersValue is a pflag.Value for a list of filters; unlike a plain string slice, it doesn't split filter
+// expressions at commas inside parentheses or quotes
+type filtersValue struct {
+	filters *[]string
+	changed bool
+}
+
+func newFiltersValue(filters *[]string) *filtersValue {
+	return &filtersValue{filters: filters}
+}
+
+func (f *filtersValue) Set(val string) error {
+	filters := filter.SplitFilters(val)
+	if !f.changed {
+		*f.filters = filters
+	} else {
+		*f.filters = append(*f.filters, filters...)
+	}
+	f.changed = true
+	return nil
+}
+
+func (f *filtersValue) Type() string {
+	return "strings"
+}
+
+func (f *filtersValue) String() string {
+	return "[" + strings.Join(*f.filters, ",") + "]"
+}
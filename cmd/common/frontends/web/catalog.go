@@ -0,0 +1,174 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+const (
+	runtimePrefix  = "runtime."
+	operatorPrefix = "operator."
+)
+
+// catalog is the information sent to the browser to render the list of gadgets and their forms
+type catalog struct {
+	Categories map[string]string `json:"categories"`
+	Gadgets    []*gadgetInfo     `json:"gadgets"`
+}
+
+type gadgetInfo struct {
+	Category       string        `json:"category"`
+	Name           string        `json:"name"`
+	Type           string        `json:"type"`
+	Description    string        `json:"description"`
+	Params         []*paramInfo  `json:"params"`
+	Columns        []*columnInfo `json:"columns,omitempty"`
+	DefaultColumns []string      `json:"defaultColumns,omitempty"`
+}
+
+// columnInfo holds the attributes of a column that are needed to render it in the browser
+type columnInfo struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	AlignRight  bool         `json:"alignRight,omitempty"`
+	Unit        columns.Unit `json:"unit,omitempty"`
+}
+
+// paramInfo describes a single param of a gadget; Prefix has to be prepended to the key of the param when sending
+// the values back to run the gadget (see gadgets.ParamsFromMap)
+type paramInfo struct {
+	*params.ParamDesc
+	Prefix string `json:"prefix"`
+	Group  string `json:"group"`
+}
+
+func (s *Server) catalog() (*catalog, error) {
+	runtimeCatalog, err := s.runtime.GetCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("getting catalog: %w", err)
+	}
+
+	out := &catalog{
+		Categories: gadgets.GetCategories(),
+		Gadgets:    make([]*gadgetInfo, 0),
+	}
+	if runtimeCatalog == nil {
+		return out, nil
+	}
+
+	for _, info := range runtimeCatalog.Gadgets {
+		gadgetDesc := gadgetregistry.Get(info.Category, info.Name)
+		if gadgetDesc == nil {
+			// Gadgets only known to the remote side can't be handled, just like in the CLI
+			continue
+		}
+
+		var skipParams []params.ValueHint
+		if skipParamsInterface, ok := gadgetDesc.(gadgets.GadgetDescSkipParams); ok {
+			skipParams = skipParamsInterface.SkipParams()
+		}
+
+		gi := &gadgetInfo{
+			Category:    info.Category,
+			Name:        info.Name,
+			Type:        info.Type,
+			Description: info.Description,
+			Params:      make([]*paramInfo, 0),
+		}
+
+		parser := gadgetDesc.Parser()
+		if parser != nil {
+			parser.SetColumnFilters(s.columnFilters...)
+			for _, attrs := range parser.GetColumnAttributes() {
+				gi.Columns = append(gi.Columns, &columnInfo{
+					Name:        attrs.Name,
+					Description: attrs.Description,
+					AlignRight:  attrs.Alignment == columns.AlignRight,
+					Unit:        attrs.Unit,
+				})
+			}
+			gi.DefaultColumns = parser.GetDefaultColumns()
+		}
+
+		gadgetParamDescs := gadgetDesc.ParamDescs()
+		gadgetParamDescs.Add(gadgets.GadgetParams(gadgetDesc, parser)...)
+		gi.Params = append(gi.Params, s.paramInfos(gadgetParamDescs, "", "Gadget", skipParams)...)
+		gi.Params = append(gi.Params, s.paramInfos(s.runtime.ParamDescs(), runtimePrefix, "Runtime", skipParams)...)
+
+		operatorNames := make([]string, 0, len(info.OperatorParamsCollection))
+		for operatorName := range info.OperatorParamsCollection {
+			operatorNames = append(operatorNames, operatorName)
+		}
+		sort.Strings(operatorNames)
+		for _, operatorName := range operatorNames {
+			operatorParamDescs := info.OperatorParamsCollection[operatorName]
+			if operatorParamDescs == nil {
+				continue
+			}
+			gi.Params = append(gi.Params, s.paramInfos(
+				*operatorParamDescs,
+				operatorPrefix+operatorName+".",
+				operatorName,
+				skipParams,
+			)...)
+		}
+
+		out.Gadgets = append(out.Gadgets, gi)
+	}
+
+	sort.Slice(out.Gadgets, func(i, j int) bool {
+		if out.Gadgets[i].Category != out.Gadgets[j].Category {
+			return out.Gadgets[i].Category < out.Gadgets[j].Category
+		}
+		return out.Gadgets[i].Name < out.Gadgets[j].Name
+	})
+
+	return out, nil
+}
+
+func (s *Server) paramInfos(paramDescs params.ParamDescs, prefix, group string, skipParams []params.ValueHint) []*paramInfo {
+	out := make([]*paramInfo, 0, len(paramDescs))
+paramLoop:
+	for _, paramDesc := range paramDescs {
+		if paramDesc.ValueHint != "" {
+			for _, skip := range skipParams {
+				if paramDesc.ValueHint == skip {
+					continue paramLoop
+				}
+			}
+		}
+
+		// Copy the description, so the default value can be adjusted by the runtime without side effects
+		desc := *paramDesc
+		if desc.ValueHint != "" {
+			if value, ok := s.runtime.GetDefaultValue(desc.ValueHint); ok {
+				desc.DefaultValue = value
+			}
+		}
+		out = append(out, &paramInfo{
+			ParamDesc: &desc,
+			Prefix:    prefix,
+			Group:     group,
+		})
+	}
+	return out
+}
@@ -0,0 +1,292 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// Types of messages sent to the browser
+const (
+	MessageTypeColumns = "columns" // names of the columns that the values of events refer to
+	MessageTypeEvent   = "event"   // a single event as list of values
+	MessageTypeEvents  = "events"  // a list of events replacing the previous ones (periodic gadgets)
+	MessageTypeOutput  = "output"  // text output
+	MessageTypeLog     = "log"     // a log message
+	MessageTypeClear   = "clear"   // request to clear the output
+	MessageTypeResult  = "result"  // result of a gadget for a node
+	MessageTypeError   = "error"   // the gadget failed; terminal message
+	MessageTypeDone    = "done"    // the gadget finished; terminal message
+)
+
+// runRequest is the first message sent by the browser to start a gadget; params use the same keys as
+// gadgets.ParamsFromMap expects them
+type runRequest struct {
+	Category string            `json:"category"`
+	Name     string            `json:"name"`
+	Params   map[string]string `json:"params"`
+	Filters  []string          `json:"filters"`
+	Args     []string          `json:"args"`
+	Timeout  int               `json:"timeout"`
+}
+
+type message struct {
+	Type  string `json:"type"`
+	Node  string `json:"node,omitempty"`
+	Level string `json:"level,omitempty"`
+	Data  any    `json:"data,omitempty"`
+}
+
+// session implements frontends.Frontend and logger.GenericLoggerWithLevelSetter for a single gadget run and
+// forwards everything to the browser. Messages are queued and dropped if the browser can't keep up, like in the
+// gadget service.
+type session struct {
+	conn   *websocket.Conn
+	ctx    context.Context
+	cancel func()
+	level  logger.Level
+
+	mu       sync.Mutex
+	closed   bool
+	out      chan *message
+	pumpDone chan struct{}
+}
+
+func newSession(conn *websocket.Conn, level logger.Level) *session {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &session{
+		conn:     conn,
+		ctx:      ctx,
+		cancel:   cancel,
+		level:    level,
+		out:      make(chan *message, 1024),
+		pumpDone: make(chan struct{}),
+	}
+	go s.pump()
+	return s
+}
+
+func (s *session) pump() {
+	defer close(s.pumpDone)
+	for msg := range s.out {
+		websocket.JSON.Send(s.conn, msg)
+	}
+}
+
+func (s *session) send(msg *message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.out <- msg:
+	default:
+	}
+}
+
+// finish sends all queued messages followed by msg; nothing can be sent afterwards
+func (s *session) finish(msg *message) {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.out)
+	}
+	s.mu.Unlock()
+
+	<-s.pumpDone
+	websocket.JSON.Send(s.conn, msg)
+}
+
+func (s *session) Output(payload string) {
+	s.send(&message{Type: MessageTypeOutput, Data: payload})
+}
+
+func (s *session) Logf(severity logger.Level, format string, params ...any) {
+	if s.level < severity {
+		return
+	}
+	s.send(&message{Type: MessageTypeLog, Level: severity.String(), Data: fmt.Sprintf(format, params...)})
+}
+
+func (s *session) Log(severity logger.Level, params ...any) {
+	if s.level < severity {
+		return
+	}
+	s.send(&message{Type: MessageTypeLog, Level: severity.String(), Data: fmt.Sprint(params...)})
+}
+
+func (s *session) SetLevel(level logger.Level) {
+	s.level = level
+}
+
+func (s *session) GetLevel() logger.Level {
+	return s.level
+}
+
+func (s *session) IsTerminal() bool {
+	return false
+}
+
+func (s *session) Clear() {
+	s.send(&message{Type: MessageTypeClear})
+}
+
+func (s *session) Close() {
+	s.cancel()
+}
+
+func (s *session) GetContext() context.Context {
+	return s.ctx
+}
+
+func (s *Server) handleRun(conn *websocket.Conn) {
+	defer conn.Close()
+
+	sess := newSession(conn, s.logger.GetLevel())
+	defer sess.Close()
+
+	request := &runRequest{}
+	if err := websocket.JSON.Receive(conn, request); err != nil {
+		sess.finish(&message{Type: MessageTypeError, Data: fmt.Sprintf("reading request: %v", err)})
+		return
+	}
+
+	// Any further message or closing the connection stops the gadget
+	go func() {
+		var msg json.RawMessage
+		websocket.JSON.Receive(conn, &msg)
+		sess.Close()
+	}()
+
+	if err := s.run(sess, request); err != nil {
+		sess.finish(&message{Type: MessageTypeError, Data: err.Error()})
+		return
+	}
+	sess.finish(&message{Type: MessageTypeDone})
+}
+
+func (s *Server) run(sess *session, request *runRequest) error {
+	gadgetDesc := gadgetregistry.Get(request.Category, request.Name)
+	if gadgetDesc == nil {
+		return fmt.Errorf("gadget not found: %s/%s", request.Category, request.Name)
+	}
+
+	ops := operators.GetOperatorsForGadget(gadgetDesc)
+	if err := s.initOperators(ops); err != nil {
+		return err
+	}
+
+	operatorParams := ops.ParamCollection()
+
+	parser := gadgetDesc.Parser()
+
+	runtimeParams := s.runtime.ParamDescs().ToParams()
+
+	gadgetParamDescs := gadgetDesc.ParamDescs()
+	gadgetParamDescs.Add(gadgets.GadgetParams(gadgetDesc, parser)...)
+	gadgetParams := gadgetParamDescs.ToParams()
+	err := gadgets.ParamsFromMap(request.Params, gadgetParams, runtimeParams, operatorParams)
+	if err != nil {
+		return fmt.Errorf("setting parameters: %w", err)
+	}
+
+	if c, ok := gadgetDesc.(gadgets.GadgetDescCustomParser); ok {
+		parser, err = c.CustomParser(gadgetParams, request.Args)
+		if err != nil {
+			return fmt.Errorf("calling custom parser: %w", err)
+		}
+	}
+
+	if parser != nil {
+		parser.SetColumnFilters(s.columnFilters...)
+
+		if err := parser.SetFilters(request.Filters); err != nil {
+			return fmt.Errorf("setting filters: %w", err)
+		}
+
+		columnNames := make([]string, 0)
+		for _, attrs := range parser.GetColumnAttributes() {
+			columnNames = append(columnNames, attrs.Name)
+		}
+		values, err := parser.ValuesFunc(columnNames)
+		if err != nil {
+			return err
+		}
+		sess.send(&message{Type: MessageTypeColumns, Data: columnNames})
+
+		parser.SetLogCallback(sess.Logf)
+		parser.SetEventCallback(func(ev any) {
+			v := reflect.ValueOf(ev)
+			if v.Kind() != reflect.Slice {
+				sess.send(&message{Type: MessageTypeEvent, Data: values(ev)})
+				return
+			}
+			rows := make([][]string, 0, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				rows = append(rows, values(v.Index(i).Interface()))
+			}
+			sess.send(&message{Type: MessageTypeEvents, Data: rows})
+		})
+	}
+
+	gadgetCtx := gadgetcontext.New(
+		sess.GetContext(),
+		"",
+		s.runtime,
+		runtimeParams,
+		gadgetDesc,
+		gadgetParams,
+		request.Args,
+		operatorParams,
+		parser,
+		logger.NewFromGenericLogger(sess),
+		time.Duration(request.Timeout)*time.Second,
+	)
+	defer gadgetCtx.Cancel()
+
+	// Partial results are sent before returning the error
+	results, err := s.runtime.RunGadget(gadgetCtx)
+	for node, result := range results {
+		msg := &message{Type: MessageTypeResult, Node: node}
+		switch {
+		case result.Error != nil:
+			msg.Level = logger.ErrorLevel.String()
+			msg.Data = result.Error.Error()
+		case json.Valid(result.Payload):
+			msg.Data = json.RawMessage(result.Payload)
+		default:
+			msg.Data = string(result.Payload)
+		}
+		sess.send(msg)
+	}
+	if err != nil {
+		return fmt.Errorf("running gadget: %w", err)
+	}
+	return nil
+}
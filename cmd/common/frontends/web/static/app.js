@@ -0,0 +1,497 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+'use strict';
+
+// Maximum number of events kept for trace gadgets; older events are dropped
+const maxRows = 5000;
+
+const $ = (id) => document.getElementById(id);
+
+const state = {
+  catalog: null,
+  gadget: null,
+  socket: null,
+  columns: [],
+  columnInfo: {},
+  shown: new Set(),
+  rows: [],
+  sortColumn: -1,
+  sortDesc: false,
+  filter: [],
+  renderPending: false,
+};
+
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  Object.assign(e, attrs || {});
+  for (const child of children) {
+    e.append(child);
+  }
+  return e;
+}
+
+// Formatting of values with units; values of these columns are sent as raw numbers
+
+function formatScaled(value, base, prefixes, suffix) {
+  let i = 0;
+  while (Math.abs(value) >= base && i < prefixes.length - 1) {
+    value /= base;
+    i++;
+  }
+  const digits = i === 0 ? 0 : 1;
+  return value.toFixed(digits) + prefixes[i] + suffix;
+}
+
+function formatNanoseconds(ns) {
+  const units = [[1e9 * 60 * 60, 'h'], [1e9 * 60, 'm'], [1e9, 's'], [1e6, 'ms'], [1e3, 'µs']];
+  for (const [factor, unit] of units) {
+    if (Math.abs(ns) >= factor) {
+      return (ns / factor).toFixed(2).replace(/\.?0+$/, '') + unit;
+    }
+  }
+  return ns + 'ns';
+}
+
+function formatValue(column, value) {
+  const info = state.columnInfo[column] || {};
+  const n = Number(value);
+  if (!info.unit || value === '' || isNaN(n)) {
+    return value;
+  }
+  switch (info.unit) {
+    case 'bytes':
+      return formatScaled(n, 1024, ['B', 'KiB', 'MiB', 'GiB', 'TiB', 'PiB', 'EiB'], '');
+    case 'ns':
+      return formatNanoseconds(n);
+    case 'rate':
+      return formatScaled(n, 1000, ['', 'k', 'M', 'G', 'T', 'P', 'E'], '/s');
+    case 'timestamp':
+      return n === 0 ? '' : new Date(n / 1e6).toISOString();
+  }
+  return value;
+}
+
+// Catalog and forms
+
+async function loadCatalog() {
+  const res = await fetch('api/catalog');
+  if (!res.ok) {
+    $('gadgets').textContent = 'Loading catalog failed: ' + (await res.text());
+    return;
+  }
+  state.catalog = await res.json();
+  renderCatalog();
+}
+
+function renderCatalog() {
+  const search = $('search').value.toLowerCase();
+  const container = $('gadgets');
+  container.replaceChildren();
+
+  let lastCategory = null;
+  for (const gadget of state.catalog.gadgets) {
+    const fullName = (gadget.category + ' ' + gadget.name).toLowerCase();
+    if (search && !fullName.includes(search) && !gadget.description.toLowerCase().includes(search)) {
+      continue;
+    }
+    if (gadget.category !== lastCategory) {
+      lastCategory = gadget.category;
+      container.append(el('h3', {
+        textContent: gadget.category,
+        title: state.catalog.categories[gadget.category] || '',
+      }));
+    }
+    const link = el('a', { textContent: gadget.name, title: gadget.description });
+    if (gadget === state.gadget) {
+      link.classList.add('active');
+    }
+    link.addEventListener('click', () => selectGadget(gadget));
+    container.append(link);
+  }
+}
+
+function selectGadget(gadget) {
+  stopGadget();
+  state.gadget = gadget;
+  renderCatalog();
+
+  $('welcome').hidden = true;
+  $('gadget').hidden = false;
+  $('gadget-title').textContent = gadget.category + ' ' + gadget.name;
+  $('gadget-description').textContent = gadget.description;
+
+  const form = $('params');
+  form.replaceChildren();
+
+  const groups = new Map();
+  for (const param of gadget.params) {
+    if (!groups.has(param.group)) {
+      groups.set(param.group, []);
+    }
+    groups.get(param.group).push(param);
+  }
+
+  // Settings handled by the web frontend itself
+  const general = el('fieldset', {}, el('legend', { textContent: 'General' }));
+  general.append(
+    el('label', { textContent: 'Filters', title: 'Comma-separated filters like on the command line, e.g. comm:cat' },
+      el('input', { id: 'param-filters', type: 'text' })),
+    el('label', { textContent: 'Timeout (seconds, 0 to run until stopped)' },
+      el('input', { id: 'param-timeout', type: 'number', min: 0, value: 0 })),
+    el('label', { textContent: 'Arguments', title: 'Space-separated arguments' },
+      el('input', { id: 'param-args', type: 'text' })),
+  );
+  form.append(general);
+
+  for (const [group, params] of groups) {
+    const fieldset = el('fieldset', {}, el('legend', { textContent: group }));
+    for (const param of params) {
+      fieldset.append(paramInput(param));
+    }
+    form.append(fieldset);
+  }
+
+  resetOutput();
+}
+
+function paramInput(param) {
+  const title = param.title || param.key;
+  const id = 'param-' + param.prefix + param.key;
+  let input;
+  if (param.type === 'bool') {
+    input = el('input', { id, type: 'checkbox', checked: param.defaultValue === 'true' });
+    return el('label', { className: 'checkbox', title: param.description }, input, title);
+  }
+  if (param.possibleValues && param.possibleValues.length > 0) {
+    input = el('select', { id });
+    for (const value of param.possibleValues) {
+      input.append(el('option', { value, textContent: value, selected: value === param.defaultValue }));
+    }
+  } else {
+    input = el('input', { id, type: 'text', value: param.defaultValue, required: param.isMandatory });
+  }
+  return el('label', { textContent: title, title: param.description }, input);
+}
+
+function collectRequest() {
+  const gadget = state.gadget;
+  const params = {};
+  for (const param of gadget.params) {
+    const input = $('param-' + param.prefix + param.key);
+    params[param.prefix + param.key] = input.type === 'checkbox' ? String(input.checked) : input.value;
+  }
+  const split = (value, sep) => value.split(sep).map((s) => s.trim()).filter((s) => s !== '');
+  return {
+    category: gadget.category,
+    name: gadget.name,
+    params,
+    filters: split($('param-filters').value, ','),
+    args: split($('param-args').value, /\s+/),
+    timeout: Number($('param-timeout').value) || 0,
+  };
+}
+
+// Running gadgets
+
+function runGadget() {
+  stopGadget();
+  resetOutput();
+
+  const request = collectRequest();
+  const protocol = location.protocol === 'https:' ? 'wss:' : 'ws:';
+  const socket = new WebSocket(protocol + '//' + location.host + location.pathname.replace(/[^/]*$/, '') + 'api/run');
+  state.socket = socket;
+
+  socket.onopen = () => {
+    socket.send(JSON.stringify(request));
+    setStatus('Running');
+    $('run').disabled = true;
+    $('stop').disabled = false;
+  };
+  socket.onmessage = (ev) => handleMessage(JSON.parse(ev.data));
+  socket.onclose = () => {
+    if (state.socket === socket) {
+      state.socket = null;
+      $('run').disabled = false;
+      $('stop').disabled = true;
+      if ($('status').textContent === 'Running') {
+        setStatus('Stopped');
+      }
+    }
+  };
+}
+
+function stopGadget() {
+  if (state.socket) {
+    state.socket.close();
+  }
+}
+
+function setStatus(text, isError) {
+  $('status').textContent = text;
+  $('status').classList.toggle('error', !!isError);
+}
+
+function handleMessage(msg) {
+  switch (msg.type) {
+    case 'columns':
+      setColumns(msg.data);
+      break;
+    case 'event':
+      state.rows.push(msg.data);
+      if (state.rows.length > maxRows) {
+        state.rows.splice(0, state.rows.length - maxRows);
+      }
+      scheduleRender();
+      break;
+    case 'events':
+      state.rows = msg.data || [];
+      scheduleRender();
+      break;
+    case 'clear':
+      state.rows = [];
+      $('text').textContent = '';
+      scheduleRender();
+      break;
+    case 'output':
+      $('text').hidden = false;
+      $('text').textContent += msg.data + '\n';
+      break;
+    case 'log':
+      addLog(msg.level, msg.data);
+      break;
+    case 'result':
+      renderResult(msg);
+      break;
+    case 'error':
+      setStatus('Error: ' + msg.data, true);
+      break;
+    case 'done':
+      setStatus('Done');
+      break;
+  }
+}
+
+function addLog(level, text) {
+  $('logs').append(el('li', { className: level || '', textContent: (level ? level + ': ' : '') + text }));
+}
+
+function resetOutput() {
+  state.columns = [];
+  state.rows = [];
+  state.sortColumn = -1;
+  state.sortDesc = false;
+  $('events').hidden = true;
+  $('table-controls').hidden = true;
+  $('results').replaceChildren();
+  $('text').textContent = '';
+  $('text').hidden = true;
+  $('logs').replaceChildren();
+  setStatus('');
+}
+
+// Table handling
+
+function setColumns(columns) {
+  state.columns = columns;
+  state.columnInfo = {};
+  for (const info of state.gadget.columns || []) {
+    state.columnInfo[info.name] = info;
+  }
+  const defaults = state.gadget.defaultColumns || [];
+  state.shown = new Set(defaults.length > 0 ? defaults : columns);
+
+  const list = $('column-list');
+  list.replaceChildren();
+  for (const column of columns) {
+    const checkbox = el('input', { type: 'checkbox', checked: state.shown.has(column) });
+    checkbox.addEventListener('change', () => {
+      if (checkbox.checked) {
+        state.shown.add(column);
+      } else {
+        state.shown.delete(column);
+      }
+      renderTable();
+    });
+    const info = state.columnInfo[column] || {};
+    list.append(el('label', { className: 'checkbox', title: info.description || '' }, checkbox, column));
+  }
+
+  $('events').hidden = false;
+  $('table-controls').hidden = false;
+  renderTable();
+}
+
+// parseFilter splits the filter input into terms; "column:value" matches a substring of a column, other terms
+// match any column. A leading "!" negates a term.
+function parseFilter(text) {
+  return text.split(/\s+/).filter((t) => t !== '').map((term) => {
+    const negate = term.startsWith('!');
+    if (negate) {
+      term = term.substring(1);
+    }
+    const sep = term.indexOf(':');
+    const index = sep > 0 ? state.columns.indexOf(term.substring(0, sep)) : -1;
+    return {
+      negate,
+      index,
+      value: (index >= 0 ? term.substring(sep + 1) : term).toLowerCase(),
+    };
+  });
+}
+
+function rowMatches(row) {
+  for (const term of state.filter) {
+    let match;
+    if (term.index >= 0) {
+      match = String(row[term.index]).toLowerCase().includes(term.value);
+    } else {
+      match = row.some((value) => String(value).toLowerCase().includes(term.value));
+    }
+    if (match === term.negate) {
+      return false;
+    }
+  }
+  return true;
+}
+
+function compareValues(a, b) {
+  const na = Number(a);
+  const nb = Number(b);
+  if (a !== '' && b !== '' && !isNaN(na) && !isNaN(nb)) {
+    return na - nb;
+  }
+  return String(a).localeCompare(String(b));
+}
+
+function scheduleRender() {
+  if (state.renderPending) {
+    return;
+  }
+  state.renderPending = true;
+  requestAnimationFrame(() => {
+    state.renderPending = false;
+    renderTable();
+  });
+}
+
+function renderTable() {
+  const indexes = [];
+  state.columns.forEach((column, i) => {
+    if (state.shown.has(column)) {
+      indexes.push(i);
+    }
+  });
+
+  const headerRow = el('tr');
+  for (const i of indexes) {
+    const column = state.columns[i];
+    const info = state.columnInfo[column] || {};
+    let label = column.toUpperCase();
+    if (i === state.sortColumn) {
+      label += state.sortDesc ? ' ▼' : ' ▲';
+    }
+    const th = el('th', { textContent: label, title: info.description || '' });
+    if (info.alignRight) {
+      th.classList.add('right');
+    }
+    th.addEventListener('click', () => {
+      if (state.sortColumn === i) {
+        state.sortDesc = !state.sortDesc;
+      } else {
+        state.sortColumn = i;
+        state.sortDesc = false;
+      }
+      renderTable();
+    });
+    headerRow.append(th);
+  }
+  document.querySelector('#events thead').replaceChildren(headerRow);
+
+  let rows = state.rows.filter(rowMatches);
+  if (state.sortColumn >= 0) {
+    const i = state.sortColumn;
+    rows.sort((a, b) => compareValues(a[i], b[i]) * (state.sortDesc ? -1 : 1));
+  }
+
+  const body = document.createDocumentFragment();
+  for (const row of rows) {
+    const tr = el('tr');
+    for (const i of indexes) {
+      const column = state.columns[i];
+      const td = el('td', { textContent: formatValue(column, row[i]) });
+      if ((state.columnInfo[column] || {}).alignRight) {
+        td.classList.add('right');
+      }
+      tr.append(td);
+    }
+    body.append(tr);
+  }
+  document.querySelector('#events tbody').replaceChildren(body);
+  $('row-count').textContent = rows.length + ' / ' + state.rows.length + ' rows';
+}
+
+// Results of gadgets that don't emit events, e.g. profile gadgets
+
+function renderResult(msg) {
+  const container = el('div');
+  if (msg.node) {
+    container.append(el('h3', { textContent: msg.node }));
+  }
+  const data = msg.data;
+  if (msg.level) {
+    container.append(el('pre', { className: msg.level, textContent: data }));
+  } else if (data && Array.isArray(data.intervals)) {
+    container.append(renderHistogram(data));
+  } else if (data && Array.isArray(data.histograms)) {
+    for (const histogram of data.histograms) {
+      container.append(renderHistogram(histogram));
+    }
+  } else {
+    container.append(el('pre', { textContent: typeof data === 'string' ? data : JSON.stringify(data, null, 2) }));
+  }
+  $('results').append(container);
+}
+
+function renderHistogram(histogram) {
+  const table = el('table', { className: 'histogram' });
+  if (histogram.address) {
+    table.append(el('caption', { textContent: histogram.address + (histogram.average ? ' (avg ' + histogram.average + ' ' + (histogram.unit || '') + ')' : '') }));
+  }
+  const unit = histogram.unit || '';
+  table.append(el('tr', {}, el('th', { textContent: unit }), el('th', { textContent: 'count' }), el('th', { textContent: 'distribution' })));
+
+  const intervals = histogram.intervals || [];
+  const max = Math.max(1, ...intervals.map((i) => i.count));
+  for (const interval of intervals) {
+    const bar = el('span', { className: 'bar' });
+    bar.style.width = Math.round((interval.count / max) * 400) + 'px';
+    table.append(el('tr', {},
+      el('td', { className: 'right', textContent: interval.start + ' -> ' + interval.end }),
+      el('td', { className: 'right', textContent: interval.count }),
+      el('td', {}, bar)));
+  }
+  return table;
+}
+
+$('search').addEventListener('input', renderCatalog);
+$('run').addEventListener('click', runGadget);
+$('stop').addEventListener('click', stopGadget);
+$('filter').addEventListener('input', () => {
+  state.filter = parseFilter($('filter').value);
+  renderTable();
+});
+
+loadCatalog();
@@ -0,0 +1,197 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package web implements a frontend that serves a web interface to list, configure and run gadgets in a browser.
+// Events are streamed to the browser using a WebSocket connection.
+package web
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// Server serves the web interface and runs gadgets on behalf of the browser using the given runtime
+type Server struct {
+	runtime                         runtime.Runtime
+	operatorsGlobalParamsCollection params.Collection
+	columnFilters                   []columns.ColumnFilter
+	logger                          logger.Logger
+
+	mu                   sync.Mutex
+	initializedOperators map[string]operators.Operator
+}
+
+// NewServer returns a new Server that runs gadgets using the given (already initialized) runtime. Operators are
+// initialized with operatorsGlobalParamsCollection when they're needed for the first time. columnFilters are
+// applied to the parsers of all gadgets, e.g. to hide kubernetes related columns in ig.
+func NewServer(
+	runtime runtime.Runtime,
+	operatorsGlobalParamsCollection params.Collection,
+	columnFilters []columns.ColumnFilter,
+) *Server {
+	return &Server{
+		runtime:                         runtime,
+		operatorsGlobalParamsCollection: operatorsGlobalParamsCollection,
+		columnFilters:                   columnFilters,
+		logger:                          logger.DefaultLogger(),
+		initializedOperators:            make(map[string]operators.Operator),
+	}
+}
+
+// initOperators initializes the given operators, if that hasn't happened before
+func (s *Server) initOperators(ops operators.Operators) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, op := range ops {
+		if _, ok := s.initializedOperators[op.Name()]; ok {
+			continue
+		}
+		if err := op.Init(s.operatorsGlobalParamsCollection[op.Name()]); err != nil {
+			return fmt.Errorf("initializing operator %q: %w", op.Name(), err)
+		}
+		s.initializedOperators[op.Name()] = op
+	}
+	return nil
+}
+
+// Close closes all operators that have been initialized by the server
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ops := make(operators.Operators, 0, len(s.initializedOperators))
+	for _, op := range s.initializedOperators {
+		ops = append(ops, op)
+	}
+	ops.Close()
+	s.initializedOperators = make(map[string]operators.Operator)
+}
+
+// Handler returns the http.Handler serving both the static files and the API
+func (s *Server) Handler() http.Handler {
+	static, _ := fs.Sub(staticFiles, "static")
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/api/catalog", s.handleCatalog)
+	mux.Handle("/api/run", websocket.Server{
+		Handshake: checkOrigin,
+		Handler:   s.handleRun,
+	})
+	return checkHost(mux)
+}
+
+// Run serves the web interface on the given address until ctx is done
+func (s *Server) Run(ctx context.Context, address string) error {
+	server := &http.Server{
+		Addr:              address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	err := server.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// checkHost rejects requests using a host name other than localhost. The server has no authentication and relies on
+// only being reachable locally; without this check, a website could use DNS rebinding to point its own host name to
+// 127.0.0.1 and pass the origin check.
+func checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAllowedHost(r.Host) {
+			http.Error(w, fmt.Sprintf("host %q not allowed", r.Host), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAllowedHost returns whether host (with an optional port) is "localhost" or an IP address; IP addresses can't be
+// rebound
+func isAllowedHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	return net.ParseIP(strings.Trim(host, "[]")) != nil
+}
+
+// checkOrigin only allows WebSocket connections from pages served by this server; otherwise any website opened in
+// the browser could run gadgets
+func checkOrigin(config *websocket.Config, req *http.Request) error {
+	origin, err := websocket.Origin(config, req)
+	if err != nil {
+		return err
+	}
+	if origin == nil || origin.Host != req.Host {
+		return fmt.Errorf("origin %q not allowed", originString(origin))
+	}
+	config.Origin = origin
+	return nil
+}
+
+func originString(origin *url.URL) string {
+	if origin == nil {
+		return ""
+	}
+	return origin.String()
+}
+
+func (s *Server) handleCatalog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	catalog, err := s.catalog()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(catalog)
+}
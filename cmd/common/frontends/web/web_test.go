@@ -0,0 +1,229 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+type testEvent struct {
+	Comm  string `column:"comm"`
+	Bytes uint64 `column:"bytes,unit:bytes"`
+}
+
+type testGadget struct{}
+
+func (g *testGadget) Name() string             { return "webtest" }
+func (g *testGadget) Description() string      { return "Gadget used to test the web frontend" }
+func (g *testGadget) Category() string         { return gadgets.CategoryTrace }
+func (g *testGadget) Type() gadgets.GadgetType { return gadgets.TypeTrace }
+func (g *testGadget) EventPrototype() any      { return &testEvent{} }
+func (g *testGadget) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{
+		{
+			Key:          "count",
+			DefaultValue: "2",
+			TypeHint:     params.TypeInt,
+		},
+	}
+}
+
+func (g *testGadget) Parser() parser.Parser {
+	return parser.NewParser[testEvent](columns.MustCreateColumns[testEvent]())
+}
+
+// testRuntime emits as many events as requested by the count param
+type testRuntime struct{}
+
+func (r *testRuntime) Init(*params.Params) error                { return nil }
+func (r *testRuntime) Close() error                             { return nil }
+func (r *testRuntime) GlobalParamDescs() params.ParamDescs      { return nil }
+func (r *testRuntime) ParamDescs() params.ParamDescs            { return nil }
+func (r *testRuntime) SetDefaultValue(params.ValueHint, string) {}
+func (r *testRuntime) GetDefaultValue(params.ValueHint) (string, bool) {
+	return "", false
+}
+
+func (r *testRuntime) GetCatalog() (*runtime.Catalog, error) {
+	return &runtime.Catalog{
+		Gadgets: []*runtime.GadgetInfo{runtime.GadgetInfoFromGadgetDesc(&testGadget{})},
+	}, nil
+}
+
+func (r *testRuntime) RunGadget(gadgetCtx runtime.GadgetContext) (runtime.CombinedGadgetResult, error) {
+	handler := gadgetCtx.Parser().EventHandlerFunc().(func(*testEvent))
+	count := gadgetCtx.GadgetParams().Get("count").AsInt()
+	for i := 0; i < count; i++ {
+		handler(&testEvent{Comm: "cat", Bytes: 2048})
+	}
+	handler(&testEvent{Comm: "filtered", Bytes: 1})
+	return runtime.CombinedGadgetResult{"node1": {Payload: []byte(`{"intervals":[]}`)}}, nil
+}
+
+func init() {
+	gadgetregistry.Register(&testGadget{})
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server := NewServer(&testRuntime{}, nil, nil)
+	t.Cleanup(server.Close)
+
+	ts := httptest.NewServer(server.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestIndex(t *testing.T) {
+	ts := newTestServer(t)
+
+	res, err := http.Get(ts.URL + "/")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, res.Header.Get("Content-Type"), "text/html")
+}
+
+func TestCatalog(t *testing.T) {
+	ts := newTestServer(t)
+
+	res, err := http.Get(ts.URL + "/api/catalog")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	c := &catalog{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(c))
+	require.Len(t, c.Gadgets, 1)
+
+	g := c.Gadgets[0]
+	assert.Equal(t, "webtest", g.Name)
+	assert.Equal(t, string(gadgets.TypeTrace), g.Type)
+	assert.Equal(t, []string{"comm", "bytes"}, g.DefaultColumns)
+	require.Len(t, g.Columns, 2)
+	assert.Equal(t, columns.UnitBytes, g.Columns[1].Unit)
+	require.Len(t, g.Params, 1)
+	assert.Equal(t, "count", g.Params[0].Key)
+	assert.Equal(t, "", g.Params[0].Prefix)
+	assert.Equal(t, "Gadget", g.Params[0].Group)
+}
+
+func TestRejectsForeignHost(t *testing.T) {
+	ts := newTestServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/api/catalog", nil)
+	require.NoError(t, err)
+	req.Host = "rebind.example.com:8080"
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func TestIsAllowedHost(t *testing.T) {
+	for host, allowed := range map[string]bool{
+		"localhost:8080":        true,
+		"127.0.0.1:8080":        true,
+		"[::1]:8080":            true,
+		"10.0.0.1":              true,
+		"example.com:8080":      false,
+		"localhost.example.com": false,
+	} {
+		assert.Equal(t, allowed, isAllowedHost(host), host)
+	}
+}
+
+func dial(t *testing.T, ts *httptest.Server, origin string) (*websocket.Conn, error) {
+	return websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/run", "", origin)
+}
+
+func TestRunRejectsForeignOrigin(t *testing.T) {
+	ts := newTestServer(t)
+
+	_, err := dial(t, ts, "http://example.com")
+	require.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	ts := newTestServer(t)
+
+	conn, err := dial(t, ts, ts.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	err = websocket.JSON.Send(conn, &runRequest{
+		Category: gadgets.CategoryTrace,
+		Name:     "webtest",
+		Params:   map[string]string{"count": "3"},
+		Filters:  []string{"comm:cat"},
+	})
+	require.NoError(t, err)
+
+	var messages []*message
+	for {
+		msg := &message{}
+		require.NoError(t, websocket.JSON.Receive(conn, msg))
+		messages = append(messages, msg)
+		if msg.Type == MessageTypeDone || msg.Type == MessageTypeError {
+			break
+		}
+	}
+
+	require.Len(t, messages, 6)
+	assert.Equal(t, MessageTypeColumns, messages[0].Type)
+	assert.Equal(t, []any{"comm", "bytes"}, messages[0].Data)
+	for _, msg := range messages[1:4] {
+		assert.Equal(t, MessageTypeEvent, msg.Type)
+		// Values of columns with a unit are sent raw
+		assert.Equal(t, []any{"cat", "2048"}, msg.Data)
+	}
+	assert.Equal(t, MessageTypeResult, messages[4].Type)
+	assert.Equal(t, "node1", messages[4].Node)
+	assert.Equal(t, map[string]any{"intervals": []any{}}, messages[4].Data)
+	assert.Equal(t, MessageTypeDone, messages[5].Type)
+}
+
+func TestRunUnknownGadget(t *testing.T) {
+	ts := newTestServer(t)
+
+	conn, err := dial(t, ts, ts.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	require.NoError(t, websocket.JSON.Send(conn, &runRequest{Category: "foo", Name: "bar"}))
+
+	msg := &message{}
+	require.NoError(t, websocket.JSON.Receive(conn, msg))
+	assert.Equal(t, MessageTypeError, msg.Type)
+	assert.Equal(t, "gadget not found: foo/bar", msg.Data)
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends"
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/tui"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+)
+
+// runInteractive shows the output of the gadget in an interactive terminal UI until the user quits or the gadget
+// stops. Periodic gadgets are run once and update the UI every interval; one-shot gadgets are run again after
+// every refresh interval.
+func runInteractive(
+	fe frontends.Frontend,
+	gadgetDesc gadgets.GadgetDesc,
+	gadgetParams *params.Params,
+	parser parser.Parser,
+	outputModeParams string,
+	filters []string,
+	refresh time.Duration,
+	runGadget func(ctx context.Context) error,
+) error {
+	if !tui.CanRun() {
+		return fmt.Errorf("--interactive requires a terminal")
+	}
+	if !gadgetDesc.Type().IsPeriodic() && refresh <= 0 {
+		return fmt.Errorf("--refresh must be greater than 0")
+	}
+
+	if len(filters) > 0 {
+		err := parser.SetFilters(filters)
+		if err != nil {
+			return fmt.Errorf("setting filters: %w", err)
+		}
+	}
+
+	if gadgetDesc.Type().CanSort() {
+		sortBy := gadgetParams.Get(gadgets.ParamSortBy).AsStringSlice()
+		err := parser.SetSorting(sortBy)
+		if err != nil {
+			return fmt.Errorf("setting sort order: %w", err)
+		}
+	}
+
+	unitsOpts, err := unitsOptions()
+	if err != nil {
+		return err
+	}
+
+	view := parser.NewView(unitsOpts...)
+	if err := view.SetShowColumns(showColumns(gadgetParams, parser, outputModeParams)); err != nil {
+		return err
+	}
+	parser.SetEventCallback(view.EventCallback())
+
+	ui := tui.New(gadgetDesc.Name(), view)
+	parser.SetLogCallback(ui.Logf)
+
+	ctx, cancel := context.WithCancel(fe.GetContext())
+	defer cancel()
+
+	gadgetErr := make(chan error, 1)
+	go func() {
+		// Stop the UI as soon as the gadget stops
+		defer cancel()
+
+		if gadgetDesc.Type().IsPeriodic() {
+			gadgetErr <- runGadget(ctx)
+			return
+		}
+
+		ticker := time.NewTicker(refresh)
+		defer ticker.Stop()
+		for {
+			if err := runGadget(ctx); err != nil {
+				gadgetErr <- err
+				return
+			}
+			select {
+			case <-ctx.Done():
+				gadgetErr <- nil
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	uiErr := ui.Run(ctx)
+	cancel()
+
+	if err := <-gadgetErr; err != nil {
+		return fmt.Errorf("running gadget: %w", err)
+	}
+	return uiErr
+}
@@ -0,0 +1,204 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends"
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends/console"
+	cols "github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/recording"
+)
+
+// startRecording creates the file given by filename and wires up the parser to write all events into it. The returned
+// function must be called after the gadget finished to flush and close the file.
+func startRecording(
+	fe frontends.Frontend,
+	filename string,
+	gadgetDesc gadgets.GadgetDesc,
+	gadgetParams *params.Params,
+	runtimeParams *params.Params,
+	operatorsParamsCollection params.Collection,
+	args []string,
+	parser parser.Parser,
+) (func(), error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("creating recording file: %w", err)
+	}
+
+	allParams := make(map[string]string)
+	gadgets.ParamsToMap(allParams, gadgetParams, runtimeParams, operatorsParamsCollection)
+
+	writer, err := recording.NewWriter(f, &recording.Header{
+		GadgetCategory: gadgetDesc.Category(),
+		GadgetName:     gadgetDesc.Name(),
+		GadgetType:     gadgetDesc.Type(),
+		Params:         allParams,
+		Args:           args,
+	})
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	parser.SetRecordCallback(writer.RecordCallback(func(err error) {
+		fe.Logf(logger.WarnLevel, "recording event: %v", err)
+	}))
+
+	return func() {
+		if err := writer.Flush(); err != nil {
+			fe.Logf(logger.WarnLevel, "flushing recording: %v", err)
+		}
+		f.Close()
+	}, nil
+}
+
+// NewReplayCmd returns a command that reads a recording created using --record and outputs it using the same
+// parser, filters, sorting and column formatting as the live gadget would
+func NewReplayCmd(columnFilters []cols.ColumnFilter) *cobra.Command {
+	var outputMode string
+	var filters []string
+	var sortBy []string
+	var speed float64
+
+	cmd := &cobra.Command{
+		Use:          "replay FILE",
+		Short:        "Replay a gadget run that was recorded using --record",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if speed < 0 {
+				return fmt.Errorf("speed must not be negative")
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("opening recording: %w", err)
+			}
+			defer f.Close()
+
+			reader, err := recording.NewReader(f)
+			if err != nil {
+				return err
+			}
+			header := reader.Header()
+
+			gadgetDesc := gadgetregistry.Get(header.GadgetCategory, header.GadgetName)
+			if gadgetDesc == nil {
+				return fmt.Errorf("gadget %s/%s not found", header.GadgetCategory, header.GadgetName)
+			}
+
+			parser := gadgetDesc.Parser()
+
+			gadgetParams := gadgetDesc.ParamDescs().ToParams()
+			gadgetParams.Add(*gadgets.GadgetParams(gadgetDesc, parser).ToParams()...)
+			err = gadgetParams.CopyFromMap(header.Params, "")
+			if err != nil {
+				return fmt.Errorf("setting gadget parameters: %w", err)
+			}
+
+			if c, ok := gadgetDesc.(gadgets.GadgetDescCustomParser); ok {
+				parser, err = c.CustomParser(gadgetParams, header.Args)
+				if err != nil {
+					return fmt.Errorf("calling custom parser: %w", err)
+				}
+			}
+			if parser == nil {
+				return fmt.Errorf("gadget %s/%s does not support replaying", header.GadgetCategory, header.GadgetName)
+			}
+			if columnFilters != nil {
+				parser.SetColumnFilters(columnFilters...)
+			}
+
+			if cmd.Flags().Changed("sort") {
+				if !gadgetDesc.Type().CanSort() {
+					return fmt.Errorf("gadget %s/%s does not support sorting", header.GadgetCategory, header.GadgetName)
+				}
+				if err := gadgetParams.Set(gadgets.ParamSortBy, strings.Join(sortBy, ",")); err != nil {
+					return err
+				}
+			}
+
+			if outputMode == "" {
+				outputMode = OutputModeColumns
+			}
+			outputModeInfo := strings.SplitN(outputMode, "=", 2)
+			outputModeName := outputModeInfo[0]
+			outputModeParams := ""
+			if len(outputModeInfo) > 1 {
+				outputModeParams = outputModeInfo[1]
+			}
+
+			fe := console.NewFrontend()
+			defer fe.Close()
+
+			err = setupParserOutput(fe, gadgetDesc, gadgetParams, parser, outputModeName, outputModeParams, filters)
+			if err != nil {
+				return err
+			}
+
+			// Combine results the same way the runtimes do it
+			switch gadgetDesc.Type() {
+			case gadgets.TypeOneShot:
+				parser.EnableCombiner()
+				defer parser.Flush()
+			case gadgets.TypeTraceIntervals:
+				if speed > 0 {
+					interval := time.Duration(gadgetParams.Get(gadgets.ParamInterval).AsInt()) * time.Second
+					parser.EnableSnapshots(fe.GetContext(), time.Duration(float64(interval)/speed), 2)
+					defer parser.Flush()
+				}
+			}
+
+			return reader.Replay(fe.GetContext(), parser, speed)
+		},
+	}
+
+	cmd.Flags().StringVarP(
+		&outputMode,
+		"output", "o",
+		OutputModeColumns,
+		fmt.Sprintf("Output format (%s). Use '-o columns=col1,col2,col3' to select columns.",
+			strings.Join([]string{OutputModeColumns, OutputModeJSON, OutputModeJSONPretty, OutputModeYAML,
+				OutputModeCSV, OutputModeTSV, OutputModeMarkdown}, ", ")),
+	)
+	cmd.Flags().VarP(newFiltersValue(&filters), "filter", "F", filterUsage)
+	cmd.Flags().StringSliceVar(
+		&sortBy,
+		"sort",
+		[]string{},
+		"Sort by columns (overrides the sorting of the recording). Join multiple columns with ','. Prefix a column with '-' to sort in descending order.",
+	)
+	cmd.Flags().Float64Var(
+		&speed,
+		"speed",
+		1,
+		"Replay speed relative to the recording; 0 replays all events without delay",
+	)
+
+	return cmd
+}
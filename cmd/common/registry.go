@@ -16,6 +16,7 @@ package common
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -29,6 +30,8 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends"
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends/console"
 	cols "github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/tablecolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
 	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
@@ -44,8 +47,18 @@ const (
 	OutputModeJSON       = "json"
 	OutputModeJSONPretty = "jsonpretty"
 	OutputModeYAML       = "yaml"
+	OutputModeCSV        = "csv"
+	OutputModeTSV        = "tsv"
+	OutputModeMarkdown   = "markdown"
 )
 
+// tableOutputModes maps the output modes that are handled by tablecolumns to their format
+var tableOutputModes = map[string]tablecolumns.Format{
+	OutputModeCSV:      tablecolumns.FormatCSV,
+	OutputModeTSV:      tablecolumns.FormatTSV,
+	OutputModeMarkdown: tablecolumns.FormatMarkdown,
+}
+
 // AddCommandsFromRegistry adds all gadgets known by the registry as cobra commands as a subcommand to their categories
 func AddCommandsFromRegistry(rootCmd *cobra.Command, runtime runtime.Runtime, columnFilters []cols.ColumnFilter) {
 	runtimeGlobalParams := runtime.GlobalParamDescs().ToParams()
@@ -56,12 +69,20 @@ func AddCommandsFromRegistry(rootCmd *cobra.Command, runtime runtime.Runtime, co
 	// Add global runtime flags
 	addFlags(rootCmd, runtimeGlobalParams, nil, runtime)
 
+	// Add global flags for the formatting of columns
+	addUnitsFlags(rootCmd)
+	addColorFlag(rootCmd)
+
 	// Add operator global flags
 	operatorsGlobalParamsCollection := operators.GlobalParamsCollection()
 	for _, operatorParams := range operatorsGlobalParamsCollection {
 		addFlags(rootCmd, operatorParams, nil, runtime)
 	}
 
+	addCatalogCommand(rootCmd, runtime)
+	addWebCommand(rootCmd, runtime, runtimeGlobalParams, operatorsGlobalParamsCollection, columnFilters)
+	addRunSpecCommand(rootCmd, runtime, runtimeGlobalParams, operatorsGlobalParamsCollection, columnFilters)
+
 	// Add all known gadgets to cobra in their respective categories
 	categories := gadgets.GetCategories()
 	catalog, _ := runtime.GetCatalog()
@@ -148,7 +169,21 @@ func buildColumnsOutputFormat(gadgetParams *params.Params, parser parser.Parser)
 
 	of.Description += out.String()
 
-	return gadgets.OutputFormats{OutputModeColumns: of}
+	return gadgets.OutputFormats{
+		OutputModeColumns: of,
+		OutputModeCSV: {
+			Name:        "CSV",
+			Description: "The output of the gadget is formatted as comma-separated values.\n  Columns are chosen like for the columns output, e.g. '-o csv=col1,col2,col3'",
+		},
+		OutputModeTSV: {
+			Name:        "TSV",
+			Description: "The output of the gadget is formatted as tab-separated values.\n  Columns are chosen like for the columns output, e.g. '-o tsv=col1,col2,col3'",
+		},
+		OutputModeMarkdown: {
+			Name:        "Markdown",
+			Description: "The output of the gadget is formatted as Markdown table.\n  Columns are chosen like for the columns output, e.g. '-o markdown=col1,col2,col3'",
+		},
+	}
 }
 
 func buildOutputFormatsHelp(outputFormats gadgets.OutputFormats) []string {
@@ -182,6 +217,12 @@ func buildCommandFromGadget(
 	var outputMode string
 	var filters []string
 	var timeout int
+	var recordFile string
+	var groupBy []string
+	var aggregations []string
+	var aggregationInterval time.Duration
+	var interactive bool
+	var refresh time.Duration
 
 	var skipParams []params.ValueHint
 	if skipParamsInterface, ok := gadgetDesc.(gadgets.GadgetDescSkipParams); ok {
@@ -317,6 +358,10 @@ func buildCommandFromGadget(
 			}
 
 			if parser == nil {
+				if recordFile != "" {
+					return fmt.Errorf("gadget does not support recording")
+				}
+
 				var transformResult func(any) ([]byte, error)
 
 				switch outputModeName {
@@ -386,127 +431,56 @@ func buildCommandFromGadget(
 				return err
 			}
 
-			// Add filters if requested
-			if len(filters) > 0 {
-				err = parser.SetFilters(filters)
-				if err != nil {
-					return fmt.Errorf("setting filters: %w", err)
-				}
-			}
+			aggregate := cmd.Flags().Changed("group-by") || cmd.Flags().Changed("agg")
 
-			if gadgetDesc.Type().CanSort() {
-				sortBy := gadgetParams.Get(gadgets.ParamSortBy).AsStringSlice()
-				err := parser.SetSorting(sortBy)
-				if err != nil {
-					return fmt.Errorf("setting sort order: %w", err)
+			if interactive {
+				if outputModeName != OutputModeColumns {
+					return fmt.Errorf("--interactive can only be used with the %q output mode", OutputModeColumns)
 				}
-			}
-
-			formatter := parser.GetTextColumnsFormatter()
-
-			requestedStandardColumns := outputModeParams == ""
-			requestedColumns := strings.Split(outputModeParams, ",")
-
-			// If the standard columns are requested, hide columns that would be empty without specific features
-			// (bool params) enabled
-			if requestedStandardColumns {
-				var hiddenTags []string
-				if gadgetParams != nil {
-					for _, param := range *gadgetParams {
-						if param.TypeHint == params.TypeBool {
-							if !param.AsBool() {
-								hiddenTags = append(hiddenTags, "param:"+strings.ToLower(param.Key))
-							}
-						}
-					}
+				if aggregate {
+					return fmt.Errorf("--interactive can't be used when aggregating events")
+				}
+				if recordFile != "" {
+					return fmt.Errorf("--interactive can't be used together with --record")
 				}
-				requestedColumns = parser.GetDefaultColumns(hiddenTags...)
+				return runInteractive(fe, gadgetDesc, gadgetParams, parser, outputModeParams, filters, refresh,
+					func(ctx context.Context) error {
+						gadgetCtx := gadgetcontext.New(
+							ctx,
+							"",
+							runtime,
+							runtimeParams,
+							gadgetDesc,
+							gadgetParams,
+							args,
+							operatorsParamsCollection,
+							parser,
+							logger.DefaultLogger(),
+							timeoutDuration,
+						)
+						defer gadgetCtx.Cancel()
+
+						_, err := runtime.RunGadget(gadgetCtx)
+						return err
+					},
+				)
 			}
 
-			valid, invalid := parser.VerifyColumnNames(requestedColumns)
-
-			for _, c := range invalid {
-				log.Warnf("column %q not found", c)
+			if aggregate {
+				err = setupAggregationOutput(fe, parser, outputModeName, outputModeParams, filters, groupBy, aggregations, aggregationInterval)
+			} else {
+				err = setupParserOutput(fe, gadgetDesc, gadgetParams, parser, outputModeName, outputModeParams, filters)
 			}
-
-			if err := formatter.SetShowColumns(valid); err != nil {
+			if err != nil {
 				return err
 			}
 
-			parser.SetLogCallback(fe.Logf)
-
-			// Wire up callbacks before handing over to runtime depending on the output mode
-			switch outputModeName {
-			default:
-				transformer, ok := gadgetDesc.(gadgets.GadgetOutputFormats)
-				if !ok {
-					return fmt.Errorf("gadget does not provide output formats")
-				}
-				formats, _ := transformer.OutputFormats()
-				if _, ok := formats[outputModeName]; !ok {
-					return fmt.Errorf("invalid output mode %q", outputModeName)
-				}
-
-				format := formats[outputModeName]
-
-				if format.RequiresCombinedResult {
-					parser.EnableCombiner()
-				}
-
-				transformResult := format.Transform
-				parser.SetEventCallback(func(ev any) {
-					transformed, err := transformResult(ev)
-					if err != nil {
-						fe.Logf(logger.WarnLevel, "could not transform event: %v", err)
-						return
-					}
-					fe.Output(string(transformed))
-				})
-			case OutputModeColumns:
-				formatter.SetEventCallback(fe.Output)
-
-				// Enable additional output, if the gadget supports it (e.g. profile/cpu)
-				//  TODO: This can be optimized later on
-				formatter.SetEnableExtraLines(true)
-
-				parser.SetEventCallback(formatter.EventHandlerFunc())
-				if gadgetDesc.Type().IsPeriodic() {
-					// In case of periodic outputting gadgets, this is done as full table output, and we need to
-					// clear the screen for every interval, that's why we add fe.Clear here
-					parser.SetEventCallback(formatter.EventHandlerFuncArray(
-						fe.Clear,
-						func() {
-							fe.Output(formatter.FormatHeader())
-						},
-					))
-
-					// Print first header while we wait for input
-					if fe.IsTerminal() {
-						fe.Clear()
-						fe.Output(formatter.FormatHeader())
-					}
-					break
-				}
-				fe.Output(formatter.FormatHeader())
-				parser.SetEventCallback(formatter.EventHandlerFuncArray())
-			case OutputModeJSON:
-				jsonCallback := printEventAsJSONFn(fe)
-				if cjson, ok := gadgetDesc.(gadgets.GadgetJSONConverter); ok {
-					jsonCallback = cjson.JSONConverter(gadgetParams, fe)
-				}
-				parser.SetEventCallback(jsonCallback)
-			case OutputModeJSONPretty:
-				jsonPrettyCallback := printEventAsJSONFn(fe)
-				if cjson, ok := gadgetDesc.(gadgets.GadgetJSONPrettyConverter); ok {
-					jsonPrettyCallback = cjson.JSONPrettyConverter(gadgetParams, fe)
-				}
-				parser.SetEventCallback(jsonPrettyCallback)
-			case OutputModeYAML:
-				yamlCallback := printEventAsYAMLFn(fe)
-				if cyaml, ok := gadgetDesc.(gadgets.GadgetYAMLConverter); ok {
-					yamlCallback = cyaml.YAMLConverter(gadgetParams, fe)
+			if recordFile != "" {
+				stopRecording, err := startRecording(fe, recordFile, gadgetDesc, gadgetParams, runtimeParams, operatorsParamsCollection, args, parser)
+				if err != nil {
+					return err
 				}
-				parser.SetEventCallback(yamlCallback)
+				defer stopRecording()
 			}
 
 			// Gadgets with parser don't return anything, they provide the
@@ -516,6 +490,10 @@ func buildCommandFromGadget(
 				return fmt.Errorf("running gadget: %w", err)
 			}
 
+			if aggregate {
+				parser.Flush()
+			}
+
 			return nil
 		},
 	}
@@ -553,28 +531,59 @@ func buildCommandFromGadget(
 	// Add parser output flags
 	if parser != nil {
 		outputFormats.Append(buildColumnsOutputFormat(gadgetParams, parser))
+
+		// The interactive UI needs to know the columns of the gadget upfront
+		if gadgetDesc.Type().IsPeriodic() || gadgetDesc.Type() == gadgets.TypeOneShot {
+			cmd.PersistentFlags().BoolVar(
+				&interactive,
+				"interactive",
+				false,
+				"Show the output in an interactive terminal UI that allows to change sorting, filters and columns at runtime",
+			)
+		}
+		if gadgetDesc.Type() == gadgets.TypeOneShot {
+			cmd.PersistentFlags().DurationVar(
+				&refresh,
+				"refresh",
+				2*time.Second,
+				"Interval in which the gadget is run again when using --interactive",
+			)
+		}
 	}
 	_, hasCustomParser := gadgetDesc.(gadgets.GadgetDescCustomParser)
 
 	if parser != nil || hasCustomParser {
 		defaultOutputFormat = "columns"
 
-		cmd.PersistentFlags().StringSliceVarP(
-			&filters,
-			"filter", "F",
-			[]string{},
-			`Filter rules
-  A filter can match any column using the following syntax
-    columnName:value       - matches, if the content of columnName equals exactly value
-    columnName:!value      - matches, if the content of columnName does not equal exactly value
-    columnName:>=value     - matches, if the content of columnName is greater than or equal to the value
-    columnName:>value      - matches, if the content of columnName is greater than the value
-    columnName:<=value     - matches, if the content of columnName is less than or equal to the value
-    columnName:<value      - matches, if the content of columnName is less than the value
-    columnName:~value      - matches, if the content of columnName matches the regular expression 'value'
-                             see [https://github.com/google/re2/wiki/Syntax] for more information on the syntax
-`,
+		cmd.PersistentFlags().VarP(newFiltersValue(&filters), "filter", "F", filterUsage)
+
+		cmd.PersistentFlags().StringVar(
+			&recordFile,
+			"record",
+			"",
+			"Record all events (before filtering) to the given file; use 'replay' to replay it later on",
 		)
+
+		if gadgetDesc.Type() == gadgets.TypeTrace {
+			cmd.PersistentFlags().StringSliceVar(
+				&groupBy,
+				"group-by",
+				[]string{},
+				"Aggregate events by the given columns instead of printing them. Join multiple columns with ','",
+			)
+			cmd.PersistentFlags().StringSliceVar(
+				&aggregations,
+				"agg",
+				[]string{"count"},
+				"Aggregations to calculate for each group when aggregating events: count, sum(column), min(column), max(column), avg(column). Join multiple aggregations with ','",
+			)
+			cmd.PersistentFlags().DurationVar(
+				&aggregationInterval,
+				"agg-interval",
+				time.Second,
+				"Interval in which aggregated results are printed",
+			)
+		}
 	}
 
 	// Add alternative output formats available in the gadgets
@@ -610,6 +619,185 @@ func buildCommandFromGadget(
 	return cmd
 }
 
+// setupParserOutput applies filters, sorting and column selection to the parser and wires up its event callbacks
+// according to the requested output mode
+func setupParserOutput(
+	fe frontends.Frontend,
+	gadgetDesc gadgets.GadgetDesc,
+	gadgetParams *params.Params,
+	parser parser.Parser,
+	outputModeName string,
+	outputModeParams string,
+	filters []string,
+) error {
+	// Add filters if requested
+	if len(filters) > 0 {
+		err := parser.SetFilters(filters)
+		if err != nil {
+			return fmt.Errorf("setting filters: %w", err)
+		}
+	}
+
+	if gadgetDesc.Type().CanSort() {
+		sortBy := gadgetParams.Get(gadgets.ParamSortBy).AsStringSlice()
+		err := parser.SetSorting(sortBy)
+		if err != nil {
+			return fmt.Errorf("setting sort order: %w", err)
+		}
+	}
+
+	unitsOpts, err := unitsOptions()
+	if err != nil {
+		return err
+	}
+
+	tableFormat, isTableOutput := tableOutputModes[outputModeName]
+
+	// Colors are only used for the columns output
+	var colorOpts []textcolumns.Option
+	if !isTableOutput {
+		colorOpts, err = colorOptions(fe, gadgetDesc)
+		if err != nil {
+			return err
+		}
+	}
+
+	formatter := parser.GetTextColumnsFormatter(append(unitsOpts, colorOpts...)...)
+	if isTableOutput {
+		formatter = parser.GetTableColumnsFormatter(tablecolumns.WithFormat(tableFormat))
+	}
+
+	valid := showColumns(gadgetParams, parser, outputModeParams)
+	if err := formatter.SetShowColumns(valid); err != nil {
+		return err
+	}
+
+	parser.SetLogCallback(fe.Logf)
+
+	// Wire up callbacks before handing over to runtime depending on the output mode
+	switch outputModeName {
+	default:
+		transformer, ok := gadgetDesc.(gadgets.GadgetOutputFormats)
+		if !ok {
+			return fmt.Errorf("gadget does not provide output formats")
+		}
+		formats, _ := transformer.OutputFormats()
+		if _, ok := formats[outputModeName]; !ok {
+			return fmt.Errorf("invalid output mode %q", outputModeName)
+		}
+
+		format := formats[outputModeName]
+
+		if format.RequiresCombinedResult {
+			parser.EnableCombiner()
+		}
+
+		transformResult := format.Transform
+		parser.SetEventCallback(func(ev any) {
+			transformed, err := transformResult(ev)
+			if err != nil {
+				fe.Logf(logger.WarnLevel, "could not transform event: %v", err)
+				return
+			}
+			fe.Output(string(transformed))
+		})
+	case OutputModeColumns:
+		formatter.SetEventCallback(fe.Output)
+
+		// Enable additional output, if the gadget supports it (e.g. profile/cpu)
+		//  TODO: This can be optimized later on
+		formatter.SetEnableExtraLines(true)
+
+		parser.SetEventCallback(formatter.EventHandlerFunc())
+		if gadgetDesc.Type().IsPeriodic() {
+			// In case of periodic outputting gadgets, this is done as full table output, and we need to
+			// clear the screen for every interval, that's why we add fe.Clear here
+			parser.SetEventCallback(formatter.EventHandlerFuncArray(
+				fe.Clear,
+				func() {
+					fe.Output(formatter.FormatHeader())
+				},
+			))
+
+			// Print first header while we wait for input
+			if fe.IsTerminal() {
+				fe.Clear()
+				fe.Output(formatter.FormatHeader())
+			}
+			break
+		}
+		fe.Output(formatter.FormatHeader())
+		parser.SetEventCallback(formatter.EventHandlerFuncArray())
+	case OutputModeCSV, OutputModeTSV, OutputModeMarkdown:
+		formatter.SetEventCallback(fe.Output)
+
+		fe.Output(formatter.FormatHeader())
+		parser.SetEventCallback(formatter.EventHandlerFunc())
+		if gadgetDesc.Type().IsPeriodic() && tableFormat == tablecolumns.FormatMarkdown {
+			// Start a new table for every interval; CSV and TSV rows are just appended
+			first := true
+			parser.SetEventCallback(formatter.EventHandlerFuncArray(func() {
+				if !first {
+					fe.Output("")
+					fe.Output(formatter.FormatHeader())
+				}
+				first = false
+			}))
+			break
+		}
+		parser.SetEventCallback(formatter.EventHandlerFuncArray())
+	case OutputModeJSON:
+		jsonCallback := printEventAsJSONFn(fe)
+		if cjson, ok := gadgetDesc.(gadgets.GadgetJSONConverter); ok {
+			jsonCallback = cjson.JSONConverter(gadgetParams, fe)
+		}
+		parser.SetEventCallback(jsonCallback)
+	case OutputModeJSONPretty:
+		jsonPrettyCallback := printEventAsJSONFn(fe)
+		if cjson, ok := gadgetDesc.(gadgets.GadgetJSONPrettyConverter); ok {
+			jsonPrettyCallback = cjson.JSONPrettyConverter(gadgetParams, fe)
+		}
+		parser.SetEventCallback(jsonPrettyCallback)
+	case OutputModeYAML:
+		yamlCallback := printEventAsYAMLFn(fe)
+		if cyaml, ok := gadgetDesc.(gadgets.GadgetYAMLConverter); ok {
+			yamlCallback = cyaml.YAMLConverter(gadgetParams, fe)
+		}
+		parser.SetEventCallback(yamlCallback)
+	}
+	return nil
+}
+
+// showColumns returns the columns requested using "-o columns=..." or the default columns of the parser
+func showColumns(gadgetParams *params.Params, parser parser.Parser, outputModeParams string) []string {
+	requestedStandardColumns := outputModeParams == ""
+	requestedColumns := strings.Split(outputModeParams, ",")
+
+	// If the standard columns are requested, hide columns that would be empty without specific features
+	// (bool params) enabled
+	if requestedStandardColumns {
+		var hiddenTags []string
+		if gadgetParams != nil {
+			for _, param := range *gadgetParams {
+				if param.TypeHint == params.TypeBool {
+					if !param.AsBool() {
+						hiddenTags = append(hiddenTags, "param:"+strings.ToLower(param.Key))
+					}
+				}
+			}
+		}
+		requestedColumns = parser.GetDefaultColumns(hiddenTags...)
+	}
+
+	valid, invalid := parser.VerifyColumnNames(requestedColumns)
+
+	for _, c := range invalid {
+		log.Warnf("column %q not found", c)
+	}
+
+	return valid
+}
+
 func mustSkip(skipParams []params.ValueHint, valueHint params.ValueHint) bool {
 	for _, param := range skipParams {
 		if param == valueHint {
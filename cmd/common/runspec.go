@@ -0,0 +1,597 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	k8syaml "sigs.k8s.io/yaml"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends"
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends/console"
+	cols "github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+// runSpec describes several gadgets that are started and stopped together, see docs/gadgets/run-spec.md
+type runSpec struct {
+	// Timeout stops all gadgets after the given duration, e.g. "30s"
+	Timeout string `json:"timeout,omitempty"`
+
+	// Selector holds the container selection shared by all gadgets. Keys are the ones of the operator params, e.g.
+	// containername or namespace, and are set in all operators having them.
+	Selector map[string]string `json:"selector,omitempty"`
+
+	// RuntimeParams and OperatorParams are applied to all gadgets, before the ones of each gadget
+	RuntimeParams  map[string]string            `json:"runtimeParams,omitempty"`
+	OperatorParams map[string]map[string]string `json:"operatorParams,omitempty"`
+
+	Gadgets []*gadgetSpec `json:"gadgets"`
+}
+
+type gadgetSpec struct {
+	// ID identifies the gadget in the output; it defaults to its name and needs to be set when running the same
+	// gadget more than once
+	ID       string `json:"id,omitempty"`
+	Category string `json:"category"`
+	Name     string `json:"name"`
+
+	Params         map[string]string            `json:"params,omitempty"`
+	RuntimeParams  map[string]string            `json:"runtimeParams,omitempty"`
+	OperatorParams map[string]map[string]string `json:"operatorParams,omitempty"`
+	Args           []string                     `json:"args,omitempty"`
+	Filters        []string                     `json:"filters,omitempty"`
+
+	// Output is the output mode, like with -o; it defaults to columns
+	Output string `json:"output,omitempty"`
+	// OutputFile is the file the output is written to instead of stdout. Gadgets can share the same file.
+	OutputFile string `json:"outputFile,omitempty"`
+}
+
+// runSpecOutputModes are the output modes that can be multiplexed with the output of other gadgets
+var runSpecOutputModes = []string{OutputModeColumns, OutputModeJSON, OutputModeJSONPretty, OutputModeYAML}
+
+// specGadget is a gadget of a run spec ready to be run
+type specGadget struct {
+	id             string
+	desc           gadgets.GadgetDesc
+	parser         parser.Parser
+	gadgetParams   *params.Params
+	runtimeParams  *params.Params
+	operatorParams params.Collection
+	args           []string
+	filters        []string
+
+	outputMode       string
+	outputModeParams string
+	outputFile       string
+}
+
+// addRunSpecCommand adds a command that runs all gadgets described in a spec file together
+func addRunSpecCommand(
+	rootCmd *cobra.Command,
+	runtime runtime.Runtime,
+	runtimeGlobalParams *params.Params,
+	operatorsGlobalParamsCollection params.Collection,
+	columnFilters []cols.ColumnFilter,
+) {
+	cmd := &cobra.Command{
+		Use:          "run-spec FILE",
+		Short:        "Run several gadgets described in a YAML file together; use '-' to read it from stdin",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec, err := loadRunSpec(args[0])
+			if err != nil {
+				return err
+			}
+
+			var timeout time.Duration
+			if spec.Timeout != "" {
+				timeout, err = time.ParseDuration(spec.Timeout)
+				if err != nil {
+					return fmt.Errorf("parsing timeout: %w", err)
+				}
+			}
+
+			err = runtime.Init(runtimeGlobalParams)
+			if err != nil {
+				return fmt.Errorf("initializing runtime: %w", err)
+			}
+			defer runtime.Close()
+
+			specGadgets, err := prepareRunSpec(spec, runtime, columnFilters)
+			if err != nil {
+				return err
+			}
+
+			// Operators are shared by all gadgets, so they're only initialized once
+			var ops operators.Operators
+			seen := make(map[string]struct{})
+			for _, sg := range specGadgets {
+				for _, op := range operators.GetOperatorsForGadget(sg.desc) {
+					if _, ok := seen[op.Name()]; !ok {
+						seen[op.Name()] = struct{}{}
+						ops = append(ops, op)
+					}
+				}
+			}
+			err = ops.Init(operatorsGlobalParamsCollection)
+			if err != nil {
+				return fmt.Errorf("initializing operators: %w", err)
+			}
+			defer ops.Close()
+
+			fe := console.NewFrontend()
+			defer fe.Close()
+
+			outputs, err := openSpecOutputs(specGadgets)
+			if err != nil {
+				return err
+			}
+			defer outputs.close()
+
+			for _, sg := range specGadgets {
+				out := outputs[sg.outputFile]
+				tag, newTable := newOutputTagger(sg.outputMode, sg.id, out.idWidth, out.shared())
+				sfe := &specFrontend{
+					Frontend: fe,
+					out:      out,
+					id:       sg.id,
+					tag:      tag,
+					newTable: newTable,
+				}
+				err := setupParserOutput(sfe, sg.desc, sg.gadgetParams, sg.parser, sg.outputMode, sg.outputModeParams, sg.filters)
+				if err != nil {
+					return fmt.Errorf("setting up output of gadget %q: %w", sg.id, err)
+				}
+			}
+
+			// The first gadget failing stops the other ones
+			g, ctx := errgroup.WithContext(fe.GetContext())
+			for _, sg := range specGadgets {
+				gadgetCtx := gadgetcontext.New(
+					ctx,
+					"",
+					runtime,
+					sg.runtimeParams,
+					sg.desc,
+					sg.gadgetParams,
+					sg.args,
+					sg.operatorParams,
+					sg.parser,
+					logger.DefaultLogger(),
+					timeout,
+				)
+				id := sg.id
+				g.Go(func() error {
+					defer gadgetCtx.Cancel()
+
+					// Gadgets with parser don't return anything, they provide the output via the parser
+					_, err := runtime.RunGadget(gadgetCtx)
+					if err != nil {
+						return fmt.Errorf("running gadget %q: %w", id, err)
+					}
+					return nil
+				})
+			}
+			return g.Wait()
+		},
+	}
+
+	rootCmd.AddCommand(cmd)
+}
+
+// loadRunSpec reads a run spec from the given file or from stdin if file is "-"
+func loadRunSpec(file string) (*runSpec, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading run spec: %w", err)
+	}
+
+	spec := &runSpec{}
+	if err := k8syaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, fmt.Errorf("parsing run spec: %w", err)
+	}
+	if len(spec.Gadgets) == 0 {
+		return nil, errors.New("run spec has no gadgets")
+	}
+	return spec, nil
+}
+
+// prepareRunSpec looks up the gadgets of the spec and fills their params
+func prepareRunSpec(spec *runSpec, runtime runtime.Runtime, columnFilters []cols.ColumnFilter) ([]*specGadget, error) {
+	catalog, err := runtime.GetCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("getting catalog: %w", err)
+	}
+
+	ids := make(map[string]struct{})
+	usedSelectorKeys := make(map[string]struct{})
+	specGadgets := make([]*specGadget, 0, len(spec.Gadgets))
+	for _, gs := range spec.Gadgets {
+		sg, err := prepareSpecGadget(spec, gs, runtime, catalog, columnFilters, usedSelectorKeys)
+		if err != nil {
+			return nil, fmt.Errorf("gadget %s/%s: %w", gs.Category, gs.Name, err)
+		}
+		if _, ok := ids[sg.id]; ok {
+			return nil, fmt.Errorf("gadget id %q is used more than once; set a unique id for each gadget", sg.id)
+		}
+		ids[sg.id] = struct{}{}
+		specGadgets = append(specGadgets, sg)
+	}
+
+	for key := range spec.Selector {
+		if _, ok := usedSelectorKeys[key]; !ok {
+			return nil, fmt.Errorf("selector key %q is not supported by any gadget", key)
+		}
+	}
+
+	return specGadgets, nil
+}
+
+func prepareSpecGadget(
+	spec *runSpec,
+	gs *gadgetSpec,
+	runtime runtime.Runtime,
+	catalog *runtime.Catalog,
+	columnFilters []cols.ColumnFilter,
+	usedSelectorKeys map[string]struct{},
+) (*specGadget, error) {
+	gadgetDesc := gadgetregistry.Get(gs.Category, gs.Name)
+	if gadgetDesc == nil {
+		return nil, errors.New("gadget not found")
+	}
+
+	// Operators params are the ones known to the runtime, like when adding the commands of the gadgets
+	var operatorParamDescs params.DescCollection
+	found := false
+	for _, info := range catalog.Gadgets {
+		if info.Category == gs.Category && info.Name == gs.Name {
+			operatorParamDescs = info.OperatorParamsCollection
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, errors.New("gadget not available in the runtime")
+	}
+
+	var skipParams []params.ValueHint
+	if skipParamsInterface, ok := gadgetDesc.(gadgets.GadgetDescSkipParams); ok {
+		skipParams = skipParamsInterface.SkipParams()
+	}
+
+	sg := &specGadget{
+		id:             gs.ID,
+		desc:           gadgetDesc,
+		parser:         gadgetDesc.Parser(),
+		runtimeParams:  runtime.ParamDescs().ToParams(),
+		operatorParams: operatorParamDescs.ToParams(),
+		args:           gs.Args,
+		filters:        gs.Filters,
+		outputMode:     OutputModeColumns,
+		outputFile:     gs.OutputFile,
+	}
+	if sg.id == "" {
+		sg.id = gadgetDesc.Name()
+	}
+
+	gadgetParamDescs := gadgetDesc.ParamDescs()
+	gadgetParamDescs.Add(gadgets.GadgetParams(gadgetDesc, sg.parser)...)
+	sg.gadgetParams = gadgetParamDescs.ToParams()
+
+	applyDefaultValues(runtime, sg.gadgetParams)
+	applyDefaultValues(runtime, sg.runtimeParams)
+	for _, operatorParams := range sg.operatorParams {
+		applyDefaultValues(runtime, operatorParams)
+	}
+
+	if err := setSpecParams(sg.gadgetParams, gs.Params); err != nil {
+		return nil, fmt.Errorf("setting gadget params: %w", err)
+	}
+	for _, runtimeParams := range []map[string]string{spec.RuntimeParams, gs.RuntimeParams} {
+		if err := setSpecParams(sg.runtimeParams, runtimeParams); err != nil {
+			return nil, fmt.Errorf("setting runtime params: %w", err)
+		}
+	}
+
+	// The selector is applied before the operator params, so that they can override it
+	for key, value := range spec.Selector {
+		for _, operatorParams := range sg.operatorParams {
+			p := operatorParams.Get(key)
+			if p == nil || (p.ValueHint != "" && mustSkip(skipParams, p.ValueHint)) {
+				continue
+			}
+			if err := p.Set(value); err != nil {
+				return nil, fmt.Errorf("setting selector %q: %w", key, err)
+			}
+			usedSelectorKeys[key] = struct{}{}
+		}
+	}
+	// Shared operator params only apply to the gadgets using that operator
+	for operatorName, values := range spec.OperatorParams {
+		if p, ok := sg.operatorParams[operatorName]; ok {
+			if err := setSpecParams(p, values); err != nil {
+				return nil, fmt.Errorf("setting params of operator %q: %w", operatorName, err)
+			}
+		}
+	}
+	for operatorName, values := range gs.OperatorParams {
+		p, ok := sg.operatorParams[operatorName]
+		if !ok {
+			return nil, fmt.Errorf("operator %q is not used by this gadget", operatorName)
+		}
+		if err := setSpecParams(p, values); err != nil {
+			return nil, fmt.Errorf("setting params of operator %q: %w", operatorName, err)
+		}
+	}
+
+	for _, p := range *sg.gadgetParams {
+		if p.IsMandatory && p.AsString() == "" {
+			return nil, fmt.Errorf("param %q is mandatory", p.Key)
+		}
+	}
+
+	if c, ok := gadgetDesc.(gadgets.GadgetDescCustomParser); ok {
+		var err error
+		sg.parser, err = c.CustomParser(sg.gadgetParams, sg.args)
+		if err != nil {
+			return nil, fmt.Errorf("calling custom parser: %w", err)
+		}
+	}
+	if sg.parser == nil {
+		return nil, errors.New("gadgets without parser are not supported in run specs")
+	}
+	if columnFilters != nil {
+		sg.parser.SetColumnFilters(columnFilters...)
+	}
+
+	if gs.Output != "" {
+		outputModeInfo := strings.SplitN(gs.Output, "=", 2)
+		sg.outputMode = outputModeInfo[0]
+		if len(outputModeInfo) > 1 {
+			sg.outputModeParams = outputModeInfo[1]
+		}
+	}
+	valid := false
+	for _, mode := range runSpecOutputModes {
+		if sg.outputMode == mode {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("output mode %q is not supported in run specs; use one of %s",
+			sg.outputMode, strings.Join(runSpecOutputModes, ", "))
+	}
+
+	return sg, nil
+}
+
+// applyDefaultValues sets the values the runtime has for params with value hints, like the default namespace
+func applyDefaultValues(runtime runtime.Runtime, ps *params.Params) {
+	for _, p := range *ps {
+		if p.ValueHint == "" {
+			continue
+		}
+		if value, hasValue := runtime.GetDefaultValue(p.ValueHint); hasValue {
+			p.Set(value)
+		}
+	}
+}
+
+// setSpecParams sets the given values; unlike gadgets.ParamsFromMap, unknown keys are an error to catch typos in
+// the spec
+func setSpecParams(ps *params.Params, values map[string]string) error {
+	// Sort keys to get stable errors
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		p := ps.Get(key)
+		if p == nil {
+			return fmt.Errorf("unknown param %q", key)
+		}
+		if err := p.Set(values[key]); err != nil {
+			return fmt.Errorf("param %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// specOutput is a destination shared by one or more gadgets
+type specOutput struct {
+	mu   sync.Mutex
+	w    io.Writer
+	file *os.File
+
+	users   int
+	idWidth int
+}
+
+func (o *specOutput) shared() bool {
+	return o.users > 1
+}
+
+// write writes the payload after tagging it; tag is called with the lock held, as taggers aren't safe for
+// concurrent use
+func (o *specOutput) write(tag func(string) string, payload string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	fmt.Fprintln(o.w, tag(payload))
+}
+
+// specOutputs maps file names to outputs; stdout uses the empty name
+type specOutputs map[string]*specOutput
+
+func openSpecOutputs(specGadgets []*specGadget) (specOutputs, error) {
+	outputs := make(specOutputs)
+	for _, sg := range specGadgets {
+		out, ok := outputs[sg.outputFile]
+		if !ok {
+			out = &specOutput{w: os.Stdout, idWidth: len("GADGET")}
+			if sg.outputFile != "" {
+				file, err := os.Create(sg.outputFile)
+				if err != nil {
+					outputs.close()
+					return nil, fmt.Errorf("creating output file: %w", err)
+				}
+				out.w = file
+				out.file = file
+			}
+			outputs[sg.outputFile] = out
+		}
+		out.users++
+		if len(sg.id) > out.idWidth {
+			out.idWidth = len(sg.id)
+		}
+	}
+	return outputs, nil
+}
+
+func (o specOutputs) close() {
+	for _, out := range o {
+		if out.file != nil {
+			out.file.Close()
+		}
+	}
+}
+
+// specFrontend is the frontend of a single gadget of a run spec. It tags the output of the gadget with its id if
+// other gadgets write to the same destination.
+type specFrontend struct {
+	frontends.Frontend
+	out *specOutput
+	id  string
+	tag func(payload string) string
+	// newTable is called when a periodic gadget starts a new table
+	newTable func()
+}
+
+func (f *specFrontend) Output(payload string) {
+	f.out.write(f.tag, payload)
+}
+
+func (f *specFrontend) Logf(severity logger.Level, format string, params ...any) {
+	f.Frontend.Logf(severity, "%s: "+format, append([]any{f.id}, params...)...)
+}
+
+func (f *specFrontend) IsTerminal() bool {
+	return f.out.file == nil && f.Frontend.IsTerminal()
+}
+
+// Clear doesn't clear the output, as other gadgets might share it. Periodic gadgets clear it before printing a new
+// table, so the next line is a header again.
+func (f *specFrontend) Clear() {
+	f.newTable()
+}
+
+// Close does nothing, the frontend and outputs are closed by the command
+func (f *specFrontend) Close() {}
+
+// newOutputTagger returns a function adding the gadget id to the output of the given mode. With the columns output,
+// it's a first column named GADGET; with the other output modes, it's a "gadget" field. Events that aren't objects
+// (e.g. the lists of periodic gadgets) are put into an "events" field. The second function returned must be called
+// when a new table starts, so its header is tagged as such.
+func newOutputTagger(outputMode string, id string, width int, shared bool) (func(string) string, func()) {
+	noNewTable := func() {}
+
+	if !shared {
+		return func(payload string) string {
+			return payload
+		}, noNewTable
+	}
+
+	quotedID, _ := json.Marshal(id)
+
+	switch outputMode {
+	case OutputModeJSON:
+		return func(payload string) string {
+			switch {
+			case payload == "{}":
+				return fmt.Sprintf(`{"gadget":%s}`, quotedID)
+			case strings.HasPrefix(payload, "{"):
+				return fmt.Sprintf(`{"gadget":%s,%s`, quotedID, payload[1:])
+			default:
+				return fmt.Sprintf(`{"gadget":%s,"events":%s}`, quotedID, payload)
+			}
+		}, noNewTable
+	case OutputModeJSONPretty:
+		return func(payload string) string {
+			switch {
+			case strings.HasPrefix(payload, "{\n"):
+				return fmt.Sprintf("{\n  \"gadget\": %s,\n%s", quotedID, payload[2:])
+			default:
+				return fmt.Sprintf("{\n  \"gadget\": %s,\n  \"events\": %s\n}", quotedID,
+					strings.ReplaceAll(payload, "\n", "\n  "))
+			}
+		}, noNewTable
+	case OutputModeYAML:
+		return func(payload string) string {
+			body := strings.TrimPrefix(payload, "---\n")
+			if strings.HasPrefix(body, "- ") {
+				return fmt.Sprintf("---\ngadget: %s\nevents:\n%s", quotedID, body)
+			}
+			return fmt.Sprintf("---\ngadget: %s\n%s", quotedID, body)
+		}, noNewTable
+	default:
+		// The first line written is the header, as well as the first line of each new table. Payloads can hold
+		// several lines, so the header is tracked per line.
+		header := true
+		tag := func(payload string) string {
+			lines := strings.Split(payload, "\n")
+			for i, line := range lines {
+				prefix := id
+				if header {
+					prefix = "GADGET"
+					header = false
+				}
+				lines[i] = fmt.Sprintf("%-*s ", width, prefix) + line
+			}
+			return strings.Join(lines, "\n")
+		}
+		newTable := func() {
+			header = true
+		}
+		return tag, newTable
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8syaml "sigs.k8s.io/yaml"
+)
+
+func TestLoadRunSpec(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		file := filepath.Join(dir, "spec.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+		return file
+	}
+
+	spec, err := loadRunSpec(write(`
+timeout: 10s
+selector:
+  containername: test
+gadgets:
+- category: top
+  name: catalogtest
+  params:
+    mode: b
+  output: json
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "10s", spec.Timeout)
+	assert.Equal(t, map[string]string{"containername": "test"}, spec.Selector)
+	require.Len(t, spec.Gadgets, 1)
+	assert.Equal(t, "catalogtest", spec.Gadgets[0].Name)
+	assert.Equal(t, "b", spec.Gadgets[0].Params["mode"])
+
+	_, err = loadRunSpec(write("gadgets:\n- name: catalogtest\n  foo: bar\n"))
+	assert.Error(t, err, "unknown fields")
+
+	_, err = loadRunSpec(write("timeout: 10s\n"))
+	assert.Error(t, err, "no gadgets")
+
+	_, err = loadRunSpec(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestPrepareRunSpec(t *testing.T) {
+	newSpec := func() *runSpec {
+		return &runSpec{
+			RuntimeParams: map[string]string{"node": "node1"},
+			Gadgets: []*gadgetSpec{
+				{Category: "top", Name: "catalogtest", Params: map[string]string{"mode": "b"}},
+				{ID: "other", Category: "top", Name: "catalogtest", Output: "json", OutputFile: "out.json"},
+			},
+		}
+	}
+
+	specGadgets, err := prepareRunSpec(newSpec(), &catalogTestRuntime{}, nil)
+	require.NoError(t, err)
+	require.Len(t, specGadgets, 2)
+
+	assert.Equal(t, "catalogtest", specGadgets[0].id)
+	assert.Equal(t, "b", specGadgets[0].gadgetParams.Get("mode").AsString())
+	assert.Equal(t, "node1", specGadgets[0].runtimeParams.Get("node").AsString())
+	assert.Equal(t, OutputModeColumns, specGadgets[0].outputMode)
+
+	assert.Equal(t, "other", specGadgets[1].id)
+	assert.Equal(t, "a", specGadgets[1].gadgetParams.Get("mode").AsString())
+	assert.Equal(t, OutputModeJSON, specGadgets[1].outputMode)
+	assert.Equal(t, "out.json", specGadgets[1].outputFile)
+
+	tests := map[string]func(spec *runSpec){
+		"unknown gadget": func(spec *runSpec) {
+			spec.Gadgets[0].Name = "foo"
+		},
+		"unknown param": func(spec *runSpec) {
+			spec.Gadgets[0].Params["foo"] = "bar"
+		},
+		"invalid param value": func(spec *runSpec) {
+			spec.Gadgets[0].Params["mode"] = "c"
+		},
+		"unknown runtime param": func(spec *runSpec) {
+			spec.Gadgets[1].RuntimeParams = map[string]string{"foo": "bar"}
+		},
+		"unknown operator": func(spec *runSpec) {
+			spec.Gadgets[1].OperatorParams = map[string]map[string]string{"foo": {"bar": "baz"}}
+		},
+		"duplicated id": func(spec *runSpec) {
+			spec.Gadgets[1].ID = ""
+		},
+		"unsupported selector": func(spec *runSpec) {
+			spec.Selector = map[string]string{"foo": "bar"}
+		},
+		"unsupported output mode": func(spec *runSpec) {
+			spec.Gadgets[0].Output = OutputModeCSV
+		},
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			spec := newSpec()
+			modify(spec)
+			_, err := prepareRunSpec(spec, &catalogTestRuntime{}, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOutputTagger(t *testing.T) {
+	tag, _ := newOutputTagger(OutputModeJSON, "exec", 6, false)
+	assert.Equal(t, `{"pid":1}`, tag(`{"pid":1}`))
+
+	tag, _ = newOutputTagger(OutputModeJSON, "exec", 6, true)
+	assert.Equal(t, `{"gadget":"exec","pid":1}`, tag(`{"pid":1}`))
+	assert.Equal(t, `{"gadget":"exec"}`, tag(`{}`))
+	assert.Equal(t, `{"gadget":"exec","events":[{"pid":1}]}`, tag(`[{"pid":1}]`))
+	assert.True(t, json.Valid([]byte(tag(`[{"pid":1}]`))))
+
+	tag, _ = newOutputTagger(OutputModeJSONPretty, "exec", 6, true)
+	pretty := tag("{\n  \"pid\": 1\n}")
+	assert.Equal(t, "{\n  \"gadget\": \"exec\",\n  \"pid\": 1\n}", pretty)
+	pretty = tag("[\n  {\n    \"pid\": 1\n  }\n]")
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal([]byte(pretty), &decoded))
+	assert.Equal(t, "exec", decoded["gadget"])
+	assert.Len(t, decoded["events"], 1)
+
+	tag, _ = newOutputTagger(OutputModeYAML, "exec", 6, true)
+	decoded = nil
+	require.NoError(t, k8syaml.Unmarshal([]byte(tag("---\npid: 1\n")), &decoded))
+	assert.Equal(t, map[string]any{"gadget": "exec", "pid": float64(1)}, decoded)
+	decoded = nil
+	require.NoError(t, k8syaml.Unmarshal([]byte(tag("---\n- pid: 1\n")), &decoded))
+	assert.Equal(t, "exec", decoded["gadget"])
+	assert.Len(t, decoded["events"], 1)
+
+	tag, newTable := newOutputTagger(OutputModeColumns, "exec", 6, true)
+	assert.Equal(t, "GADGET PID", tag("PID"))
+	assert.Equal(t, "exec   1", tag("1"))
+	assert.Equal(t, "exec   1\nexec   2", tag("1\n2"))
+
+	// Periodic gadgets print a new header with each table
+	newTable()
+	assert.Equal(t, "GADGET PID", tag("PID"))
+	assert.Equal(t, "exec   3", tag("3"))
+
+	// Only the first line of a table written at once is the header
+	newTable()
+	assert.Equal(t, "GADGET PID\nexec   4\nexec   5", tag("PID\n4\n5"))
+	assert.Equal(t, "exec   6", tag("6"))
+}
@@ -0,0 +1,124 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import "unicode/utf8"
+
+type key int
+
+const (
+	keyRune key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyPageUp
+	keyPageDown
+	keyHome
+	keyEnd
+	keyEnter
+	keyEscape
+	keyBackspace
+	keyCtrlC
+)
+
+type keyEvent struct {
+	key  key
+	rune rune
+}
+
+// parseKeys turns the bytes read from a terminal in raw mode into key events; unknown control and escape sequences
+// are ignored
+func parseKeys(b []byte) []keyEvent {
+	var events []keyEvent
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 || (b[1] != '[' && b[1] != 'O') {
+				events = append(events, keyEvent{key: keyEscape})
+				b = b[1:]
+				continue
+			}
+			ev, n := parseEscapeSequence(b[2:])
+			if ev != nil {
+				events = append(events, *ev)
+			}
+			b = b[2+n:]
+		case c == '\r' || c == '\n':
+			events = append(events, keyEvent{key: keyEnter})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			events = append(events, keyEvent{key: keyBackspace})
+			b = b[1:]
+		case c == 0x03:
+			events = append(events, keyEvent{key: keyCtrlC})
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			r, n := utf8.DecodeRune(b)
+			events = append(events, keyEvent{key: keyRune, rune: r})
+			b = b[n:]
+		}
+	}
+	return events
+}
+
+// parseEscapeSequence parses the remainder of a CSI or SS3 sequence and returns the matching key event (or nil if
+// unknown) together with the number of bytes consumed
+func parseEscapeSequence(b []byte) (*keyEvent, int) {
+	param := 0
+	for i, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			param = param*10 + int(c-'0')
+			continue
+		case c == ';':
+			continue
+		}
+
+		k := key(-1)
+		switch c {
+		case 'A':
+			k = keyUp
+		case 'B':
+			k = keyDown
+		case 'C':
+			k = keyRight
+		case 'D':
+			k = keyLeft
+		case 'H':
+			k = keyHome
+		case 'F':
+			k = keyEnd
+		case '~':
+			switch param {
+			case 1, 7:
+				k = keyHome
+			case 4, 8:
+				k = keyEnd
+			case 5:
+				k = keyPageUp
+			case 6:
+				k = keyPageDown
+			}
+		}
+		if k < 0 {
+			return nil, i + 1
+		}
+		return &keyEvent{key: k}, i + 1
+	}
+	return nil, len(b)
+}
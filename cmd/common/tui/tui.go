@@ -0,0 +1,585 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tui implements an interactive full-screen terminal UI for gadgets emitting arrays of events, like the top
+// gadgets. It allows changing the sort order, filters and shown columns at runtime, pausing the output and drilling
+// down into a single container.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/term"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/filter"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+)
+
+const (
+	escEnterScreen = "\x1b[?1049h\x1b[?25l"
+	escLeaveScreen = "\x1b[?25h\x1b[?1049l"
+	escHome        = "\x1b[H"
+	escClearLine   = "\x1b[K"
+	escClearBelow  = "\x1b[J"
+	escReverse     = "\x1b[7m"
+	escBold        = "\x1b[1m"
+	escReset       = "\x1b[0m"
+
+	// readKeysTimeout is how long to wait for input before checking whether to stop reading keys
+	readKeysTimeout = 100 * time.Millisecond
+
+	helpText = "q quit  ←/→ sort column  r reverse  / filter  c columns  space pause  ↑/↓ select  enter container  esc back"
+)
+
+// containerColumns are the columns used to identify the container of a row when drilling down
+var containerColumns = []string{"node", "namespace", "pod", "container"}
+
+type mode int
+
+const (
+	modeTable mode = iota
+	modeFilter
+	modeColumns
+)
+
+// UI is an interactive terminal UI showing the entries of a parser.View
+type UI struct {
+	title string
+	view  parser.View
+
+	in  *os.File
+	out *os.File
+
+	redraw chan struct{}
+
+	mu     sync.Mutex
+	status string
+
+	// the fields below are only accessed from the loop in Run
+	mode        mode
+	selected    int
+	offset      int
+	rowCount    int
+	sortColumn  string
+	sortDesc    bool
+	paused      bool
+	input       string
+	filters     []string
+	drillDown   []string
+	drillLabel  string
+	columnIndex int
+}
+
+// New returns a UI showing the entries of view; title is shown in the title bar
+func New(title string, view parser.View) *UI {
+	u := &UI{
+		title:  title,
+		view:   view,
+		in:     os.Stdin,
+		out:    os.Stdout,
+		redraw: make(chan struct{}, 1),
+	}
+	if sortBy := view.SortBy(); len(sortBy) > 0 {
+		u.sortColumn = strings.TrimPrefix(sortBy[0], "-")
+		u.sortDesc = strings.HasPrefix(sortBy[0], "-")
+	}
+	view.SetUpdateCallback(u.requestRedraw)
+	return u
+}
+
+// CanRun returns whether stdin and stdout are terminals, which is required to run the UI
+func CanRun() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// Logf shows a log message in the status line; it can be used as log callback of the parser
+func (u *UI) Logf(severity logger.Level, format string, params ...any) {
+	if severity > logger.InfoLevel {
+		return
+	}
+	u.setStatus(fmt.Sprintf(format, params...))
+}
+
+// Write implements io.Writer to show the output of the logger in the status line
+func (u *UI) Write(p []byte) (int, error) {
+	if line := strings.TrimSpace(string(p)); line != "" {
+		u.setStatus(line)
+	}
+	return len(p), nil
+}
+
+func (u *UI) setStatus(status string) {
+	u.mu.Lock()
+	u.status = status
+	u.mu.Unlock()
+	u.requestRedraw()
+}
+
+func (u *UI) getStatus() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.status
+}
+
+func (u *UI) requestRedraw() {
+	select {
+	case u.redraw <- struct{}{}:
+	default:
+	}
+}
+
+// Run takes over the terminal and shows the UI until ctx is done or the user quits
+func (u *UI) Run(ctx context.Context) error {
+	state, err := term.MakeRaw(int(u.in.Fd()))
+	if err != nil {
+		return fmt.Errorf("setting terminal to raw mode: %w", err)
+	}
+	defer term.Restore(int(u.in.Fd()), state)
+
+	fmt.Fprint(u.out, escEnterScreen)
+	defer fmt.Fprint(u.out, escLeaveScreen)
+
+	// Messages of the logger would mess up the screen, so show them in the status line instead
+	prevOut := log.StandardLogger().Out
+	log.SetOutput(u)
+	defer log.SetOutput(prevOut)
+
+	keys := make(chan []keyEvent)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		readKeys(u.in, keys, done)
+	}()
+	// Stop reading keys before restoring the terminal, so no input is taken away from the shell
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	// Redraw regularly to pick up changes of the terminal size
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	u.draw()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case events, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, ev := range events {
+				if quit := u.handleKey(ev); quit {
+					return nil
+				}
+			}
+		case <-u.redraw:
+		case <-ticker.C:
+		}
+		u.draw()
+	}
+}
+
+// readKeys sends the keys read from f to keys until done is closed or reading fails
+func readKeys(f *os.File, keys chan<- []keyEvent, done <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		ready, err := waitForInput(f, readKeysTimeout)
+		if err != nil {
+			return
+		}
+		if !ready {
+			continue
+		}
+
+		n, err := f.Read(buf)
+		if err != nil {
+			return
+		}
+		select {
+		case keys <- parseKeys(buf[:n]):
+		case <-done:
+			return
+		}
+	}
+}
+
+// handleKey updates the state of the UI according to ev and returns true if the user wants to quit
+func (u *UI) handleKey(ev keyEvent) bool {
+	if ev.key == keyCtrlC {
+		return true
+	}
+	switch u.mode {
+	case modeFilter:
+		u.handleFilterKey(ev)
+	case modeColumns:
+		u.handleColumnsKey(ev)
+	default:
+		return u.handleTableKey(ev)
+	}
+	return false
+}
+
+func (u *UI) handleTableKey(ev keyEvent) bool {
+	switch ev.key {
+	case keyUp:
+		u.selected--
+	case keyDown:
+		u.selected++
+	case keyPageUp:
+		u.selected -= u.pageSize()
+	case keyPageDown:
+		u.selected += u.pageSize()
+	case keyHome:
+		u.selected = 0
+	case keyEnd:
+		u.selected = u.rowCount - 1
+	case keyLeft:
+		u.moveSortColumn(-1)
+	case keyRight:
+		u.moveSortColumn(1)
+	case keyEnter:
+		u.enterContainer()
+	case keyEscape, keyBackspace:
+		if u.drillDown != nil {
+			u.drillDown = nil
+			u.drillLabel = ""
+			u.applyFilters(u.filters)
+		}
+		u.setStatus("")
+	case keyRune:
+		switch ev.rune {
+		case 'q':
+			return true
+		case '<':
+			u.moveSortColumn(-1)
+		case '>':
+			u.moveSortColumn(1)
+		case 'r':
+			if u.sortColumn != "" {
+				u.sortDesc = !u.sortDesc
+				u.applySorting()
+			}
+		case '/':
+			u.mode = modeFilter
+			u.input = strings.Join(u.filters, ",")
+		case 'c':
+			u.mode = modeColumns
+			u.columnIndex = 0
+		case ' ', 'p':
+			u.paused = !u.paused
+			u.view.SetPaused(u.paused)
+		}
+	}
+	return false
+}
+
+func (u *UI) handleFilterKey(ev keyEvent) {
+	switch ev.key {
+	case keyEscape:
+		u.mode = modeTable
+	case keyEnter:
+		u.mode = modeTable
+		var filters []string
+		if input := strings.TrimSpace(u.input); input != "" {
+			filters = filter.SplitFilters(input)
+		}
+		u.applyFilters(filters)
+	case keyBackspace:
+		if r := []rune(u.input); len(r) > 0 {
+			u.input = string(r[:len(r)-1])
+		}
+	case keyRune:
+		u.input += string(ev.rune)
+	}
+}
+
+func (u *UI) handleColumnsKey(ev keyEvent) {
+	names := u.view.ColumnNames()
+	switch ev.key {
+	case keyUp:
+		if u.columnIndex > 0 {
+			u.columnIndex--
+		}
+	case keyDown:
+		if u.columnIndex < len(names)-1 {
+			u.columnIndex++
+		}
+	case keyEscape, keyEnter:
+		u.mode = modeTable
+	case keyRune:
+		switch ev.rune {
+		case 'c', 'q':
+			u.mode = modeTable
+		case ' ', 'x':
+			if u.columnIndex < len(names) {
+				u.toggleColumn(names, names[u.columnIndex])
+			}
+		}
+	}
+}
+
+// toggleColumn shows or hides the given column, keeping the default order of the columns
+func (u *UI) toggleColumn(names []string, name string) {
+	shown := make(map[string]bool)
+	for _, c := range u.view.ShownColumns() {
+		shown[strings.ToLower(c)] = true
+	}
+	shown[strings.ToLower(name)] = !shown[strings.ToLower(name)]
+
+	var columns []string
+	for _, c := range names {
+		if shown[strings.ToLower(c)] {
+			columns = append(columns, c)
+		}
+	}
+	if len(columns) == 0 {
+		u.setStatus("at least one column has to be shown")
+		return
+	}
+	if err := u.view.SetShowColumns(columns); err != nil {
+		u.setStatus(err.Error())
+	}
+}
+
+// moveSortColumn sorts by the column next to the current sort column, keeping the sort direction
+func (u *UI) moveSortColumn(delta int) {
+	columns := u.view.ShownColumns()
+	if len(columns) == 0 {
+		return
+	}
+	idx := -1
+	for i, c := range columns {
+		if strings.EqualFold(c, u.sortColumn) {
+			idx = i
+			break
+		}
+	}
+	switch {
+	case idx < 0 && delta < 0:
+		idx = len(columns) - 1
+	case idx < 0:
+		idx = 0
+	default:
+		idx = (idx + delta + len(columns)) % len(columns)
+	}
+	u.sortColumn = columns[idx]
+	u.applySorting()
+}
+
+func (u *UI) applySorting() {
+	sortBy := u.sortColumn
+	if u.sortDesc {
+		sortBy = "-" + sortBy
+	}
+	if err := u.view.SetSortBy([]string{sortBy}); err != nil {
+		u.setStatus(err.Error())
+	}
+}
+
+func (u *UI) applyFilters(filters []string) {
+	if err := u.view.SetFilters(append(append([]string{}, u.drillDown...), filters...)); err != nil {
+		u.setStatus(err.Error())
+		return
+	}
+	u.filters = filters
+	u.selected = 0
+}
+
+// enterContainer limits the view to the container of the selected row
+func (u *UI) enterContainer() {
+	available := make(map[string]bool)
+	for _, c := range u.view.ColumnNames() {
+		available[strings.ToLower(c)] = true
+	}
+
+	var filters, labels []string
+	for _, c := range containerColumns {
+		if !available[c] {
+			continue
+		}
+		value, ok := u.view.Value(u.selected, c)
+		if !ok || value == "" {
+			continue
+		}
+		filters = append(filters, c+":"+value)
+		labels = append(labels, value)
+	}
+	if len(filters) == 0 {
+		u.setStatus("selected row has no container information")
+		return
+	}
+
+	if err := u.view.SetFilters(append(append([]string{}, filters...), u.filters...)); err != nil {
+		u.setStatus(err.Error())
+		return
+	}
+	u.drillDown = filters
+	u.drillLabel = strings.Join(labels, "/")
+	u.selected = 0
+}
+
+func (u *UI) size() (int, int) {
+	width, height, err := term.GetSize(int(u.out.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// pageSize returns the number of rows that fit on the screen below the title bar and header and above the
+// status line
+func (u *UI) pageSize() int {
+	_, height := u.size()
+	if height < 4 {
+		return 1
+	}
+	return height - 3
+}
+
+func (u *UI) titleBar() string {
+	var parts []string
+	parts = append(parts, u.title)
+	if u.sortColumn != "" {
+		order := "asc"
+		if u.sortDesc {
+			order = "desc"
+		}
+		parts = append(parts, fmt.Sprintf("sort: %s (%s)", u.sortColumn, order))
+	}
+	if len(u.filters) > 0 {
+		parts = append(parts, "filter: "+strings.Join(u.filters, ","))
+	}
+	if u.drillLabel != "" {
+		parts = append(parts, "container: "+u.drillLabel)
+	}
+	if u.paused {
+		parts = append(parts, "PAUSED")
+	}
+	parts = append(parts, fmt.Sprintf("%d rows", u.rowCount))
+	return strings.Join(parts, " | ")
+}
+
+func (u *UI) draw() {
+	width, _ := u.size()
+	pageSize := u.pageSize()
+
+	var lines []string
+	lines = append(lines, escReverse+fit(u.titleBar(), width)+escReset)
+
+	switch u.mode {
+	case modeColumns:
+		shown := make(map[string]bool)
+		for _, c := range u.view.ShownColumns() {
+			shown[strings.ToLower(c)] = true
+		}
+		lines = append(lines, escBold+fit("Columns (space to toggle, enter to close)", width)+escReset)
+		names := u.view.ColumnNames()
+		offset := 0
+		if u.columnIndex >= pageSize {
+			offset = u.columnIndex - pageSize + 1
+		}
+		for i := offset; i < len(names) && i < offset+pageSize; i++ {
+			mark := "[ ]"
+			if shown[strings.ToLower(names[i])] {
+				mark = "[x]"
+			}
+			line := fit(mark+" "+names[i], width)
+			if i == u.columnIndex {
+				line = escReverse + line + escReset
+			}
+			lines = append(lines, line)
+		}
+	default:
+		header, rows := u.view.Render()
+		u.rowCount = len(rows)
+		u.selected = clamp(u.selected, 0, len(rows)-1)
+		if u.selected < u.offset {
+			u.offset = u.selected
+		}
+		if u.selected >= u.offset+pageSize {
+			u.offset = u.selected - pageSize + 1
+		}
+		u.offset = clamp(u.offset, 0, len(rows)-1)
+
+		lines = append(lines, escBold+fit(header, width)+escReset)
+		for i := u.offset; i < len(rows) && i < u.offset+pageSize; i++ {
+			line := fit(rows[i], width)
+			if i == u.selected {
+				line = escReverse + line + escReset
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	for len(lines) < pageSize+2 {
+		lines = append(lines, "")
+	}
+
+	bottom := helpText
+	if u.mode == modeFilter {
+		bottom = "filter: " + u.input + "_"
+	} else if status := u.getStatus(); status != "" {
+		bottom = status
+	}
+	lines = append(lines, fit(bottom, width))
+
+	var sb strings.Builder
+	sb.WriteString(escHome)
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString("\r\n")
+		}
+		sb.WriteString(line)
+		sb.WriteString(escClearLine)
+	}
+	sb.WriteString(escClearBelow)
+	fmt.Fprint(u.out, sb.String())
+}
+
+// fit cuts or pads s to exactly width runes
+func fit(s string, width int) string {
+	r := []rune(s)
+	if len(r) > width {
+		return string(r[:width])
+	}
+	return s + strings.Repeat(" ", width-len(r))
+}
+
+func clamp(v, min, max int) int {
+	if v > max {
+		v = max
+	}
+	if v < min {
+		v = min
+	}
+	return v
+}
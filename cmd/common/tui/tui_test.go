@@ -0,0 +1,193 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tui
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeView struct {
+	columns []string
+	shown   []string
+	sortBy  []string
+	filters []string
+	paused  bool
+	values  map[string]string
+}
+
+func (v *fakeView) EventCallback() any                { return nil }
+func (v *fakeView) SetUpdateCallback(func())          {}
+func (v *fakeView) SetPaused(paused bool)             { v.paused = paused }
+func (v *fakeView) ColumnNames() []string             { return v.columns }
+func (v *fakeView) ShownColumns() []string            { return v.shown }
+func (v *fakeView) SetShowColumns(c []string) error   { v.shown = c; return nil }
+func (v *fakeView) SortBy() []string                  { return v.sortBy }
+func (v *fakeView) SetSortBy(sortBy []string) error   { v.sortBy = sortBy; return nil }
+func (v *fakeView) SetFilters(filters []string) error { v.filters = filters; return nil }
+func (v *fakeView) Render() (string, []string)        { return "", nil }
+
+func (v *fakeView) Value(row int, column string) (string, bool) {
+	value, ok := v.values[column]
+	return value, ok
+}
+
+func runes(s string) []keyEvent {
+	var events []keyEvent
+	for _, r := range s {
+		events = append(events, keyEvent{key: keyRune, rune: r})
+	}
+	return events
+}
+
+func newTestUI() (*UI, *fakeView) {
+	view := &fakeView{
+		columns: []string{"container", "pid", "comm", "reads"},
+		shown:   []string{"pid", "comm", "reads"},
+		sortBy:  []string{"-reads"},
+		values:  map[string]string{"container": "nginx"},
+	}
+	return New("top file", view), view
+}
+
+func TestParseKeys(t *testing.T) {
+	events := parseKeys([]byte("a\x1b[A\x1b[B\x1b[C\x1b[D\x1b[5~\x1b[6~\x1bOH\r\x7f\x03\x1bä"))
+	assert.Equal(t, []keyEvent{
+		{key: keyRune, rune: 'a'},
+		{key: keyUp},
+		{key: keyDown},
+		{key: keyRight},
+		{key: keyLeft},
+		{key: keyPageUp},
+		{key: keyPageDown},
+		{key: keyHome},
+		{key: keyEnter},
+		{key: keyBackspace},
+		{key: keyCtrlC},
+		{key: keyEscape},
+		{key: keyRune, rune: 'ä'},
+	}, events)
+
+	// Unknown sequences are skipped
+	assert.Equal(t, []keyEvent{{key: keyRune, rune: 'x'}}, parseKeys([]byte("\x1b[3~x")))
+}
+
+func TestSorting(t *testing.T) {
+	ui, view := newTestUI()
+	assert.Equal(t, "reads", ui.sortColumn)
+	assert.True(t, ui.sortDesc)
+
+	ui.handleKey(keyEvent{key: keyRight})
+	assert.Equal(t, []string{"-pid"}, view.sortBy)
+
+	ui.handleKey(keyEvent{key: keyRune, rune: 'r'})
+	assert.Equal(t, []string{"pid"}, view.sortBy)
+
+	ui.handleKey(keyEvent{key: keyLeft})
+	assert.Equal(t, []string{"reads"}, view.sortBy)
+}
+
+func TestFilter(t *testing.T) {
+	ui, view := newTestUI()
+
+	ui.handleKey(keyEvent{key: keyRune, rune: '/'})
+	for _, ev := range runes("comm:cat,pid:>10") {
+		ui.handleKey(ev)
+	}
+	assert.Equal(t, modeFilter, ui.mode)
+	assert.Nil(t, view.filters)
+
+	ui.handleKey(keyEvent{key: keyEnter})
+	assert.Equal(t, modeTable, ui.mode)
+	assert.Equal(t, []string{"comm:cat", "pid:>10"}, view.filters)
+
+	// Drilling down into a container keeps the filters
+	ui.handleKey(keyEvent{key: keyEnter})
+	assert.Equal(t, []string{"container:nginx", "comm:cat", "pid:>10"}, view.filters)
+	assert.Equal(t, "nginx", ui.drillLabel)
+
+	ui.handleKey(keyEvent{key: keyEscape})
+	assert.Equal(t, []string{"comm:cat", "pid:>10"}, view.filters)
+
+	// Clearing the filter
+	ui.handleKey(keyEvent{key: keyRune, rune: '/'})
+	for i := 0; i < len("comm:cat,pid:>10"); i++ {
+		ui.handleKey(keyEvent{key: keyBackspace})
+	}
+	ui.handleKey(keyEvent{key: keyEnter})
+	assert.Empty(t, view.filters)
+}
+
+func TestColumnsAndPause(t *testing.T) {
+	ui, view := newTestUI()
+
+	ui.handleKey(keyEvent{key: keyRune, rune: 'c'})
+	require.Equal(t, modeColumns, ui.mode)
+
+	// Show the container column, which comes first
+	ui.handleKey(keyEvent{key: keyRune, rune: ' '})
+	assert.Equal(t, []string{"container", "pid", "comm", "reads"}, view.shown)
+
+	// Hide the comm column
+	ui.handleKey(keyEvent{key: keyDown})
+	ui.handleKey(keyEvent{key: keyDown})
+	ui.handleKey(keyEvent{key: keyRune, rune: ' '})
+	assert.Equal(t, []string{"container", "pid", "reads"}, view.shown)
+
+	ui.handleKey(keyEvent{key: keyEnter})
+	assert.Equal(t, modeTable, ui.mode)
+
+	ui.handleKey(keyEvent{key: keyRune, rune: ' '})
+	assert.True(t, view.paused)
+	ui.handleKey(keyEvent{key: keyRune, rune: ' '})
+	assert.False(t, view.paused)
+
+	assert.True(t, ui.handleKey(keyEvent{key: keyRune, rune: 'q'}))
+}
+
+func TestReadKeysStops(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+	})
+
+	keys := make(chan []keyEvent)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		readKeys(r, keys, done)
+		close(stopped)
+	}()
+
+	_, err = w.Write([]byte("q"))
+	require.NoError(t, err)
+	assert.Equal(t, runes("q"), <-keys)
+
+	// Reading stops without further input
+	close(done)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("readKeys didn't stop")
+	}
+	_, ok := <-keys
+	assert.False(t, ok)
+}
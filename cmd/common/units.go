@@ -0,0 +1,63 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+)
+
+var (
+	units           string
+	timestampFormat string
+)
+
+func addUnitsFlags(rootCmd *cobra.Command) {
+	rootCmd.PersistentFlags().StringVar(
+		&units,
+		"units",
+		string(textcolumns.UnitsRaw),
+		fmt.Sprintf("How to print sizes, durations and rates in columns output: %s or %s", textcolumns.UnitsRaw, textcolumns.UnitsHuman),
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&timestampFormat,
+		"timestamp",
+		string(textcolumns.TimestampAbsolute),
+		fmt.Sprintf("How to print timestamps in columns output: %s, %s or %s",
+			textcolumns.TimestampAbsolute, textcolumns.TimestampRelative, textcolumns.TimestampUnix),
+	)
+}
+
+// unitsOptions returns the textcolumns options matching the --units and --timestamp flags
+func unitsOptions() ([]textcolumns.Option, error) {
+	switch textcolumns.Units(units) {
+	case textcolumns.UnitsRaw, textcolumns.UnitsHuman:
+	default:
+		return nil, fmt.Errorf("invalid value %q for --units: expected %s or %s", units, textcolumns.UnitsRaw, textcolumns.UnitsHuman)
+	}
+	switch textcolumns.TimestampFormat(timestampFormat) {
+	case textcolumns.TimestampAbsolute, textcolumns.TimestampRelative, textcolumns.TimestampUnix:
+	default:
+		return nil, fmt.Errorf("invalid value %q for --timestamp: expected %s, %s or %s", timestampFormat,
+			textcolumns.TimestampAbsolute, textcolumns.TimestampRelative, textcolumns.TimestampUnix)
+	}
+	return []textcolumns.Option{
+		textcolumns.WithUnits(textcolumns.Units(units)),
+		textcolumns.WithTimestamp(textcolumns.TimestampFormat(timestampFormat)),
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends/console"
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends/web"
+	cols "github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+)
+
+const defaultWebAddress = "127.0.0.1:8080"
+
+// addWebCommand adds a command that serves a web interface to run gadgets in a browser using the given runtime
+func addWebCommand(
+	rootCmd *cobra.Command,
+	runtime runtime.Runtime,
+	runtimeGlobalParams *params.Params,
+	operatorsGlobalParamsCollection params.Collection,
+	columnFilters []cols.ColumnFilter,
+) {
+	var address string
+
+	cmd := &cobra.Command{
+		Use:          "web",
+		Short:        "Serve a web interface to run gadgets in a browser",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runtime.Init(runtimeGlobalParams)
+			if err != nil {
+				return fmt.Errorf("initializing runtime: %w", err)
+			}
+			defer runtime.Close()
+
+			fe := console.NewFrontend()
+			defer fe.Close()
+
+			if host, _, err := net.SplitHostPort(address); err == nil {
+				if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+					log.Warnf("The web interface has no authentication; everyone who can reach %s can run gadgets", address)
+				}
+			}
+
+			log.Infof("Serving web interface on http://%s", address)
+			server := web.NewServer(runtime, operatorsGlobalParamsCollection, columnFilters)
+			defer server.Close()
+
+			return server.Run(fe.GetContext(), address)
+		},
+	}
+	cmd.Flags().StringVar(&address, "address", defaultWebAddress, "Address to serve the web interface on")
+
+	rootCmd.AddCommand(cmd)
+}
@@ -34,7 +34,10 @@ import (
 
 	// Another blank import for the used operator
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/localmanager"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otellogs"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/processtree"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/prometheus"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ratelimit"
 )
 
 func main() {
@@ -57,6 +60,7 @@ func main() {
 	// columnFilters for ig
 	columnFilters := []columns.ColumnFilter{columns.WithoutExceptTag("kubernetes", "runtime")}
 	common.AddCommandsFromRegistry(rootCmd, runtime, columnFilters)
+	rootCmd.AddCommand(common.NewReplayCmd(columnFilters))
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
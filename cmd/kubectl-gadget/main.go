@@ -43,7 +43,7 @@ var rootCmd = &cobra.Command{
 	Short: "Collection of gadgets for Kubernetes developers",
 }
 
-var infoSkipCommands = []string{"deploy", "undeploy", "version"}
+var infoSkipCommands = []string{"deploy", "undeploy", "version", "replay"}
 
 func init() {
 	utils.FlagInit(rootCmd)
@@ -77,6 +77,7 @@ func main() {
 	// columnFilters for kubectl-gadget
 	columnFilters := []columns.ColumnFilter{columns.WithoutExceptTag("runtime", "kubernetes")}
 	common.AddCommandsFromRegistry(rootCmd, runtime, columnFilters)
+	rootCmd.AddCommand(common.NewReplayCmd(columnFilters))
 
 	// Advise category is still being handled by CRs for now
 	rootCmd.AddCommand(advise.NewAdviseCmd())
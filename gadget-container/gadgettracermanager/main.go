@@ -44,9 +44,12 @@ import (
 	// The script gadget is designed only to work in k8s, hence it's not part of all-gadgets
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/script"
 
+	"github.com/inspektor-gadget/inspektor-gadget/cmd/common/frontends/web"
 	gadgetservice "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-service"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgettracermanager"
 	pb "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgettracermanager/api"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/local"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
 )
 
@@ -55,6 +58,8 @@ var (
 	serve                   bool
 	liveness                bool
 	fallbackPodInformer     bool
+	hostCgroups             bool
+	eventLabels             string
 	dump                    string
 	hookMode                string
 	socketfile              string
@@ -67,6 +72,7 @@ var (
 	podname                 string
 	containername           string
 	containerPid            uint
+	webAddress              string
 )
 
 var clientTimeout = 2 * time.Second
@@ -74,7 +80,7 @@ var clientTimeout = 2 * time.Second
 func init() {
 	flag.StringVar(&socketfile, "socketfile", "/run/gadgettracermanager.socket", "Socket file")
 	flag.StringVar(&gadgetServiceSocketFile, "service-socketfile", pb.GadgetServiceSocket, "Socket file for gadget service")
-	flag.StringVar(&hookMode, "hook-mode", "auto", "how to get containers start/stop notifications (podinformer, fanotify, auto, none)")
+	flag.StringVar(&hookMode, "hook-mode", "auto", "how to get containers start/stop notifications (podinformer, fanotify, fanotify+ebpf, runtime-events, auto, none)")
 
 	flag.BoolVar(&serve, "serve", false, "Start server")
 	flag.BoolVar(&controller, "controller", false, "Enable the controller for custom resources")
@@ -92,6 +98,10 @@ func init() {
 
 	flag.BoolVar(&liveness, "liveness", false, "Execute as client and perform liveness probe")
 	flag.BoolVar(&fallbackPodInformer, "fallback-podinformer", true, "Use pod informer as a fallback for main hook")
+	flag.BoolVar(&hostCgroups, "host-cgroups", true, "Track the systemd services of the host so they can be selected by cgroup path or systemd unit")
+	flag.StringVar(&eventLabels, "event-labels", "", "Comma-separated list of container or image labels to add to the events of all gadgets in the containerlabels column")
+
+	flag.StringVar(&webAddress, "web-address", "", "Loopback address to serve the web interface on, reachable with kubectl port-forward (disabled if empty); it has no authentication")
 }
 
 func main() {
@@ -279,6 +289,8 @@ func main() {
 			NodeName:            node,
 			HookMode:            hookMode,
 			FallbackPodInformer: fallbackPodInformer,
+			HostCgroups:         hostCgroups,
+			EventLabels:         splitEventLabels(eventLabels),
 		})
 
 		if err != nil {
@@ -305,11 +317,64 @@ func main() {
 			}
 		}()
 
+		webCtx, webCancel := context.WithCancel(context.Background())
+		if webAddress != "" {
+			go func() {
+				err := runWebServer(webCtx, webAddress)
+				if err != nil {
+					log.Fatalf("failed to start web interface: %v", err)
+				}
+			}()
+		}
+
 		exitSignal := make(chan os.Signal, 1)
 		signal.Notify(exitSignal, syscall.SIGINT, syscall.SIGTERM)
 		<-exitSignal
 
+		webCancel()
 		service.Close()
 		tracerManager.Close()
 	}
 }
+
+// runWebServer serves the web interface using a local runtime until ctx is done. As it has no authentication, it
+// only listens on the loopback interface of the pod, so it can only be reached using kubectl port-forward.
+func runWebServer(ctx context.Context, address string) error {
+	if !isLoopbackAddress(address) {
+		return fmt.Errorf("address %q is not a loopback address", address)
+	}
+
+	runtime := local.New()
+	defer runtime.Close()
+
+	err := runtime.Init(runtime.GlobalParamDescs().ToParams())
+	if err != nil {
+		return fmt.Errorf("initializing runtime: %w", err)
+	}
+
+	server := web.NewServer(runtime, operators.GlobalParamsCollection(), nil)
+	defer server.Close()
+
+	log.Infof("Serving web interface on %s", address)
+	return server.Run(ctx, address)
+}
+
+// isLoopbackAddress returns whether address, as host:port, only listens on the loopback interface
+func isLoopbackAddress(address string) bool {
+	h, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if h == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(h)
+	return ip != nil && ip.IsLoopback()
+}
+
+func splitEventLabels(labels string) []string {
+	if labels == "" {
+		return nil
+	}
+	return strings.Split(labels, ",")
+}
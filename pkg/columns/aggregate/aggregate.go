@@ -0,0 +1,328 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+type Function string
+
+const (
+	FunctionCount Function = "count"
+	FunctionSum   Function = "sum"
+	FunctionMin   Function = "min"
+	FunctionMax   Function = "max"
+	FunctionAvg   Function = "avg"
+)
+
+// Aggregation describes a single aggregation like "count" or "sum(size)"
+type Aggregation struct {
+	Function Function
+	Column   string
+}
+
+func (a Aggregation) String() string {
+	if a.Column == "" {
+		return string(a.Function)
+	}
+	return fmt.Sprintf("%s(%s)", a.Function, a.Column)
+}
+
+// ParseAggregation parses an aggregation given in the form "function" or "function(columnName)"
+func ParseAggregation(aggregation string) (Aggregation, error) {
+	aggregation = strings.TrimSpace(aggregation)
+
+	function, columnName := aggregation, ""
+	if idx := strings.Index(aggregation, "("); idx >= 0 {
+		if !strings.HasSuffix(aggregation, ")") {
+			return Aggregation{}, fmt.Errorf("invalid aggregation %q: missing closing parenthesis", aggregation)
+		}
+		function = strings.TrimSpace(aggregation[:idx])
+		columnName = strings.TrimSpace(aggregation[idx+1 : len(aggregation)-1])
+	}
+
+	a := Aggregation{
+		Function: Function(strings.ToLower(function)),
+		Column:   strings.ToLower(columnName),
+	}
+
+	switch a.Function {
+	case FunctionCount:
+		if a.Column != "" {
+			return Aggregation{}, fmt.Errorf("invalid aggregation %q: %s doesn't take a column", aggregation, a.Function)
+		}
+	case FunctionSum, FunctionMin, FunctionMax, FunctionAvg:
+		if a.Column == "" {
+			return Aggregation{}, fmt.Errorf("invalid aggregation %q: %s needs a column", aggregation, a.Function)
+		}
+	default:
+		return Aggregation{}, fmt.Errorf("invalid aggregation %q: unknown function %q", aggregation, function)
+	}
+	return a, nil
+}
+
+// Spec describes how entries should be aggregated. It doesn't depend on the type of the entries, so it can be used
+// to prepare the output of the results independently.
+type Spec struct {
+	GroupBy      []string
+	Aggregations []Aggregation
+}
+
+// NewSpec creates a new Spec that groups by the given column names and calculates the given aggregations (see
+// ParseAggregation) for each group. If no aggregation is given, entries will be counted.
+func NewSpec(groupBy []string, aggregations []string) (*Spec, error) {
+	spec := &Spec{}
+	for _, columnName := range groupBy {
+		columnName = strings.ToLower(strings.TrimSpace(columnName))
+		if columnName == "" {
+			continue
+		}
+		spec.GroupBy = append(spec.GroupBy, columnName)
+	}
+
+	if len(aggregations) == 0 {
+		aggregations = []string{string(FunctionCount)}
+	}
+	for _, aggregation := range aggregations {
+		a, err := ParseAggregation(aggregation)
+		if err != nil {
+			return nil, err
+		}
+		spec.Aggregations = append(spec.Aggregations, a)
+	}
+	return spec, nil
+}
+
+// ColumnNames returns the names of the columns of the results: first the columns grouped by, then the aggregations
+func (s *Spec) ColumnNames() []string {
+	names := make([]string, 0, len(s.GroupBy)+len(s.Aggregations))
+	names = append(names, s.GroupBy...)
+	for _, a := range s.Aggregations {
+		names = append(names, a.String())
+	}
+	return names
+}
+
+// Row holds the result of a single group
+type Row struct {
+	// Keys holds the values of the columns grouped by (as strings)
+	Keys []string
+	// Values holds the results of the aggregations
+	Values []float64
+}
+
+// Columns returns columns for rows created according to the spec; this can be used to output them using
+// formatters
+func (s *Spec) Columns() *columns.Columns[Row] {
+	cols := columns.MustCreateColumns[Row](columns.WithRequireColumnDefinition(true))
+	for i, columnName := range s.GroupBy {
+		i := i
+		cols.MustAddColumn(columns.Attributes{
+			Name:     columnName,
+			Width:    16,
+			MinWidth: 4,
+			Visible:  true,
+			Order:    i * 10,
+		}, func(row *Row) string {
+			return row.Keys[i]
+		})
+	}
+	for i, a := range s.Aggregations {
+		i, a := i, a
+		cols.MustAddColumn(columns.Attributes{
+			Name:      a.String(),
+			Width:     12,
+			MinWidth:  len(a.String()),
+			Alignment: columns.AlignRight,
+			Visible:   true,
+			Order:     (len(s.GroupBy) + i) * 10,
+		}, func(row *Row) string {
+			return formatValue(a, row.Values[i])
+		})
+	}
+	return cols
+}
+
+// RowToMap returns the given row as map of column names to values, e.g. to be marshaled to JSON
+func (s *Spec) RowToMap(row *Row) map[string]any {
+	res := make(map[string]any, len(s.GroupBy)+len(s.Aggregations))
+	for i, columnName := range s.GroupBy {
+		res[columnName] = row.Keys[i]
+	}
+	for i, a := range s.Aggregations {
+		res[a.String()] = row.Values[i]
+	}
+	return res
+}
+
+func formatValue(a Aggregation, value float64) string {
+	if a.Function == FunctionAvg {
+		return strconv.FormatFloat(value, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+type group struct {
+	keys   []string
+	count  int64
+	values []float64
+}
+
+// Aggregator aggregates entries of type *T according to a Spec; it is safe to be used from multiple goroutines
+type Aggregator[T any] struct {
+	spec       *Spec
+	keyFuncs   []func(*T) string
+	valueFuncs []func(*T) float64
+
+	mu     sync.Mutex
+	groups map[string]*group
+}
+
+// NewAggregator creates a new Aggregator for entries of type *T; it verifies that all columns used in the spec
+// exist and that aggregations are only done on numeric columns
+func NewAggregator[T any](cols columns.ColumnMap[T], spec *Spec) (*Aggregator[T], error) {
+	a := &Aggregator[T]{
+		spec:   spec,
+		groups: make(map[string]*group),
+	}
+
+	for _, columnName := range spec.GroupBy {
+		column, ok := cols.GetColumn(columnName)
+		if !ok {
+			return nil, fmt.Errorf("grouping by %q: column not found", columnName)
+		}
+		if column.HasCustomExtractor() {
+			a.keyFuncs = append(a.keyFuncs, column.Extractor)
+			continue
+		}
+		a.keyFuncs = append(a.keyFuncs, columns.GetFieldAsString[T](column))
+	}
+
+	for _, aggregation := range spec.Aggregations {
+		if aggregation.Function == FunctionCount {
+			a.valueFuncs = append(a.valueFuncs, nil)
+			continue
+		}
+		column, ok := cols.GetColumn(aggregation.Column)
+		if !ok {
+			return nil, fmt.Errorf("aggregation %q: column not found", aggregation)
+		}
+		switch column.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+		default:
+			return nil, fmt.Errorf("aggregation %q: column is not numeric", aggregation)
+		}
+		a.valueFuncs = append(a.valueFuncs, columns.GetFieldAsNumberFunc[float64, T](column))
+	}
+
+	return a, nil
+}
+
+// Add adds a single entry to its group
+func (a *Aggregator[T]) Add(entry *T) {
+	if entry == nil {
+		return
+	}
+
+	keys := make([]string, len(a.keyFuncs))
+	for i, keyFunc := range a.keyFuncs {
+		keys[i] = keyFunc(entry)
+	}
+	key := strings.Join(keys, "\x00")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	g, ok := a.groups[key]
+	if !ok {
+		g = &group{
+			keys:   keys,
+			values: make([]float64, len(a.valueFuncs)),
+		}
+		a.groups[key] = g
+	}
+	g.count++
+
+	for i, valueFunc := range a.valueFuncs {
+		if valueFunc == nil {
+			continue
+		}
+		value := valueFunc(entry)
+		switch a.spec.Aggregations[i].Function {
+		case FunctionSum, FunctionAvg:
+			g.values[i] += value
+		case FunctionMin:
+			if g.count == 1 || value < g.values[i] {
+				g.values[i] = value
+			}
+		case FunctionMax:
+			if g.count == 1 || value > g.values[i] {
+				g.values[i] = value
+			}
+		}
+	}
+}
+
+// Flush returns the results for all groups and resets the aggregation. Rows are sorted by the value of the first
+// aggregation (descending) and then by their keys.
+func (a *Aggregator[T]) Flush() []*Row {
+	a.mu.Lock()
+	groups := a.groups
+	a.groups = make(map[string]*group)
+	a.mu.Unlock()
+
+	rows := make([]*Row, 0, len(groups))
+	for _, g := range groups {
+		row := &Row{
+			Keys:   g.keys,
+			Values: make([]float64, len(a.spec.Aggregations)),
+		}
+		for i, aggregation := range a.spec.Aggregations {
+			switch aggregation.Function {
+			case FunctionCount:
+				row.Values[i] = float64(g.count)
+			case FunctionAvg:
+				row.Values[i] = g.values[i] / float64(g.count)
+			default:
+				row.Values[i] = g.values[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if len(rows[i].Values) > 0 && rows[i].Values[0] != rows[j].Values[0] {
+			return rows[i].Values[0] > rows[j].Values[0]
+		}
+		for k := range rows[i].Keys {
+			if rows[i].Keys[k] != rows[j].Keys[k] {
+				return rows[i].Keys[k] < rows[j].Keys[k]
+			}
+		}
+		return false
+	})
+
+	return rows
+}
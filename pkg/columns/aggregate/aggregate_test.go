@@ -0,0 +1,152 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+)
+
+type testEvent struct {
+	Comm string  `column:"comm"`
+	Port uint16  `column:"port"`
+	Size int64   `column:"size"`
+	Lat  float64 `column:"lat"`
+}
+
+func TestParseAggregation(t *testing.T) {
+	type aggregationTest struct {
+		aggregation string
+		expected    Aggregation
+		expectError bool
+	}
+
+	tests := []aggregationTest{
+		{aggregation: "count", expected: Aggregation{Function: FunctionCount}},
+		{aggregation: "SUM(Size)", expected: Aggregation{Function: FunctionSum, Column: "size"}},
+		{aggregation: " avg( lat ) ", expected: Aggregation{Function: FunctionAvg, Column: "lat"}},
+		{aggregation: "max(port)", expected: Aggregation{Function: FunctionMax, Column: "port"}},
+		{aggregation: "count(size)", expectError: true},
+		{aggregation: "sum", expectError: true},
+		{aggregation: "sum(size", expectError: true},
+		{aggregation: "median(size)", expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.aggregation, func(t *testing.T) {
+			a, err := ParseAggregation(test.aggregation)
+			if test.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, a)
+		})
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	cols, err := columns.NewColumns[testEvent]()
+	require.NoError(t, err)
+
+	spec, err := NewSpec([]string{"comm", "port"}, []string{"count", "sum(size)", "min(size)", "max(lat)", "avg(size)"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"comm", "port", "count", "sum(size)", "min(size)", "max(lat)", "avg(size)"}, spec.ColumnNames())
+
+	aggregator, err := NewAggregator(cols.GetColumnMap(), spec)
+	require.NoError(t, err)
+
+	aggregator.Add(&testEvent{Comm: "curl", Port: 443, Size: 10, Lat: 1.5})
+	aggregator.Add(&testEvent{Comm: "curl", Port: 443, Size: 20, Lat: 0.5})
+	aggregator.Add(&testEvent{Comm: "curl", Port: 80, Size: 5, Lat: 2})
+	aggregator.Add(&testEvent{Comm: "wget", Port: 80, Size: 7, Lat: 3})
+	aggregator.Add(nil)
+
+	rows := aggregator.Flush()
+	require.Equal(t, []*Row{
+		{Keys: []string{"curl", "443"}, Values: []float64{2, 30, 10, 1.5, 15}},
+		{Keys: []string{"curl", "80"}, Values: []float64{1, 5, 5, 2, 5}},
+		{Keys: []string{"wget", "80"}, Values: []float64{1, 7, 7, 3, 7}},
+	}, rows)
+
+	assert.Equal(t, map[string]any{
+		"comm":      "curl",
+		"port":      "443",
+		"count":     float64(2),
+		"sum(size)": float64(30),
+		"min(size)": float64(10),
+		"max(lat)":  1.5,
+		"avg(size)": float64(15),
+	}, spec.RowToMap(rows[0]))
+
+	// Flushing resets the aggregation
+	assert.Empty(t, aggregator.Flush())
+	aggregator.Add(&testEvent{Comm: "bash", Size: 1})
+	assert.Len(t, aggregator.Flush(), 1)
+}
+
+func TestAggregatorWithoutGroups(t *testing.T) {
+	cols, err := columns.NewColumns[testEvent]()
+	require.NoError(t, err)
+
+	spec, err := NewSpec(nil, nil)
+	require.NoError(t, err)
+
+	aggregator, err := NewAggregator(cols.GetColumnMap(), spec)
+	require.NoError(t, err)
+
+	aggregator.Add(&testEvent{Comm: "curl"})
+	aggregator.Add(&testEvent{Comm: "wget"})
+
+	require.Equal(t, []*Row{{Keys: []string{}, Values: []float64{2}}}, aggregator.Flush())
+}
+
+func TestAggregatorInvalidSpec(t *testing.T) {
+	cols, err := columns.NewColumns[testEvent]()
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		groupBy      []string
+		aggregations []string
+	}{
+		{groupBy: []string{"unknown"}},
+		{aggregations: []string{"sum(unknown)"}},
+		{aggregations: []string{"sum(comm)"}},
+	} {
+		spec, err := NewSpec(test.groupBy, test.aggregations)
+		require.NoError(t, err)
+		_, err = NewAggregator(cols.GetColumnMap(), spec)
+		assert.Error(t, err)
+	}
+}
+
+func TestSpecColumns(t *testing.T) {
+	spec, err := NewSpec([]string{"comm"}, []string{"count", "avg(size)"})
+	require.NoError(t, err)
+
+	formatter := textcolumns.NewFormatter(spec.Columns().GetColumnMap())
+	formatter.SetAutoScale(false)
+
+	assert.Equal(t, "COMM                    COUNT    AVG(SIZE)", formatter.FormatHeader())
+	assert.Equal(t, "curl                        3        10.50", formatter.FormatEntry(&Row{
+		Keys:   []string{"curl"},
+		Values: []float64{3, 10.5},
+	}))
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package aggregate can aggregate a stream of entries by one or more columns. In contrast to the group package, entries
+don't have to be available as an array, but are added one by one. Results are retrieved by calling Flush(), which
+also resets the aggregation, so it can be used to build periodic tables (like the ones of top gadgets) out of any
+stream of events.
+
+Aggregations are given as strings in the form "function" or "function(columnName)". Supported functions are
+
+	count       number of entries in the group
+	sum(col)    sum of the values of col
+	min(col)    minimum value of col
+	max(col)    maximum value of col
+	avg(col)    average value of col
+
+All functions except count require a numeric column.
+*/
+package aggregate
@@ -74,6 +74,8 @@ type Attributes struct {
 	Tags []string `yaml:"tags"`
 	// Template defines the template that will be used. Non-typed templates will be applied first.
 	Template string `yaml:"template"`
+	// Unit defines the unit of numeric values of this column; formatters can use it to show human-readable values
+	Unit Unit `yaml:"unit"`
 }
 
 type Column[T any] struct {
@@ -259,6 +261,21 @@ func (ci *Column[T]) parseTagInfo(tagInfo []string) error {
 				return fmt.Errorf("negative precision value %q for field %q", params[1], ci.Name)
 			}
 			ci.Precision = w
+		case "unit":
+			if paramsLen == 1 {
+				return fmt.Errorf("missing unit value for field %q", ci.Name)
+			}
+			switch unit := Unit(params[1]); unit {
+			case UnitBytes, UnitNanoseconds, UnitRate, UnitTimestamp:
+				// Use the raw type here, as a custom extractor or stringer could already have turned this into a
+				// string column
+				if ci.rawColumnType == nil || !isNumberKind(ci.rawColumnType.Kind()) {
+					return fmt.Errorf("field %q is not a numeric field and thereby cannot have a unit defined", ci.Name)
+				}
+				ci.Unit = unit
+			default:
+				return fmt.Errorf("invalid unit %q for field %q", params[1], ci.Name)
+			}
 		case "width":
 			ci.Width, err = ci.getWidth(params)
 			if err != nil {
@@ -413,6 +430,22 @@ func (ci *Column[T]) IsVirtual() bool {
 	return ci.fieldIndex == virtualIndex
 }
 
+// FieldIndex returns the index sequence of the struct field that backs the column (see reflect.Type.FieldByIndex).
+// It returns nil for virtual columns and columns added using AddFields.
+func (ci *Column[T]) FieldIndex() []int {
+	if ci.fieldIndex == virtualIndex || ci.fieldIndex == manualIndex {
+		return nil
+	}
+	if len(ci.subFieldIndex) == 0 {
+		return []int{ci.fieldIndex}
+	}
+	index := make([]int, 0, len(ci.subFieldIndex))
+	for _, sub := range ci.subFieldIndex {
+		index = append(index, sub.index)
+	}
+	return index
+}
+
 // HasCustomExtractor returns true, if the column has a user defined extractor set
 func (ci *Column[T]) HasCustomExtractor() bool {
 	return ci.Extractor != nil
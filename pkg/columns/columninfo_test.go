@@ -242,6 +242,53 @@ func TestColumnsPrecision(t *testing.T) {
 	}](t, "invalid field")
 }
 
+func TestColumnsUnit(t *testing.T) {
+	type testSuccess1 struct {
+		Bytes     uint64  `column:"bytes,unit:bytes"`
+		Duration  int64   `column:"duration,unit:ns"`
+		Rate      float64 `column:"rate,unit:rate"`
+		Timestamp int64   `column:"timestamp,unit:timestamp"`
+		None      uint64  `column:"none"`
+	}
+
+	cols := expectColumnsSuccess[testSuccess1](t)
+	expectColumnValue(t, expectColumn(t, cols, "bytes"), "Unit", UnitBytes)
+	expectColumnValue(t, expectColumn(t, cols, "duration"), "Unit", UnitNanoseconds)
+	expectColumnValue(t, expectColumn(t, cols, "rate"), "Unit", UnitRate)
+	expectColumnValue(t, expectColumn(t, cols, "timestamp"), "Unit", UnitTimestamp)
+	expectColumnValue(t, expectColumn(t, cols, "none"), "Unit", UnitNone)
+
+	expectColumnsFail[struct {
+		Field uint64 `column:"fail,unit"`
+	}](t, "missing parameter")
+	expectColumnsFail[struct {
+		Field uint64 `column:"fail,unit:"`
+	}](t, "empty parameter")
+	expectColumnsFail[struct {
+		Field uint64 `column:"fail,unit:foo"`
+	}](t, "invalid parameter")
+	expectColumnsFail[struct {
+		Field string `column:"fail,unit:bytes"`
+	}](t, "invalid field")
+}
+
+func TestColumnsUnitRawValue(t *testing.T) {
+	type testStruct struct {
+		Duration int64 `column:"duration,unit:ns"`
+	}
+
+	cols := expectColumnsSuccess[testStruct](t)
+	col := expectColumn(t, cols, "duration")
+	col.Extractor = func(entry *testStruct) string {
+		return "extracted"
+	}
+
+	ff := GetFieldAsNumberFuncExt[int64, testStruct](col, true)
+	if v := ff(&testStruct{Duration: 1234}); v != 1234 {
+		t.Errorf("Expected raw value 1234, got %d", v)
+	}
+}
+
 func TestColumnsWidth(t *testing.T) {
 	type testSuccess1 struct {
 		FieldWidth     int64 `column:"int,width:4"`
@@ -625,69 +625,79 @@ func GetFieldAsString[T any](column ColumnInternals) func(entry *T) string {
 
 // GetFieldAsNumberFunc returns a helper function to access a field of struct T as a number.
 func GetFieldAsNumberFunc[OT constraints.Integer | constraints.Float, T any](column ColumnInternals) func(entry *T) OT {
-	switch column.(*Column[T]).Kind() {
+	return GetFieldAsNumberFuncExt[OT, T](column, false)
+}
+
+// GetFieldAsNumberFuncExt returns a helper function to access a field of struct T as a number. If raw is set, even
+// if a custom extractor has been set, the returned func will access the underlying value.
+func GetFieldAsNumberFuncExt[OT constraints.Integer | constraints.Float, T any](column ColumnInternals, raw bool) func(entry *T) OT {
+	kind := column.(*Column[T]).Kind()
+	if raw && !column.IsVirtual() {
+		kind = column.(*Column[T]).RawType().Kind()
+	}
+	switch kind {
 	default:
 		var defaultValue OT
 		return func(entry *T) OT {
 			return defaultValue
 		}
 	case reflect.Int:
-		ff := GetFieldFunc[int, T](column)
+		ff := GetFieldFuncExt[int, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Int8:
-		ff := GetFieldFunc[int8, T](column)
+		ff := GetFieldFuncExt[int8, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Int16:
-		ff := GetFieldFunc[int16, T](column)
+		ff := GetFieldFuncExt[int16, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Int32:
-		ff := GetFieldFunc[int32, T](column)
+		ff := GetFieldFuncExt[int32, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Int64:
-		ff := GetFieldFunc[int64, T](column)
+		ff := GetFieldFuncExt[int64, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Uint:
-		ff := GetFieldFunc[uint, T](column)
+		ff := GetFieldFuncExt[uint, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Uint8:
-		ff := GetFieldFunc[uint8, T](column)
+		ff := GetFieldFuncExt[uint8, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Uint16:
-		ff := GetFieldFunc[uint16, T](column)
+		ff := GetFieldFuncExt[uint16, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Uint32:
-		ff := GetFieldFunc[uint32, T](column)
+		ff := GetFieldFuncExt[uint32, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Uint64:
-		ff := GetFieldFunc[uint64, T](column)
+		ff := GetFieldFuncExt[uint64, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Float32:
-		ff := GetFieldFunc[float32, T](column)
+		ff := GetFieldFuncExt[float32, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
 	case reflect.Float64:
-		ff := GetFieldFunc[float64, T](column)
+		ff := GetFieldFuncExt[float64, T](column, raw)
 		return func(entry *T) OT {
 			return OT(ff(entry))
 		}
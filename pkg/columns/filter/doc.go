@@ -36,6 +36,16 @@ Additional rule options for integers, floats and strings are `>`, `>=`, `<` and
 
 	filter.FilterEntries(columnMap, events, []string{"pid:>=55"})
 
+# Expressions
+
+Instead of a single rule, a filter string can also hold an expression that combines rules across columns:
+
+	filter.FilterEntries(columnMap, events, []string{`comm == "curl" && (port in (80,443) || dst ~ "10.0.0.0/8")`})
+
+Supported operators are ==, !=, <, <=, >, >=, ~ and !~ (regular expression or CIDR matching), in (...), contains,
+startswith and endswith. Comparisons can be combined using &&, || and !, and grouped using parentheses. Expressions
+are compiled once using CompileExpression; see there for details.
+
 # Optimizing / Streaming
 
 If you have to filter a stream of incoming events, you can use
@@ -0,0 +1,443 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+var simpleFilterRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]+(:|$)`)
+
+// IsExpression returns true, if filter is not a simple "columnName:rule" filter and should be handled by
+// CompileExpression instead
+func IsExpression(filter string) bool {
+	return !simpleFilterRegex.MatchString(strings.TrimSpace(filter))
+}
+
+// SplitFilters splits a comma separated list of filters while keeping commas inside of parentheses or quotes
+// intact, so that expressions like `port in (80,443)` can be used in a list of filters
+func SplitFilters(s string) []string {
+	var res []string
+	depth := 0
+	var quote rune
+	escaped := false
+	start := 0
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if c == '\\' {
+				escaped = true
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+		case c == ',' && depth == 0:
+			res = append(res, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(res, s[start:])
+}
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOp
+	tokenString
+	tokenWord
+)
+
+type token struct {
+	typ tokenType
+	val string
+	pos int
+}
+
+func (t token) String() string {
+	if t.typ == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q at position %d", t.val, t.pos)
+}
+
+const wordTerminators = "()\"',=!<>~&| \t\r\n"
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRParen, ")", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"):
+			tokens = append(tokens, token{tokenAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, token{tokenOr, "||", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "=="),
+			strings.HasPrefix(expr[i:], "!="),
+			strings.HasPrefix(expr[i:], "!~"),
+			strings.HasPrefix(expr[i:], "<="),
+			strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, token{tokenOp, expr[i : i+2], i})
+			i += 2
+		case c == '<' || c == '>' || c == '~':
+			tokens = append(tokens, token{tokenOp, expr[i : i+1], i})
+			i++
+		case c == '!':
+			tokens = append(tokens, token{tokenNot, "!", i})
+			i++
+		case c == '"' || c == '\'':
+			var sb strings.Builder
+			start := i
+			i++
+			closed := false
+			for i < len(expr) {
+				if expr[i] == '\\' && i+1 < len(expr) {
+					sb.WriteByte(expr[i+1])
+					i += 2
+					continue
+				}
+				if expr[i] == c {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteByte(expr[i])
+				i++
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string starting at position %d", start)
+			}
+			tokens = append(tokens, token{tokenString, sb.String(), start})
+		case c == '=' || c == '&' || c == '|':
+			return nil, fmt.Errorf("unexpected %q at position %d", c, i)
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(wordTerminators, rune(expr[i])) {
+				i++
+			}
+			tokens = append(tokens, token{tokenWord, expr[start:i], start})
+		}
+	}
+	return append(tokens, token{typ: tokenEOF, pos: len(expr)}), nil
+}
+
+// Expression is a compiled filter expression like
+//
+//	comm == "curl" && (port in (80,443) || dst ~ "10.0.0.0/8")
+//
+// that can be matched against entries of type *T
+type Expression[T any] struct {
+	expr  string
+	match func(*T) bool
+}
+
+type expressionParser[T any] struct {
+	cols   columns.ColumnMap[T]
+	tokens []token
+	pos    int
+}
+
+// CompileExpression parses the given expression and compiles it into an Expression that can be used to match
+// entries of type *T. Comparisons have the form "columnName operator value"; supported operators are
+//
+//	==, !=, <, <=, >, >=         - compare the column to the value
+//	~, !~                        - match string columns against a regular expression or, if the value is a CIDR
+//	                               (e.g. "10.0.0.0/8"), check whether the IP address of the column is (not) part of it
+//	in (value1, value2, ...)     - matches, if the column is equal to any of the values
+//	contains, startswith, endswith - string matching on string columns
+//
+// Comparisons can be combined using && and ||, grouped using parentheses and negated using !. && has a higher
+// precedence than ||. Values can optionally be quoted using single or double quotes.
+func CompileExpression[T any](cols columns.ColumnMap[T], expr string) (*Expression[T], error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing expression %q: %w", expr, err)
+	}
+	p := &expressionParser[T]{
+		cols:   cols,
+		tokens: tokens,
+	}
+	match, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("parsing expression %q: %w", expr, err)
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, fmt.Errorf("parsing expression %q: unexpected %s", expr, t)
+	}
+	return &Expression[T]{
+		expr:  expr,
+		match: match,
+	}, nil
+}
+
+// Match matches a single entry against the expression and returns true if it matches
+func (e *Expression[T]) Match(entry *T) bool {
+	if entry == nil {
+		return false
+	}
+	return e.match(entry)
+}
+
+func (e *Expression[T]) String() string {
+	return e.expr
+}
+
+func (p *expressionParser[T]) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser[T]) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *expressionParser[T]) expect(typ tokenType, what string) (token, error) {
+	t := p.next()
+	if t.typ != typ {
+		return t, fmt.Errorf("expected %s, got %s", what, t)
+	}
+	return t, nil
+}
+
+func (p *expressionParser[T]) parseOr() (func(*T) bool, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().typ == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(entry *T) bool {
+			return l(entry) || right(entry)
+		}
+	}
+	return left, nil
+}
+
+func (p *expressionParser[T]) parseAnd() (func(*T) bool, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().typ == tokenAnd {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(entry *T) bool {
+			return l(entry) && right(entry)
+		}
+	}
+	return left, nil
+}
+
+func (p *expressionParser[T]) parseUnary() (func(*T) bool, error) {
+	switch p.peek().typ {
+	case tokenNot:
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(entry *T) bool {
+			return !inner(entry)
+		}, nil
+	case tokenLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *expressionParser[T]) parseValue() (string, error) {
+	t := p.next()
+	if t.typ != tokenString && t.typ != tokenWord {
+		return "", fmt.Errorf("expected value, got %s", t)
+	}
+	return t.val, nil
+}
+
+func (p *expressionParser[T]) parseComparison() (func(*T) bool, error) {
+	colToken, err := p.expect(tokenWord, "column name")
+	if err != nil {
+		return nil, err
+	}
+	column, ok := p.cols.GetColumn(colToken.val)
+	if !ok {
+		return nil, fmt.Errorf("column %q not found", colToken.val)
+	}
+
+	opToken := p.next()
+	op := opToken.val
+	switch opToken.typ {
+	case tokenOp:
+	case tokenWord:
+		op = strings.ToLower(op)
+		switch op {
+		case "in", "contains", "startswith", "endswith":
+		default:
+			return nil, fmt.Errorf("unknown operator %s", opToken)
+		}
+	default:
+		return nil, fmt.Errorf("expected operator after column %q, got %s", column.Name, opToken)
+	}
+
+	if op == "in" {
+		if _, err := p.expect(tokenLParen, "'(' after 'in'"); err != nil {
+			return nil, err
+		}
+		var specs []*FilterSpec[T]
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			fs, err := newFilterSpec(p.cols, column, comparisonTypeMatch, false, value)
+			if err != nil {
+				return nil, err
+			}
+			specs = append(specs, fs)
+			if p.peek().typ != tokenComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokenRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return func(entry *T) bool {
+			for _, fs := range specs {
+				if fs.Match(entry) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "==":
+		return matchFunc(newFilterSpec(p.cols, column, comparisonTypeMatch, false, value))
+	case "!=":
+		return matchFunc(newFilterSpec(p.cols, column, comparisonTypeMatch, true, value))
+	case "<":
+		return matchFunc(newFilterSpec(p.cols, column, comparisonTypeLt, false, value))
+	case "<=":
+		return matchFunc(newFilterSpec(p.cols, column, comparisonTypeLte, false, value))
+	case ">":
+		return matchFunc(newFilterSpec(p.cols, column, comparisonTypeGt, false, value))
+	case ">=":
+		return matchFunc(newFilterSpec(p.cols, column, comparisonTypeGte, false, value))
+	case "~", "!~":
+		negate := op == "!~"
+		if _, ipNet, err := net.ParseCIDR(value); err == nil {
+			return cidrMatchFunc(column, ipNet, negate)
+		}
+		return matchFunc(newFilterSpec(p.cols, column, comparisonTypeRegex, negate, value))
+	case "contains":
+		return stringMatchFunc(column, value, strings.Contains)
+	case "startswith":
+		return stringMatchFunc(column, value, strings.HasPrefix)
+	case "endswith":
+		return stringMatchFunc(column, value, strings.HasSuffix)
+	}
+	return nil, fmt.Errorf("unknown operator %s", opToken)
+}
+
+func matchFunc[T any](fs *FilterSpec[T], err error) (func(*T) bool, error) {
+	if err != nil {
+		return nil, err
+	}
+	return fs.Match, nil
+}
+
+func stringMatchFunc[T any](column *columns.Column[T], value string, cmp func(string, string) bool) (func(*T) bool, error) {
+	if column.Kind() != reflect.String {
+		return nil, fmt.Errorf("tried to apply string matching on non-string column %q", column.Name)
+	}
+	ff := columns.GetFieldFunc[string, T](column)
+	return func(entry *T) bool {
+		return cmp(ff(entry), value)
+	}, nil
+}
+
+func cidrMatchFunc[T any](column *columns.Column[T], ipNet *net.IPNet, negate bool) (func(*T) bool, error) {
+	if column.Kind() != reflect.String {
+		return nil, fmt.Errorf("tried to apply CIDR matching on non-string column %q", column.Name)
+	}
+	ff := columns.GetFieldFunc[string, T](column)
+	return func(entry *T) bool {
+		ip := net.ParseIP(ff(entry))
+		if ip == nil {
+			return negate
+		}
+		return ipNet.Contains(ip) != negate
+	}, nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+type expressionTestData struct {
+	Comm    string `column:"comm"`
+	Port    uint16 `column:"port"`
+	Dst     string `column:"dst"`
+	Pid     int    `column:"pid"`
+	Success bool   `column:"success"`
+}
+
+func TestExpressions(t *testing.T) {
+	cols, err := columns.NewColumns[expressionTestData]()
+	require.NoError(t, err)
+
+	entries := []*expressionTestData{
+		{Comm: "curl", Port: 80, Dst: "10.0.0.1", Pid: 1, Success: true},
+		{Comm: "curl", Port: 443, Dst: "192.168.0.1", Pid: 2},
+		{Comm: "curl", Port: 8080, Dst: "10.1.2.3", Pid: 3},
+		{Comm: "wget", Port: 443, Dst: "10.0.0.2", Pid: 4, Success: true},
+		{Comm: "bash", Port: 0, Dst: "", Pid: 5},
+	}
+
+	type expressionTest struct {
+		expr        string
+		expectedPid []int
+		expectError bool
+	}
+
+	tests := []expressionTest{
+		{expr: `comm == "curl"`, expectedPid: []int{1, 2, 3}},
+		{expr: `comm != curl`, expectedPid: []int{4, 5}},
+		{expr: `pid > 3`, expectedPid: []int{4, 5}},
+		{expr: `pid>=3 && pid<=4`, expectedPid: []int{3, 4}},
+		{expr: `pid < 2 || pid == 5`, expectedPid: []int{1, 5}},
+		{expr: `port in (80, 443)`, expectedPid: []int{1, 2, 4}},
+		{expr: `!port in (80,443)`, expectedPid: []int{3, 5}},
+		{expr: `dst ~ "10.0.0.0/8"`, expectedPid: []int{1, 3, 4}},
+		{expr: `dst !~ '10.0.0.0/8'`, expectedPid: []int{2, 5}},
+		{expr: `dst ~ "^192\\."`, expectedPid: []int{2}},
+		{expr: `comm == "curl" && (port in (80,443) || dst ~ "10.0.0.0/8")`, expectedPid: []int{1, 2, 3}},
+		{expr: `comm == "curl" && !(port in (80,443) || dst ~ "10.0.0.0/8")`, expectedPid: nil},
+		{expr: `comm contains "ur" || comm startswith w`, expectedPid: []int{1, 2, 3, 4}},
+		{expr: `comm ENDSWITH sh`, expectedPid: []int{5}},
+		{expr: `success == true`, expectedPid: []int{1, 4}},
+		{expr: `success != true && pid < 3`, expectedPid: []int{2}},
+		{expr: `a || b || c`, expectError: true},
+		{expr: `comm ==`, expectError: true},
+		{expr: `(comm == curl`, expectError: true},
+		{expr: `comm == curl)`, expectError: true},
+		{expr: `comm = curl`, expectError: true},
+		{expr: `comm == "curl`, expectError: true},
+		{expr: `pid == abc`, expectError: true},
+		{expr: `pid contains 1`, expectError: true},
+		{expr: `pid ~ "10.0.0.0/8"`, expectError: true},
+		{expr: `comm foo bar`, expectError: true},
+		{expr: `port in ()`, expectError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			expr, err := CompileExpression(cols.GetColumnMap(), test.expr)
+			if test.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var pids []int
+			for _, entry := range entries {
+				if expr.Match(entry) {
+					pids = append(pids, entry.Pid)
+				}
+			}
+			assert.Equal(t, test.expectedPid, pids)
+			assert.False(t, expr.Match(nil))
+		})
+	}
+}
+
+func TestExpressionsInFilterSpecs(t *testing.T) {
+	cols, err := columns.NewColumns[expressionTestData]()
+	require.NoError(t, err)
+
+	entries := []*expressionTestData{
+		{Comm: "curl", Port: 80, Pid: 1},
+		{Comm: "curl", Port: 443, Pid: 2},
+		{Comm: "wget", Port: 443, Pid: 3},
+	}
+
+	specs, err := GetFiltersFromStrings(cols.GetColumnMap(), []string{"comm:curl", "port in (443, 8443)"})
+	require.NoError(t, err)
+	assert.False(t, specs.MatchAll(entries[0]))
+	assert.True(t, specs.MatchAll(entries[1]))
+	assert.False(t, specs.MatchAll(entries[2]))
+
+	out, err := FilterEntries(cols.GetColumnMap(), entries, []string{`comm == wget || pid == 1`})
+	require.NoError(t, err)
+	assert.Equal(t, []*expressionTestData{entries[0], entries[2]}, out)
+}
+
+func TestIsExpression(t *testing.T) {
+	assert.False(t, IsExpression("comm:curl"))
+	assert.False(t, IsExpression("comm:!~^cu"))
+	assert.False(t, IsExpression("comm"))
+	assert.False(t, IsExpression("k8s.namespace:default"))
+	assert.True(t, IsExpression(`comm == "a:b"`))
+	assert.True(t, IsExpression(`(comm == curl)`))
+	assert.True(t, IsExpression(`!comm == curl`))
+}
+
+func TestSplitFilters(t *testing.T) {
+	assert.Equal(t, []string{"a:1", "b:2"}, SplitFilters("a:1,b:2"))
+	assert.Equal(t, []string{"port in (80,443)", "comm:curl"}, SplitFilters("port in (80,443),comm:curl"))
+	assert.Equal(t, []string{`comm == "a,b"`, `comm == 'c,\'d'`}, SplitFilters(`comm == "a,b",comm == 'c,\'d'`))
+	assert.Equal(t, []string{""}, SplitFilters(""))
+}
@@ -80,6 +80,12 @@ func getValueFromFilterSpec[T any](fs *FilterSpec[T], column *columns.Column[T])
 		value = reflect.ValueOf(number).Convert(column.Type())
 	case reflect.String:
 		value = reflect.ValueOf(fs.value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(fs.value)
+		if err != nil {
+			return value, fmt.Errorf("tried to compare %q to bool column %q", fs.value, column.Name)
+		}
+		value = reflect.ValueOf(b).Convert(column.Type())
 	default:
 		return reflect.Value{}, fmt.Errorf("tried to match %q on unsupported column %q", fs.value, column.Name)
 	}
@@ -87,8 +93,21 @@ func getValueFromFilterSpec[T any](fs *FilterSpec[T], column *columns.Column[T])
 }
 
 // GetFilterFromString prepares a filter that has a Match() function that can be called on
-// entries of type *T
+// entries of type *T. filter can either be a single rule in the form "columnName:rule" or an expression
+// (see CompileExpression).
 func GetFilterFromString[T any](cols columns.ColumnMap[T], filter string) (*FilterSpec[T], error) {
+	if IsExpression(filter) {
+		expr, err := CompileExpression(cols, filter)
+		if err != nil {
+			return nil, err
+		}
+		return &FilterSpec[T]{
+			value:       filter,
+			compareFunc: expr.Match,
+			cols:        cols,
+		}, nil
+	}
+
 	filterInfo := strings.SplitN(filter, ":", 2)
 	if len(filterInfo) == 1 {
 		// special case: only a column means we match with an empty string
@@ -101,50 +120,59 @@ func GetFilterFromString[T any](cols columns.ColumnMap[T], filter string) (*Filt
 		return nil, fmt.Errorf("applying filter: column %q not found", filterInfo[0])
 	}
 
-	fs := &FilterSpec[T]{
-		cols:   cols,
-		column: column,
-	}
-
 	filterRule := filterInfo[1]
 
-	fs.value = filterRule
-
+	negate := false
 	if strings.HasPrefix(filterRule, "!") {
-		fs.negate = true
+		negate = true
 		filterRule = filterRule[1:]
-		fs.value = filterRule
 	}
 
+	ct := comparisonTypeMatch
 	if strings.HasPrefix(filterRule, "~") {
-		fs.comparisonType = comparisonTypeRegex
+		ct = comparisonTypeRegex
 		filterRule = strings.TrimPrefix(filterRule, "~")
-		fs.value = filterRule
-		re, err := regexp.Compile(fs.value)
-		if err != nil {
-			return nil, fmt.Errorf("compiling regular expression %q: %w", fs.value, err)
-		}
-		fs.regex = re
 	} else if strings.HasPrefix(filterRule, ">=") {
-		fs.comparisonType = comparisonTypeGte
+		ct = comparisonTypeGte
 		filterRule = strings.TrimPrefix(filterRule, ">=")
-		fs.value = filterRule
 	} else if strings.HasPrefix(filterRule, ">") {
-		fs.comparisonType = comparisonTypeGt
+		ct = comparisonTypeGt
 		filterRule = strings.TrimPrefix(filterRule, ">")
-		fs.value = filterRule
 	} else if strings.HasPrefix(filterRule, "<=") {
-		fs.comparisonType = comparisonTypeLte
+		ct = comparisonTypeLte
 		filterRule = strings.TrimPrefix(filterRule, "<=")
-		fs.value = filterRule
 	} else if strings.HasPrefix(filterRule, "<") {
-		fs.comparisonType = comparisonTypeLt
+		ct = comparisonTypeLt
 		filterRule = strings.TrimPrefix(filterRule, "<")
-		fs.value = filterRule
 	}
 
-	if fs.comparisonType == comparisonTypeRegex && column.Kind() != reflect.String {
-		return nil, fmt.Errorf("tried to apply regular expression on non-string column %q", fs.column.Name)
+	return newFilterSpec(cols, column, ct, negate, filterRule)
+}
+
+func newFilterSpec[T any](
+	cols columns.ColumnMap[T],
+	column *columns.Column[T],
+	ct comparisonType,
+	negate bool,
+	filterValue string,
+) (*FilterSpec[T], error) {
+	fs := &FilterSpec[T]{
+		cols:           cols,
+		column:         column,
+		comparisonType: ct,
+		negate:         negate,
+		value:          filterValue,
+	}
+
+	if fs.comparisonType == comparisonTypeRegex {
+		if column.Kind() != reflect.String {
+			return nil, fmt.Errorf("tried to apply regular expression on non-string column %q", fs.column.Name)
+		}
+		re, err := regexp.Compile(fs.value)
+		if err != nil {
+			return nil, fmt.Errorf("compiling regular expression %q: %w", fs.value, err)
+		}
+		fs.regex = re
 	}
 
 	// We precalculate value to be of a comparable type to column.kind when comparisonType is not comparisonTypeRegex
@@ -222,7 +250,7 @@ func (fs *FilterSpec[T]) getComparisonFunc() func(*T) bool {
 		if fs.comparisonType == comparisonTypeMatch {
 			ff := columns.GetFieldFunc[bool, T](fs.column)
 			return func(entry *T) bool {
-				return ff(entry) == fs.refValue.(bool)
+				return ff(entry) == fs.refValue.(bool) != fs.negate
 			}
 		}
 		fallthrough
@@ -291,6 +319,16 @@ func (fs *FilterSpec[T]) Match(entry *T) bool {
 	return fs.compareFunc(entry)
 }
 
+// Equality returns the (lowercase) column name and the value, if the FilterSpec is a simple, non-negated rule in
+// the form "columnName:value" - meaning only entries holding exactly that value in the column can match. ok will be
+// false for all other rules and for expressions.
+func (fs *FilterSpec[T]) Equality() (columnName string, value string, ok bool) {
+	if fs.column == nil || fs.negate || fs.comparisonType != comparisonTypeMatch {
+		return "", "", false
+	}
+	return strings.ToLower(fs.column.Name), fs.value, true
+}
+
 // FilterEntries will return the elements of entries that match all given filters.
 func FilterEntries[T any](cols columns.ColumnMap[T], entries []*T, filters []string) ([]*T, error) {
 	if entries == nil {
@@ -332,3 +332,41 @@ func TestFilters(t *testing.T) {
 		assert.Equal(t, out[0].Int, 1)
 	})
 }
+
+func TestFilterEquality(t *testing.T) {
+	type testData struct {
+		Pid  int    `column:"pid"`
+		Comm string `column:"Comm"`
+	}
+
+	cols, err := columns.NewColumns[testData]()
+	require.NoError(t, err)
+	cmap := cols.GetColumnMap()
+
+	type equalityTest struct {
+		filterString string
+		columnName   string
+		value        string
+		ok           bool
+	}
+
+	tests := []equalityTest{
+		{filterString: "pid:123", columnName: "pid", value: "123", ok: true},
+		{filterString: "COMM:curl", columnName: "comm", value: "curl", ok: true},
+		{filterString: "pid:!123"},
+		{filterString: "pid:>=123"},
+		{filterString: "comm:~^cu"},
+		{filterString: "pid == 123"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filterString, func(t *testing.T) {
+			fs, err := GetFilterFromString(cmap, test.filterString)
+			require.NoError(t, err)
+			columnName, value, ok := fs.Equality()
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.columnName, columnName)
+			assert.Equal(t, test.value, value)
+		})
+	}
+}
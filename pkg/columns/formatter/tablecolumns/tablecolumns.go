@@ -0,0 +1,237 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tablecolumns helps to output structs (and events of structs) using metadata from a `Columns` instance as
+// CSV, TSV or Markdown tables. Contrary to textcolumns, values are never shortened or padded; instead they are quoted
+// or escaped as required by the format, so the output can be processed by other tools.
+package tablecolumns
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+type Column[T any] struct {
+	col       *columns.Column[T]
+	formatter func(*T) string
+}
+
+type TableColumnsFormatter[T any] struct {
+	options     *Options
+	columns     map[string]*Column[T]
+	showColumns []*Column[T]
+}
+
+// NewFormatter returns a TableColumnsFormatter that will turn entries of type T into rows of a CSV, TSV or Markdown
+// table
+func NewFormatter[T any](columns columns.ColumnMap[T], options ...Option) *TableColumnsFormatter[T] {
+	opts := DefaultOptions()
+	for _, o := range options {
+		o(opts)
+	}
+
+	formatterColumnMap := make(map[string]*Column[T])
+	for columnName, column := range columns {
+		formatterColumnMap[columnName] = &Column[T]{
+			col:       column,
+			formatter: getFormatter(column),
+		}
+	}
+
+	tf := &TableColumnsFormatter[T]{
+		options: opts,
+		columns: formatterColumnMap,
+	}
+
+	tf.SetShowColumns(opts.DefaultColumns)
+
+	return tf
+}
+
+// getFormatter returns a function that returns the value of column as string. Slices (like the arguments of a
+// process) are turned into space separated lists, quoting the elements that contain spaces or quotes.
+func getFormatter[T any](column *columns.Column[T]) func(*T) string {
+	if column.IsVirtual() || column.RawType() == nil || column.RawType().Kind() != reflect.Slice ||
+		column.RawType().Elem().Kind() == reflect.Uint8 {
+		return columns.GetFieldAsStringExt[T](column, 'f', column.Precision)
+	}
+	return func(entry *T) string {
+		v := column.GetRaw(entry)
+		elems := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elems = append(elems, quoteIfNeeded(fmt.Sprint(v.Index(i).Interface())))
+		}
+		return strings.Join(elems, " ")
+	}
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '"' || r == '\''
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// SetShowDefaultColumns resets the shown columns to those defined by default
+func (tf *TableColumnsFormatter[T]) SetShowDefaultColumns() {
+	if tf.options.DefaultColumns != nil {
+		tf.SetShowColumns(tf.options.DefaultColumns)
+		return
+	}
+	newColumns := make([]*Column[T], 0)
+	for _, c := range tf.columns {
+		if !c.col.Visible {
+			continue
+		}
+		newColumns = append(newColumns, c)
+	}
+
+	// Sort using the default sort order
+	sort.Slice(newColumns, func(i, j int) bool {
+		return newColumns[i].col.Order < newColumns[j].col.Order
+	})
+
+	tf.showColumns = newColumns
+}
+
+// SetShowColumns takes a list of column names that will be displayed when using the output methods
+// Returns an error if any of the columns is not available.
+func (tf *TableColumnsFormatter[T]) SetShowColumns(columns []string) error {
+	if columns == nil {
+		tf.SetShowDefaultColumns()
+		return nil
+	}
+
+	newColumns := make([]*Column[T], 0)
+	for _, c := range columns {
+		column, ok := tf.columns[strings.ToLower(c)]
+		if !ok {
+			return fmt.Errorf("column %q is invalid", strings.ToLower(c))
+		}
+
+		newColumns = append(newColumns, column)
+	}
+	tf.showColumns = newColumns
+
+	return nil
+}
+
+// FormatHeader returns the header of the table; in case of Markdown, this includes the line separating the header
+// from the rows
+func (tf *TableColumnsFormatter[T]) FormatHeader() string {
+	names := make([]string, 0, len(tf.showColumns))
+	for _, column := range tf.showColumns {
+		names = append(names, column.col.Name)
+	}
+
+	if tf.options.Format != FormatMarkdown {
+		return tf.formatRecord(names)
+	}
+
+	for i, name := range names {
+		names[i] = strings.ToUpper(name)
+	}
+	separators := make([]string, 0, len(tf.showColumns))
+	for _, column := range tf.showColumns {
+		separator := "---"
+		if column.col.Alignment == columns.AlignRight {
+			separator += ":"
+		}
+		separators = append(separators, separator)
+	}
+	return tf.formatRecord(names) + "\n" + formatMarkdownRow(separators)
+}
+
+// FormatEntry returns a single row of the table
+func (tf *TableColumnsFormatter[T]) FormatEntry(entry *T) string {
+	if entry == nil {
+		return ""
+	}
+
+	values := make([]string, 0, len(tf.showColumns))
+	for _, column := range tf.showColumns {
+		values = append(values, column.formatter(entry))
+	}
+	return tf.formatRecord(values)
+}
+
+func (tf *TableColumnsFormatter[T]) formatRecord(values []string) string {
+	switch tf.options.Format {
+	case FormatMarkdown:
+		for i, value := range values {
+			values[i] = escapeMarkdown(value)
+		}
+		return formatMarkdownRow(values)
+	case FormatTSV:
+		return formatCSVRecord(values, '\t')
+	default:
+		return formatCSVRecord(values, ',')
+	}
+}
+
+func formatCSVRecord(values []string, separator rune) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = separator
+	// Writing to a bytes.Buffer can't fail
+	w.Write(values)
+	w.Flush()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func formatMarkdownRow(values []string) string {
+	return "| " + strings.Join(values, " | ") + " |"
+}
+
+var markdownReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+func escapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// FormatTable returns the header and the given entries as table
+func (tf *TableColumnsFormatter[T]) FormatTable(entries []*T) string {
+	buf := bytes.NewBuffer(nil)
+	_ = tf.WriteTable(buf, entries)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// WriteTable writes the header and the given entries as table to writer
+func (tf *TableColumnsFormatter[T]) WriteTable(writer io.Writer, entries []*T) error {
+	if _, err := io.WriteString(writer, tf.FormatHeader()+"\n"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := io.WriteString(writer, tf.FormatEntry(entry)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
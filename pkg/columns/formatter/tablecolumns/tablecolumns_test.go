@@ -0,0 +1,83 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tablecolumns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+type testStruct struct {
+	Name    string   `column:"name,width:10"`
+	Age     uint     `column:"age,width:4,align:right,fixed"`
+	Size    float32  `column:"size,width:6,precision:2,align:right"`
+	Args    []string `column:"args,width:20"`
+	Comment string   `column:"comment,width:20"`
+}
+
+var testEntries = []*testStruct{
+	{"Alice", 32, 1.74, []string{"sh", "-c", "echo hello"}, "likes, commas"},
+	{"Bob", 26, 1.73, nil, `says "hi"`},
+	{"Eve", 99, 5.12, []string{""}, "a|b\nc"},
+}
+
+var testColumns = columns.MustCreateColumns[testStruct]().GetColumnMap()
+
+func TestTableColumnsFormatter_CSV(t *testing.T) {
+	formatter := NewFormatter(testColumns)
+
+	assert.Equal(t, strings.Join([]string{
+		"name,age,size,args,comment",
+		`Alice,32,1.74,"sh -c ""echo hello""","likes, commas"`,
+		`Bob,26,1.73,,"says ""hi"""`,
+		`Eve,99,5.12,"""""","a|b` + "\n" + `c"`,
+	}, "\n"), formatter.FormatTable(testEntries))
+}
+
+func TestTableColumnsFormatter_TSV(t *testing.T) {
+	formatter := NewFormatter(testColumns, WithFormat(FormatTSV), WithDefaultColumns([]string{"name", "comment"}))
+
+	assert.Equal(t, "name\tcomment", formatter.FormatHeader())
+	assert.Equal(t, "Alice\tlikes, commas", formatter.FormatEntry(testEntries[0]))
+	assert.Equal(t, "Bob\t\"says \"\"hi\"\"\"", formatter.FormatEntry(testEntries[1]))
+}
+
+func TestTableColumnsFormatter_Markdown(t *testing.T) {
+	formatter := NewFormatter(testColumns, WithFormat(FormatMarkdown))
+
+	assert.Equal(t, strings.Join([]string{
+		"| NAME | AGE | SIZE | ARGS | COMMENT |",
+		"| --- | ---: | ---: | --- | --- |",
+		`| Alice | 32 | 1.74 | sh -c "echo hello" | likes, commas |`,
+		`| Bob | 26 | 1.73 |  | says "hi" |`,
+		`| Eve | 99 | 5.12 | "" | a\|b<br>c |`,
+	}, "\n"), formatter.FormatTable(testEntries))
+}
+
+func TestTableColumnsFormatter_SetShowColumns(t *testing.T) {
+	formatter := NewFormatter(testColumns)
+
+	require.NoError(t, formatter.SetShowColumns([]string{"Age", "name"}))
+	assert.Equal(t, "age,name", formatter.FormatHeader())
+	assert.Equal(t, "32,Alice", formatter.FormatEntry(testEntries[0]))
+	assert.Equal(t, "", formatter.FormatEntry(nil))
+
+	require.Error(t, formatter.SetShowColumns([]string{"unknown"}))
+}
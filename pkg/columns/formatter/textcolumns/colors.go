@@ -0,0 +1,113 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textcolumns
+
+import (
+	"hash/fnv"
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/filter"
+)
+
+const colorReset = "\x1b[0m"
+
+// valuePalette holds the colors used for ColorColumns; red is left out so that it stands out for rules
+var valuePalette = []columns.Color{
+	columns.ColorGreen, columns.ColorYellow, columns.ColorBlue, columns.ColorMagenta, columns.ColorCyan,
+	columns.ColorBrightGreen, columns.ColorBrightYellow, columns.ColorBrightBlue, columns.ColorBrightMagenta, columns.ColorBrightCyan,
+}
+
+type colorRule[T any] struct {
+	filter *filter.FilterSpec[T]
+	color  columns.Color
+}
+
+func wrapColor(c columns.Color, s string) string {
+	if c == columns.ColorNone {
+		return s
+	}
+	return "\x1b[" + string(c) + "m" + s + colorReset
+}
+
+// colorForValue returns a color of valuePalette for value; the same value always gets the same color
+func colorForValue(value string) columns.Color {
+	if value == "" {
+		return columns.ColorNone
+	}
+	h := fnv.New32a()
+	h.Write([]byte(value))
+	return valuePalette[h.Sum32()%uint32(len(valuePalette))]
+}
+
+// buildColorRules compiles the color rules of the options; rules referencing unknown columns (e.g. kubernetes
+// columns when running without kubernetes) are skipped
+func (tf *TextColumnsFormatter[T]) buildColorRules(cols columns.ColumnMap[T]) {
+	if !tf.options.Colors {
+		return
+	}
+	tf.cellRules = make(map[string][]*colorRule[T])
+	for _, rule := range tf.options.ColorRules {
+		filterSpec, err := filter.GetFilterFromString(cols, rule.Filter)
+		if err != nil {
+			continue
+		}
+		cr := &colorRule[T]{filter: filterSpec, color: rule.Color}
+		if rule.Column == "" {
+			tf.rowRules = append(tf.rowRules, cr)
+			continue
+		}
+		if _, ok := tf.columns[strings.ToLower(rule.Column)]; !ok {
+			continue
+		}
+		tf.cellRules[strings.ToLower(rule.Column)] = append(tf.cellRules[strings.ToLower(rule.Column)], cr)
+	}
+	tf.colorColumns = make(map[string]bool)
+	for _, c := range tf.options.ColorColumns {
+		tf.colorColumns[strings.ToLower(c)] = true
+	}
+}
+
+func matchColor[T any](rules []*colorRule[T], entry *T) columns.Color {
+	for _, rule := range rules {
+		if rule.filter.Match(entry) {
+			return rule.color
+		}
+	}
+	return columns.ColorNone
+}
+
+// cellColor returns the color for the cell of column for entry
+func (tf *TextColumnsFormatter[T]) cellColor(column *Column[T], entry *T) columns.Color {
+	if !tf.options.Colors {
+		return columns.ColorNone
+	}
+	name := strings.ToLower(column.col.Name)
+	if color := matchColor(tf.cellRules[name], entry); color != columns.ColorNone {
+		return color
+	}
+	if tf.colorColumns[name] {
+		return colorForValue(column.value(entry))
+	}
+	return columns.ColorNone
+}
+
+// rowColor returns the color for the whole row of entry
+func (tf *TextColumnsFormatter[T]) rowColor(entry *T) columns.Color {
+	if !tf.options.Colors {
+		return columns.ColorNone
+	}
+	return matchColor(tf.rowRules, entry)
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textcolumns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+type testColorStruct struct {
+	Pod  string `column:"pod,width:5"`
+	Comm string `column:"comm,width:5"`
+	Ret  int    `column:"ret,width:3"`
+}
+
+func TestTextColumnsFormatter_Colors(t *testing.T) {
+	cols := columns.MustCreateColumns[testColorStruct]().GetColumnMap()
+	rules := []columns.ColorRule{
+		{Filter: "ret:!0", Column: "ret", Color: columns.ColorRed},
+		{Filter: "comm:bad", Color: columns.ColorYellow},
+		{Filter: "unknown:foo", Color: columns.ColorRed},
+	}
+	entries := []*testColorStruct{
+		{"a", "cat", 0},
+		{"a", "cat", -2},
+		{"b", "bad", -2},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		formatter := NewFormatter(cols, WithAutoScale(false), WithColorRules(rules), WithColorColumns([]string{"pod"}))
+		assert.Equal(t, "a     cat   -2 ", formatter.FormatEntry(entries[1]))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		formatter := NewFormatter(cols, WithAutoScale(false), WithColors(true), WithColorRules(rules), WithColorColumns([]string{"pod"}))
+		podA := wrapColor(colorForValue("a"), "a    ")
+		assert.Equal(t, podA+" cat   0  ", formatter.FormatEntry(entries[0]))
+		assert.Equal(t, podA+" cat   \x1b[31m-2 \x1b[0m", formatter.FormatEntry(entries[1]))
+		assert.Equal(t, "\x1b[33mb     bad   -2 \x1b[0m", formatter.FormatEntry(entries[2]))
+		assert.Equal(t, "POD   COMM  RET", formatter.FormatHeader())
+	})
+}
+
+func TestColorForValue(t *testing.T) {
+	assert.Equal(t, columns.ColorNone, colorForValue(""))
+	assert.Equal(t, colorForValue("mypod"), colorForValue("mypod"))
+	assert.Contains(t, valuePalette, colorForValue("mypod"))
+}
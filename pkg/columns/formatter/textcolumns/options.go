@@ -14,6 +14,8 @@
 
 package textcolumns
 
+import "github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+
 type HeaderStyle int
 
 const (
@@ -29,14 +31,36 @@ const (
 	DividerNone  = ""
 )
 
+// Units defines how values of columns with a unit should be printed
+type Units string
+
+const (
+	UnitsRaw   Units = "raw"   // UnitsRaw prints values as they are
+	UnitsHuman Units = "human" // UnitsHuman prints values scaled and with their unit, like "1.5 MiB" or "3.2ms"
+)
+
+// TimestampFormat defines how values of timestamp columns should be printed
+type TimestampFormat string
+
+const (
+	TimestampAbsolute TimestampFormat = "absolute" // TimestampAbsolute prints timestamps as date and time
+	TimestampRelative TimestampFormat = "relative" // TimestampRelative prints timestamps relative to the first one
+	TimestampUnix     TimestampFormat = "unix"     // TimestampUnix prints timestamps as seconds since the epoch
+)
+
 type Option func(*Options)
 
 type Options struct {
-	AutoScale      bool        // if enabled, the screen size will be used to scale the widths
-	ColumnDivider  string      // defines the string that should be used as spacer in between columns (default " ")
-	DefaultColumns []string    // defines which columns to show by default; will be set to all visible columns if nil
-	HeaderStyle    HeaderStyle // defines how column headers are decorated (e.g. uppercase/lowercase)
-	RowDivider     string      // defines the (to be repeated) string that should be used below the header
+	AutoScale      bool                // if enabled, the screen size will be used to scale the widths
+	Colors         bool                // if enabled, ColorRules and ColorColumns are used to colorize the output
+	ColorColumns   []string            // defines columns whose cells get a color depending on their value
+	ColorRules     []columns.ColorRule // defines rules to colorize rows or cells of matching entries
+	ColumnDivider  string              // defines the string that should be used as spacer in between columns (default " ")
+	DefaultColumns []string            // defines which columns to show by default; will be set to all visible columns if nil
+	HeaderStyle    HeaderStyle         // defines how column headers are decorated (e.g. uppercase/lowercase)
+	RowDivider     string              // defines the (to be repeated) string that should be used below the header
+	Timestamp      TimestampFormat     // defines how columns with the timestamp unit are printed
+	Units          Units               // defines how columns with a unit (bytes, ns, rate) are printed
 }
 
 func DefaultOptions() *Options {
@@ -46,6 +70,8 @@ func DefaultOptions() *Options {
 		DefaultColumns: nil,
 		HeaderStyle:    HeaderStyleUppercase,
 		RowDivider:     DividerNone,
+		Timestamp:      TimestampAbsolute,
+		Units:          UnitsRaw,
 	}
 }
 
@@ -56,6 +82,28 @@ func WithAutoScale(autosScale bool) Option {
 	}
 }
 
+// WithColors sets whether the output should be colorized using ANSI escape sequences
+func WithColors(colors bool) Option {
+	return func(opts *Options) {
+		opts.Colors = colors
+	}
+}
+
+// WithColorColumns sets the columns whose cells should get a color depending on their value; the same value always
+// gets the same color, which makes it easier to tell apart interleaved events of e.g. different pods
+func WithColorColumns(columns []string) Option {
+	return func(opts *Options) {
+		opts.ColorColumns = columns
+	}
+}
+
+// WithColorRules sets the rules used to colorize rows or cells of entries; the first matching rule wins
+func WithColorRules(rules []columns.ColorRule) Option {
+	return func(opts *Options) {
+		opts.ColorRules = rules
+	}
+}
+
 // WithColumnDivider sets the string that should be used as divider between columns
 func WithColumnDivider(divider string) Option {
 	return func(opts *Options) {
@@ -83,3 +131,17 @@ func WithRowDivider(divider string) Option {
 		opts.RowDivider = divider
 	}
 }
+
+// WithTimestamp sets how columns holding timestamps should be printed
+func WithTimestamp(timestamp TimestampFormat) Option {
+	return func(opts *Options) {
+		opts.Timestamp = timestamp
+	}
+}
+
+// WithUnits sets whether columns with a unit should be printed raw or in a human-readable way
+func WithUnits(units Units) Option {
+	return func(opts *Options) {
+		opts.Units = units
+	}
+}
@@ -49,4 +49,14 @@ func TestOptions(t *testing.T) {
 	if opts.RowDivider != "X" {
 		t.Errorf("Expected RowDivider to be X")
 	}
+
+	WithTimestamp(TimestampRelative)(opts)
+	if opts.Timestamp != TimestampRelative {
+		t.Errorf("Expected Timestamp to be TimestampRelative")
+	}
+
+	WithUnits(UnitsHuman)(opts)
+	if opts.Units != UnitsHuman {
+		t.Errorf("Expected Units to be UnitsHuman")
+	}
 }
@@ -25,7 +25,8 @@ import (
 )
 
 func (tf *TextColumnsFormatter[T]) setFormatter(column *Column[T]) {
-	ff := columns.GetFieldAsStringExt[T](column.col, 'f', column.col.Precision)
+	ff := tf.getValueFormatter(column.col)
+	column.value = ff
 	column.formatter = func(entry *T) string {
 		return tf.buildFixedString(ff(entry), column.calculatedWidth, column.col.EllipsisType, column.col.Alignment)
 	}
@@ -53,14 +54,21 @@ func (tf *TextColumnsFormatter[T]) FormatEntry(entry *T) string {
 		return ""
 	}
 
+	// A row color takes precedence over the colors of single cells
+	rowColor := tf.rowColor(entry)
+
 	var row strings.Builder
 	for i, col := range tf.showColumns {
 		if i > 0 {
 			row.WriteString(tf.options.ColumnDivider)
 		}
-		row.WriteString(col.formatter(entry))
+		cell := col.formatter(entry)
+		if rowColor == columns.ColorNone {
+			cell = wrapColor(tf.cellColor(col, entry), cell)
+		}
+		row.WriteString(cell)
 	}
-	return row.String()
+	return wrapColor(rowColor, row.String())
 }
 
 // FormatHeader returns the formatted header line with all visible column names, separated by ColumnDivider
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync/atomic"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
 )
@@ -27,6 +28,7 @@ type Column[T any] struct {
 	calculatedWidth int
 	treatAsFixed    bool
 	formatter       func(*T) string
+	value           func(*T) string
 }
 
 type TextColumnsFormatter[T any] struct {
@@ -35,6 +37,10 @@ type TextColumnsFormatter[T any] struct {
 	currentMaxWidth int
 	showColumns     []*Column[T]
 	fillString      string
+	timestampBase   atomic.Int64 // first timestamp seen, used for TimestampRelative
+	rowRules        []*colorRule[T]
+	cellRules       map[string][]*colorRule[T]
+	colorColumns    map[string]bool
 }
 
 // NewFormatter returns a TextColumnsFormatter that will turn entries of type T into tables that can be shown
@@ -58,6 +64,8 @@ func NewFormatter[T any](columns columns.ColumnMap[T], options ...Option) *TextC
 		columns: formatterColumnMap,
 	}
 
+	tf.buildColorRules(columns)
+
 	for _, column := range tf.columns {
 		tf.setFormatter(column)
 	}
@@ -0,0 +1,139 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textcolumns
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+var (
+	bytesPrefixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	ratePrefixes  = []string{"", "k", "M", "G", "T", "P", "E"}
+)
+
+// getValueFormatter returns a func that returns the value of the given column as string, taking the unit of the
+// column into account
+func (tf *TextColumnsFormatter[T]) getValueFormatter(col *columns.Column[T]) func(*T) string {
+	if col.IsVirtual() {
+		return columns.GetFieldAsStringExt[T](col, 'f', col.Precision)
+	}
+
+	switch col.Unit {
+	case columns.UnitTimestamp:
+		ff := columns.GetFieldAsNumberFuncExt[int64, T](col, true)
+		switch tf.options.Timestamp {
+		case TimestampUnix:
+			return func(entry *T) string {
+				return formatUnixTimestamp(ff(entry))
+			}
+		case TimestampRelative:
+			return func(entry *T) string {
+				ts := ff(entry)
+				if ts == 0 {
+					return ""
+				}
+				tf.timestampBase.CompareAndSwap(0, ts)
+				return formatRelativeTimestamp(ts - tf.timestampBase.Load())
+			}
+		}
+	case columns.UnitBytes:
+		if tf.options.Units == UnitsHuman {
+			ff := columns.GetFieldAsNumberFuncExt[float64, T](col, true)
+			return func(entry *T) string {
+				return formatBytes(ff(entry))
+			}
+		}
+	case columns.UnitNanoseconds:
+		if tf.options.Units == UnitsHuman {
+			ff := columns.GetFieldAsNumberFuncExt[float64, T](col, true)
+			return func(entry *T) string {
+				return formatNanoseconds(ff(entry))
+			}
+		}
+	case columns.UnitRate:
+		if tf.options.Units == UnitsHuman {
+			ff := columns.GetFieldAsNumberFuncExt[float64, T](col, true)
+			return func(entry *T) string {
+				return formatRate(ff(entry))
+			}
+		}
+	}
+	return columns.GetFieldAsStringExt[T](col, 'f', col.Precision)
+}
+
+// scale divides v by base until it's smaller than base and returns the result together with the number of
+// divisions, limited to maxExp
+func scale(v float64, base float64, maxExp int) (float64, int) {
+	exp := 0
+	for math.Abs(v) >= base && exp < maxExp {
+		v /= base
+		exp++
+	}
+	return v, exp
+}
+
+// formatBytes returns v using IEC prefixes, like "1.5 MiB"
+func formatBytes(v float64) string {
+	v, exp := scale(v, 1024, len(bytesPrefixes)-1)
+	if exp == 0 {
+		return strconv.FormatFloat(v, 'f', -1, 64) + " " + bytesPrefixes[0]
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + " " + bytesPrefixes[exp]
+}
+
+// formatNanoseconds returns the duration v (in nanoseconds) using the most fitting unit, like "3.2ms"
+func formatNanoseconds(v float64) string {
+	abs := math.Abs(v)
+	switch {
+	case abs < float64(time.Microsecond):
+		return strconv.FormatFloat(v, 'f', -1, 64) + "ns"
+	case abs < float64(time.Millisecond):
+		return strconv.FormatFloat(v/float64(time.Microsecond), 'f', 1, 64) + "µs"
+	case abs < float64(time.Second):
+		return strconv.FormatFloat(v/float64(time.Millisecond), 'f', 1, 64) + "ms"
+	case abs < float64(time.Minute):
+		return strconv.FormatFloat(v/float64(time.Second), 'f', 2, 64) + "s"
+	}
+	return time.Duration(v).Round(time.Second).String()
+}
+
+// formatRate returns the rate v (in events per second) using SI prefixes, like "1.2k/s"
+func formatRate(v float64) string {
+	v, exp := scale(v, 1000, len(ratePrefixes)-1)
+	if exp == 0 && v == math.Trunc(v) {
+		return strconv.FormatFloat(v, 'f', -1, 64) + "/s"
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64) + ratePrefixes[exp] + "/s"
+}
+
+// formatUnixTimestamp returns the timestamp ts (in nanoseconds since the epoch) as seconds since the epoch
+func formatUnixTimestamp(ts int64) string {
+	return fmt.Sprintf("%d.%09d", ts/int64(time.Second), ts%int64(time.Second))
+}
+
+// formatRelativeTimestamp returns the offset d (in nanoseconds) as seconds with microsecond precision
+func formatRelativeTimestamp(d int64) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	return fmt.Sprintf("%s%d.%06ds", sign, d/int64(time.Second), (d%int64(time.Second))/int64(time.Microsecond))
+}
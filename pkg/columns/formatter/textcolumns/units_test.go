@@ -0,0 +1,98 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package textcolumns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+type testUnitsStruct struct {
+	Timestamp int64   `column:"timestamp,width:30,unit:timestamp"`
+	Bytes     uint64  `column:"bytes,width:12,align:right,unit:bytes"`
+	Latency   int64   `column:"latency,width:10,align:right,unit:ns"`
+	Rate      float64 `column:"rate,width:10,align:right,unit:rate"`
+}
+
+func TestUnitFormatters(t *testing.T) {
+	bytes := []struct {
+		in       float64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024 * 1024, "5.0 GiB"},
+	}
+	for _, tc := range bytes {
+		assert.Equal(t, tc.expected, formatBytes(tc.in))
+	}
+
+	durations := []struct {
+		in       float64
+		expected string
+	}{
+		{0, "0ns"},
+		{999, "999ns"},
+		{1500, "1.5µs"},
+		{3_200_000, "3.2ms"},
+		{1_500_000_000, "1.50s"},
+		{125_400_000_000, "2m5s"},
+	}
+	for _, tc := range durations {
+		assert.Equal(t, tc.expected, formatNanoseconds(tc.in))
+	}
+
+	rates := []struct {
+		in       float64
+		expected string
+	}{
+		{0, "0/s"},
+		{12, "12/s"},
+		{12.5, "12.5/s"},
+		{1200, "1.2k/s"},
+		{3_400_000, "3.4M/s"},
+	}
+	for _, tc := range rates {
+		assert.Equal(t, tc.expected, formatRate(tc.in))
+	}
+
+	assert.Equal(t, "1690000000.000000123", formatUnixTimestamp(1690000000000000123))
+	assert.Equal(t, "+1.500000s", formatRelativeTimestamp(1_500_000_000))
+	assert.Equal(t, "-0.000250s", formatRelativeTimestamp(-250_000))
+}
+
+func TestTextColumnsFormatter_Units(t *testing.T) {
+	cols := columns.MustCreateColumns[testUnitsStruct]().GetColumnMap()
+	entries := []*testUnitsStruct{
+		{1690000000000000000, 2048, 1500, 1200},
+		{1690000001500000000, 100, 3_200_000, 5},
+	}
+
+	t.Run("raw", func(t *testing.T) {
+		formatter := NewFormatter(cols, WithAutoScale(false), WithTimestamp(TimestampUnix))
+		assert.Equal(t, "1690000000.000000000                   2048       1500    1200.00", formatter.FormatEntry(entries[0]))
+	})
+
+	t.Run("human", func(t *testing.T) {
+		formatter := NewFormatter(cols, WithAutoScale(false), WithUnits(UnitsHuman), WithTimestamp(TimestampRelative))
+		assert.Equal(t, "+0.000000s                          2.0 KiB      1.5µs     1.2k/s", formatter.FormatEntry(entries[0]))
+		assert.Equal(t, "+1.500000s                            100 B      3.2ms        5/s", formatter.FormatEntry(entries[1]))
+	})
+}
@@ -14,7 +14,10 @@
 
 package columns
 
-import "strings"
+import (
+	"reflect"
+	"strings"
+)
 
 // ToLowerStrings transforms the elements of an array of strings into lowercase.
 func ToLowerStrings(in []string) []string {
@@ -23,3 +26,13 @@ func ToLowerStrings(in []string) []string {
 	}
 	return in
 }
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
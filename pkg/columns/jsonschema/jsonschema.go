@@ -0,0 +1,237 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonschema generates JSON Schemas describing the JSON representation of events. Properties backed by a
+// column are annotated with the column's name, description and unit, so that consumers of gadget output can be
+// validated and generated from the schema.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+// Draft is the JSON Schema version used for generated schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema holds the subset of JSON Schema used to describe events
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 Type               `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+
+	// Column is the name of the column that shows this property
+	Column string `json:"x-column,omitempty"`
+
+	// Unit is the unit of the column that shows this property
+	Unit columns.Unit `json:"x-unit,omitempty"`
+}
+
+// Type holds the allowed JSON types of a value; it's marshaled as a single string if there's only one
+type Type []string
+
+func (t Type) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Type{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type generator struct {
+	// columns maps the field index path of columns (joined by ".") to the column
+	columns map[string]*columns.Attributes
+
+	// visiting holds the types that are currently being generated to break recursion
+	visiting map[reflect.Type]bool
+}
+
+// ForColumns returns a JSON Schema for type T as it's marshaled using encoding/json. Properties that are backed by
+// one of the given columns are annotated accordingly.
+func ForColumns[T any](columnMap columns.ColumnMap[T], title string) *Schema {
+	g := &generator{
+		columns:  make(map[string]*columns.Attributes),
+		visiting: make(map[reflect.Type]bool),
+	}
+	for _, column := range columnMap {
+		if index := column.FieldIndex(); index != nil {
+			g.columns[indexKey(index)] = column.GetAttributes()
+		}
+	}
+
+	schema := g.schemaForType(reflect.TypeOf((*T)(nil)).Elem(), nil)
+	schema.Schema = Draft
+	schema.Title = title
+	return schema
+}
+
+func indexKey(index []int) string {
+	var sb strings.Builder
+	for i, idx := range index {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.Itoa(idx))
+	}
+	return sb.String()
+}
+
+func (g *generator) schemaForType(t reflect.Type, index []int) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := g.schemaForType(t.Elem(), index)
+		return nullable(schema)
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: Type{"string"}, Format: "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// Custom marshalers can produce anything
+		return &Schema{}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: Type{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Type{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Type{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Type{"number"}}
+	case reflect.String:
+		return &Schema{Type: Type{"string"}}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes []byte as base64 string
+			return nullable(&Schema{Type: Type{"string"}, Format: "byte"})
+		}
+		return nullable(&Schema{Type: Type{"array"}, Items: g.schemaForType(t.Elem(), nil)})
+	case reflect.Array:
+		return &Schema{Type: Type{"array"}, Items: g.schemaForType(t.Elem(), nil)}
+	case reflect.Map:
+		return nullable(&Schema{Type: Type{"object"}, AdditionalProperties: g.schemaForType(t.Elem(), nil)})
+	case reflect.Struct:
+		if g.visiting[t] {
+			return &Schema{Type: Type{"object"}}
+		}
+		g.visiting[t] = true
+		defer delete(g.visiting, t)
+
+		schema := &Schema{Type: Type{"object"}, Properties: make(map[string]*Schema)}
+		g.addFields(schema, t, index, false)
+		return schema
+	}
+
+	// Interfaces and everything else can't be described
+	return &Schema{}
+}
+
+// nullable allows null in addition to the types of schema; encoding/json marshals nil pointers, slices and maps as
+// null
+func nullable(schema *Schema) *Schema {
+	if len(schema.Type) == 0 {
+		return schema
+	}
+	for _, t := range schema.Type {
+		if t == "null" {
+			return schema
+		}
+	}
+	schema.Type = append(schema.Type, "null")
+	return schema
+}
+
+// addFields adds the properties for the fields of struct t to schema following the rules of encoding/json:
+// embedded structs without a JSON name get inlined, and their fields don't override fields of the outer struct
+func (g *generator) addFields(schema *Schema, t reflect.Type, index []int, inlined bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(schema, ft, fieldIndex, true)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if _, ok := schema.Properties[name]; ok && inlined {
+			continue
+		}
+
+		property := g.schemaForType(f.Type, fieldIndex)
+		if attrs, ok := g.columns[indexKey(fieldIndex)]; ok {
+			property.Column = attrs.Name
+			property.Description = attrs.Description
+			property.Unit = attrs.Unit
+		}
+		schema.Properties[name] = property
+
+		if !strings.Contains(opts, "omitempty") && !contains(schema.Required, name) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, entry := range list {
+		if entry == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+)
+
+type testCommon struct {
+	Node string `json:"node,omitempty" column:"node" columnDesc:"Node the event was captured on"`
+	Comm string `json:"comm" column:"comm"`
+}
+
+type testNested struct {
+	Port uint16 `json:"port" column:"port"`
+}
+
+type testEvent struct {
+	testCommon
+
+	Comm      string            `json:"command" column:"cmd"`
+	Bytes     uint64            `json:"bytes" column:"bytes,unit:bytes"`
+	Latency   float64           `json:"latency"`
+	Args      []string          `json:"args,omitempty"`
+	Labels    map[string]string `json:"labels"`
+	Dst       testNested        `json:"dst" column:"dst"`
+	Parent    *testEvent        `json:"parent,omitempty" column:"parent,noembed"`
+	Timestamp time.Time         `json:"timestamp"`
+	Ignored   string            `json:"-" column:"ignored"`
+	internal  int
+}
+
+func TestForColumns(t *testing.T) {
+	cols := columns.MustCreateColumns[testEvent]()
+	schema := ForColumns(cols.ColumnMap, "test")
+
+	assert.Equal(t, Draft, schema.Schema)
+	assert.Equal(t, "test", schema.Title)
+	assert.Equal(t, Type{"object"}, schema.Type)
+	assert.ElementsMatch(t, []string{"comm", "command", "bytes", "latency", "labels", "dst", "timestamp"}, schema.Required)
+	assert.NotContains(t, schema.Properties, "Ignored")
+	assert.NotContains(t, schema.Properties, "internal")
+
+	// Fields of embedded structs are inlined
+	require.Contains(t, schema.Properties, "node")
+	assert.Equal(t, "node", schema.Properties["node"].Column)
+	assert.Equal(t, "Node the event was captured on", schema.Properties["node"].Description)
+	require.Contains(t, schema.Properties, "comm")
+	assert.Equal(t, "comm", schema.Properties["comm"].Column)
+
+	require.Contains(t, schema.Properties, "command")
+	assert.Equal(t, "cmd", schema.Properties["command"].Column)
+
+	bytes := schema.Properties["bytes"]
+	assert.Equal(t, Type{"integer"}, bytes.Type)
+	assert.Equal(t, columns.UnitBytes, bytes.Unit)
+
+	assert.Equal(t, Type{"number"}, schema.Properties["latency"].Type)
+	assert.Empty(t, schema.Properties["latency"].Column)
+
+	args := schema.Properties["args"]
+	assert.Equal(t, Type{"array", "null"}, args.Type)
+	assert.Equal(t, Type{"string"}, args.Items.Type)
+
+	labels := schema.Properties["labels"]
+	assert.Equal(t, Type{"object", "null"}, labels.Type)
+	assert.Equal(t, Type{"string"}, labels.AdditionalProperties.Type)
+
+	// Nested structs keep their column annotations
+	dst := schema.Properties["dst"]
+	assert.Equal(t, Type{"object"}, dst.Type)
+	require.Contains(t, dst.Properties, "port")
+	assert.Equal(t, "dst.port", dst.Properties["port"].Column)
+
+	// Recursive types are cut off
+	parent := schema.Properties["parent"]
+	assert.Equal(t, Type{"object", "null"}, parent.Type)
+	assert.Empty(t, parent.Properties)
+
+	assert.Equal(t, "date-time", schema.Properties["timestamp"].Format)
+}
+
+func TestTypeJSON(t *testing.T) {
+	data, err := json.Marshal(&Schema{Type: Type{"string"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"string"}`, string(data))
+
+	data, err = json.Marshal(&Schema{Type: Type{"array", "null"}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":["array","null"]}`, string(data))
+
+	var schema Schema
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"integer","properties":{"a":{"type":["string","null"]}}}`), &schema))
+	assert.Equal(t, Type{"integer"}, schema.Type)
+	assert.Equal(t, Type{"string", "null"}, schema.Properties["a"].Type)
+}
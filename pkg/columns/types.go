@@ -38,6 +38,18 @@ const (
 	OrderDesc Order = false // OrderDesc sorts in descending alphanumerical order
 )
 
+// Unit defines the unit of the values of a numeric column; it can be used by formatters to print values in a
+// human-readable way
+type Unit string
+
+const (
+	UnitNone        Unit = ""          // UnitNone means that the column has no specific unit
+	UnitBytes       Unit = "bytes"     // UnitBytes is used for sizes in bytes
+	UnitNanoseconds Unit = "ns"        // UnitNanoseconds is used for durations in nanoseconds
+	UnitRate        Unit = "rate"      // UnitRate is used for events per second
+	UnitTimestamp   Unit = "timestamp" // UnitTimestamp is used for timestamps in nanoseconds since the epoch
+)
+
 type ColumnMatcher interface {
 	HasTag(string) bool
 	HasNoTags() bool
@@ -51,3 +63,29 @@ type ColumnInterface[T any] interface {
 	GetOrderedColumns(filters ...ColumnFilter) []*Column[T]
 	GetColumnNames(filters ...ColumnFilter) []string
 }
+
+// Color is an ANSI SGR color code used by formatters supporting colors
+type Color string
+
+const (
+	ColorNone          Color = ""
+	ColorRed           Color = "31"
+	ColorGreen         Color = "32"
+	ColorYellow        Color = "33"
+	ColorBlue          Color = "34"
+	ColorMagenta       Color = "35"
+	ColorCyan          Color = "36"
+	ColorBrightGreen   Color = "92"
+	ColorBrightYellow  Color = "93"
+	ColorBrightBlue    Color = "94"
+	ColorBrightMagenta Color = "95"
+	ColorBrightCyan    Color = "96"
+)
+
+// ColorRule colors entries matching Filter (using the syntax of package filter). If Column is set, only the cell of
+// that column is colored, otherwise the whole row.
+type ColorRule struct {
+	Filter string
+	Column string
+	Color  Color
+}
@@ -70,6 +70,21 @@ type ContainerCollection struct {
 	// nodeName is used by the Enrich() function
 	nodeName string
 
+	// eventLabels are the keys of the container labels added to the events
+	// by the Enrich() functions
+	eventLabels []string
+
+	// resolveOwnerReference resolves the owner reference of a container,
+	// using the client of WithOwnerReferenceEnrichment(). It's nil if that
+	// option isn't used.
+	resolveOwnerReference func(*Container) error
+
+	// ownerReferenceRetries holds, by container ID, when the owner reference
+	// of containers that couldn't be resolved can be looked up again. See
+	// EnrichEventWithWorkload().
+	ownerReferenceRetriesMu sync.Mutex
+	ownerReferenceRetries   map[string]time.Time
+
 	// initialized tells if Initialize() has been called.
 	initialized bool
 
@@ -168,6 +183,11 @@ func (cc *ContainerCollection) RemoveContainer(id string) {
 	// the notification handler, and they expect the container to still be
 	// present.
 	cc.containers.Delete(id)
+	cc.forgetOwnerReferenceRetry(id)
+
+	if container.HostCgroup {
+		return
+	}
 
 	// Make this operation atomic, as RemoveContainer() could be called concurrently, which could result in
 	// dirty map contents
@@ -227,6 +247,16 @@ func (cc *ContainerCollection) AddContainer(container *Container) {
 	if loaded {
 		return
 	}
+
+	// Host cgroups usually share the namespaces of the host, so they are
+	// kept out of the lookups by namespace.
+	if container.HostCgroup {
+		if cc.pubsub != nil {
+			cc.pubsub.Publish(EventTypeAddContainer, container)
+		}
+		return
+	}
+
 	cc.mu.Lock()
 	cc.containersByMntNs.Store(container.Mntns, container)
 	arr, ok := cc.containersByNetNs.Load(container.Netns)
@@ -438,7 +468,34 @@ func (cc *ContainerCollection) EnrichByMntNs(event *eventtypes.CommonData, mount
 		event.Container = container.Name
 		event.Pod = container.Podname
 		event.Namespace = container.Namespace
+		cc.enrichImage(event, container)
+	}
+}
+
+func (cc *ContainerCollection) enrichImage(event *eventtypes.CommonData, container *Container) {
+	event.SetContainerImage(container.ImageName, container.ImageTag, container.ImageDigest)
+	event.SetContainerLabels(selectEventLabels(container, cc.eventLabels))
+}
+
+// selectEventLabels returns the labels of the container with the given keys.
+// Kubernetes labels take precedence over the ones reported by the container
+// runtime.
+func selectEventLabels(container *Container, keys []string) map[string]string {
+	var labels map[string]string
+	for _, key := range keys {
+		value, ok := container.Labels[key]
+		if !ok {
+			value, ok = container.RuntimeLabels[key]
+		}
+		if !ok {
+			continue
+		}
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[key] = value
 	}
+	return labels
 }
 
 func (cc *ContainerCollection) EnrichByNetNs(event *eventtypes.CommonData, netnsid uint64) {
@@ -460,6 +517,7 @@ func (cc *ContainerCollection) EnrichByNetNs(event *eventtypes.CommonData, netns
 		event.Container = containers[0].Name
 		event.Pod = containers[0].Podname
 		event.Namespace = containers[0].Namespace
+		cc.enrichImage(event, containers[0])
 		return
 	}
 	if containers[0].Podname != "" && containers[0].Namespace != "" {
@@ -17,11 +17,13 @@ package containercollection
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	ocispec "github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/sys/unix"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 
@@ -59,6 +61,14 @@ type Container struct {
 	CgroupV1 string `json:"cgroupV1,omitempty"`
 	CgroupV2 string `json:"cgroupV2,omitempty"`
 
+	// SystemdUnit is the systemd unit (service or scope) of the cgroup, if
+	// any.
+	SystemdUnit string `json:"systemdUnit,omitempty" column:"systemdunit,width:30,hide"`
+
+	// HostCgroup is set for the pseudo-containers representing host
+	// cgroups, like systemd services, added by WithHostCgroups().
+	HostCgroup bool `json:"hostCgroup,omitempty"`
+
 	// Kubernetes metadata
 	Namespace string            `json:"namespace,omitempty"`
 	Podname   string            `json:"podname,omitempty"`
@@ -66,7 +76,24 @@ type Container struct {
 	Labels    map[string]string `json:"labels,omitempty"`
 	PodUID    string            `json:"podUID,omitempty"`
 
-	ownerReference *metav1.OwnerReference
+	// Image metadata, as reported by the container runtime or Kubernetes.
+	// ImageDigest is the repository digest when available, otherwise the
+	// image ID.
+	ImageName   string `json:"imageName,omitempty" column:"imagename,hide"`
+	ImageTag    string `json:"imageTag,omitempty" column:"imagetag,hide"`
+	ImageDigest string `json:"imageDigest,omitempty" column:"imagedigest,hide"`
+
+	// RuntimeLabels are the labels of the container as reported by the
+	// container runtime. Depending on the runtime, they also include the
+	// labels of the image.
+	RuntimeLabels map[string]string `json:"runtimeLabels,omitempty"`
+
+	// ownerReference is the owner reference resolved by
+	// ownerReferenceEnrichment() and ownerReferenceResolved tells if that
+	// happened, so that pods without owner aren't looked up again. Both are
+	// guarded by ownerReferenceMu.
+	ownerReference         *metav1.OwnerReference
+	ownerReferenceResolved bool
 
 	// We keep an open file descriptor of the containers mount and net namespaces to be sure the
 	// kernel doesn't reuse the inode id before we get rid of this container. This logic avoids
@@ -92,11 +119,31 @@ func (c *Container) close() {
 	}
 }
 
+// ContainerSelector defines the criteria to select containers. Namespace,
+// Podname, Name, OwnerKind and OwnerName are comma-separated lists of
+// patterns, see MatchPatterns() for their syntax. Empty fields don't filter.
 type ContainerSelector struct {
 	Namespace string
 	Podname   string
 	Labels    map[string]string
 	Name      string
+
+	// LabelSelector is a Kubernetes label selector supporting set-based
+	// requirements (e.g. "env in (prod,staging),!canary"). It's applied in
+	// addition to Labels.
+	LabelSelector labels.Selector
+
+	// OwnerKind and OwnerName select containers by the kind (e.g.
+	// Deployment, DaemonSet, Job) and name of the workload owning their pod,
+	// as returned by Container.GetOwnerReference().
+	OwnerKind string
+	OwnerName string
+
+	// CgroupPath and SystemdUnit select containers by their cgroup v2 path
+	// (e.g. "/system.slice/*") and systemd unit (e.g. "kubelet.service").
+	// Host cgroups are only selected when one of them is set.
+	CgroupPath  string
+	SystemdUnit string
 }
 
 // GetOwnerReference returns the owner reference information of the
@@ -105,8 +152,8 @@ type ContainerSelector struct {
 // enrich" this information because this operation is expensive and this
 // information is only needed in some cases.
 func (c *Container) GetOwnerReference() (*metav1.OwnerReference, error) {
-	if c.ownerReference != nil {
-		return c.ownerReference, nil
+	if ownerRef, ok := c.cachedOwnerReference(); ok {
+		return ownerRef, nil
 	}
 
 	kubeconfig, err := rest.InClusterConfig()
@@ -124,7 +171,21 @@ func (c *Container) GetOwnerReference() (*metav1.OwnerReference, error) {
 		return nil, fmt.Errorf("enriching owner reference: %w", err)
 	}
 
-	return c.ownerReference, nil
+	ownerRef, _ := c.cachedOwnerReference()
+	return ownerRef, nil
+}
+
+// ownerReferenceMu guards the owner reference of all containers. It isn't a
+// field of Container because containers are copied by value.
+var ownerReferenceMu sync.Mutex
+
+// cachedOwnerReference returns the owner reference of the container without
+// looking it up. The boolean is false if it hasn't been resolved yet.
+func (c *Container) cachedOwnerReference() (*metav1.OwnerReference, bool) {
+	ownerReferenceMu.Lock()
+	defer ownerReferenceMu.Unlock()
+
+	return c.ownerReference, c.ownerReferenceResolved || c.ownerReference != nil
 }
 
 func ownerReferenceEnrichment(
@@ -173,8 +234,9 @@ func ownerReferenceEnrichment(
 	}
 
 	// Update container's owner reference (If any)
+	var ownerRef *metav1.OwnerReference
 	if highestOwnerRef != nil {
-		container.ownerReference = &metav1.OwnerReference{
+		ownerRef = &metav1.OwnerReference{
 			APIVersion: highestOwnerRef.APIVersion,
 			Kind:       highestOwnerRef.Kind,
 			Name:       highestOwnerRef.Name,
@@ -182,9 +244,20 @@ func ownerReferenceEnrichment(
 		}
 	}
 
+	container.setOwnerReference(ownerRef)
 	return nil
 }
 
+// setOwnerReference stores the resolved owner reference of the container,
+// which is nil for pods without owner.
+func (c *Container) setOwnerReference(ownerRef *metav1.OwnerReference) {
+	ownerReferenceMu.Lock()
+	defer ownerReferenceMu.Unlock()
+
+	c.ownerReference = ownerRef
+	c.ownerReferenceResolved = true
+}
+
 func GetColumns() *columns.Columns[Container] {
 	return columns.MustCreateColumns[Container]()
 }
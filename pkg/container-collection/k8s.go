@@ -37,6 +37,7 @@ type K8sClient struct {
 	nodeName      string
 	fieldSelector string
 	runtimeClient runtimeclient.ContainerRuntimeClient
+	runtimeName   string
 }
 
 func NewK8sClient(nodeName string) (*K8sClient, error) {
@@ -72,6 +73,7 @@ func NewK8sClient(nodeName string) (*K8sClient, error) {
 		nodeName:      nodeName,
 		fieldSelector: fieldSelector,
 		runtimeClient: runtimeClient,
+		runtimeName:   list[0],
 	}, nil
 }
 
@@ -111,6 +113,18 @@ func (k *K8sClient) GetNonRunningContainers(pod *v1.Pod) []string {
 	return ret
 }
 
+// k8sImageDigest returns the digest from the image ID of a container status,
+// e.g. "docker-pullable://nginx@sha256:..." or "sha256:...".
+func k8sImageDigest(imageID string) string {
+	if _, digest, ok := strings.Cut(imageID, "@"); ok {
+		return digest
+	}
+	if _, id, ok := strings.Cut(imageID, "://"); ok {
+		return id
+	}
+	return imageID
+}
+
 // GetRunningContainers returns a list of the containers of a given Pod that are running.
 func (k *K8sClient) GetRunningContainers(pod *v1.Pod) []Container {
 	containers := []Container{}
@@ -155,6 +169,7 @@ func (k *K8sClient) GetRunningContainers(pod *v1.Pod) []Container {
 			Labels:    labels,
 			Pid:       uint32(pid),
 		}
+		enrichContainerWithImage(&containerDef, s.Image, k8sImageDigest(s.ImageID))
 		containers = append(containers, containerDef)
 	}
 
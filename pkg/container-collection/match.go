@@ -15,21 +15,34 @@
 package containercollection
 
 import (
+	"fmt"
+	"path"
+	"regexp"
 	"strings"
 
-	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 // ContainerSelectorMatches tells if a container matches the criteria in a
 // container selector.
 func ContainerSelectorMatches(s *ContainerSelector, c *Container) bool {
-	if s.Namespace != "" && !slices.Contains(strings.Split(s.Namespace, ","), c.Namespace) {
+	if !MatchPatterns(s.CgroupPath, c.CgroupV2) || !MatchPatterns(s.SystemdUnit, c.SystemdUnit) {
 		return false
 	}
-	if s.Podname != "" && s.Podname != c.Podname {
+	if c.HostCgroup {
+		// Host cgroups have no Kubernetes metadata: they're only selected
+		// explicitly by their cgroup path or systemd unit, and optionally
+		// narrowed down by name.
+		return (s.CgroupPath != "" || s.SystemdUnit != "") && MatchPatterns(s.Name, c.Name)
+	}
+
+	if !MatchPatterns(s.Namespace, c.Namespace) {
 		return false
 	}
-	if s.Name != "" && s.Name != c.Name {
+	if !MatchPatterns(s.Podname, c.Podname) {
+		return false
+	}
+	if !MatchPatterns(s.Name, c.Name) {
 		return false
 	}
 	for sk, sv := range s.Labels {
@@ -37,6 +50,121 @@ func ContainerSelectorMatches(s *ContainerSelector, c *Container) bool {
 			return false
 		}
 	}
+	if s.LabelSelector != nil && !s.LabelSelector.Matches(labels.Set(c.Labels)) {
+		return false
+	}
+
+	if s.OwnerKind != "" || s.OwnerName != "" {
+		// The owner is resolved when the container is added, see
+		// WithOwnerReferenceEnrichment(); it's never looked up here because
+		// this is called from pubsub callbacks. Containers whose owner isn't
+		// known are handled as having an empty owner, so that they are still
+		// selected by exclusions.
+		var ownerKind, ownerName string
+		if ownerRef, _ := c.cachedOwnerReference(); ownerRef != nil {
+			ownerKind = ownerRef.Kind
+			ownerName = ownerRef.Name
+		}
+		if !MatchPatterns(s.OwnerKind, ownerKind) || !MatchPatterns(s.OwnerName, ownerName) {
+			return false
+		}
+	}
 
 	return true
 }
+
+// MatchPatterns tells if value matches a comma-separated list of patterns.
+// Each pattern is either an exact value, a glob (e.g. "nginx-*") or a regular
+// expression delimited by slashes (e.g. "/^nginx-[0-9]+$/"), which can contain
+// commas and escaped slashes. Patterns
+// prefixed with "!" are exclusions. A value matches if it matches any of the
+// inclusion patterns, or there are none, and none of the exclusions. An empty
+// list of patterns matches everything.
+func MatchPatterns(patterns, value string) bool {
+	if patterns == "" {
+		return true
+	}
+
+	included := false
+	hasInclusions := false
+	for _, pattern := range SplitPatterns(patterns) {
+		if pattern == "" {
+			continue
+		}
+		if strings.HasPrefix(pattern, "!") {
+			if matchPattern(pattern[1:], value) {
+				return false
+			}
+			continue
+		}
+		hasInclusions = true
+		if !included && matchPattern(pattern, value) {
+			included = true
+		}
+	}
+
+	return included || !hasInclusions
+}
+
+// ValidatePatterns checks the syntax of a comma-separated list of patterns as
+// accepted by MatchPatterns().
+func ValidatePatterns(patterns string) error {
+	if patterns == "" {
+		return nil
+	}
+	for _, pattern := range SplitPatterns(patterns) {
+		pattern = strings.TrimPrefix(pattern, "!")
+		if expr, ok := regexPattern(pattern); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("invalid regular expression %q: %w", pattern, err)
+			}
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// SplitPatterns splits a comma-separated list of patterns, ignoring the commas
+// inside regular expressions (e.g. "/^a{1,3}$/")
+func SplitPatterns(patterns string) []string {
+	var result []string
+	start := 0
+	inRegex := false
+	for i := 0; i < len(patterns); i++ {
+		switch c := patterns[i]; {
+		case inRegex && c == '\\':
+			// Skip the escaped character, e.g. "\/"
+			i++
+		case inRegex && c == '/':
+			inRegex = false
+		case c == '/' && strings.TrimPrefix(patterns[start:i], "!") == "":
+			inRegex = true
+		case !inRegex && c == ',':
+			result = append(result, patterns[start:i])
+			start = i + 1
+		}
+	}
+	return append(result, patterns[start:])
+}
+
+func regexPattern(pattern string) (string, bool) {
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return pattern[1 : len(pattern)-1], true
+	}
+	return "", false
+}
+
+func matchPattern(pattern, value string) bool {
+	if expr, ok := regexPattern(pattern); ok {
+		matched, err := regexp.MatchString(expr, value)
+		return err == nil && matched
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, value)
+		return err == nil && matched
+	}
+	return pattern == value
+}
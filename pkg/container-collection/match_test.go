@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -124,6 +125,171 @@ func TestSelector(t *testing.T) {
 				Name:      "this-container",
 			},
 		},
+		{
+			description: "Excluded namespace",
+			match:       false,
+			selector: &ContainerSelector{
+				Namespace: "!kube-system",
+			},
+			container: &Container{
+				Namespace: "kube-system",
+				Podname:   "this-pod",
+				Name:      "this-container",
+			},
+		},
+		{
+			description: "Namespace not excluded",
+			match:       true,
+			selector: &ContainerSelector{
+				Namespace: "!kube-system",
+			},
+			container: &Container{
+				Namespace: "this-namespace",
+				Podname:   "this-pod",
+				Name:      "this-container",
+			},
+		},
+		{
+			description: "Pod and container name patterns",
+			match:       true,
+			selector: &ContainerSelector{
+				Podname: "this-*",
+				Name:    "/^this-(container|sidecar)$/",
+			},
+			container: &Container{
+				Namespace: "this-namespace",
+				Podname:   "this-pod",
+				Name:      "this-container",
+			},
+		},
+		{
+			description: "Label selector with set-based requirements",
+			match:       true,
+			selector: &ContainerSelector{
+				LabelSelector: mustParseLabelSelector(t, "env in (prod,staging),tier,!canary"),
+			},
+			container: &Container{
+				Namespace: "this-namespace",
+				Podname:   "this-pod",
+				Name:      "this-container",
+				Labels: map[string]string{
+					"env":  "prod",
+					"tier": "backend",
+				},
+			},
+		},
+		{
+			description: "Label selector doesn't match",
+			match:       false,
+			selector: &ContainerSelector{
+				LabelSelector: mustParseLabelSelector(t, "env notin (prod)"),
+			},
+			container: &Container{
+				Namespace: "this-namespace",
+				Podname:   "this-pod",
+				Name:      "this-container",
+				Labels: map[string]string{
+					"env": "prod",
+				},
+			},
+		},
+		{
+			description: "Owner kind and name",
+			match:       true,
+			selector: &ContainerSelector{
+				OwnerKind: "Deployment,DaemonSet",
+				OwnerName: "my-*",
+			},
+			container: &Container{
+				Namespace: "this-namespace",
+				Podname:   "this-pod",
+				Name:      "this-container",
+				ownerReference: &metav1.OwnerReference{
+					Kind: "Deployment",
+					Name: "my-deployment",
+				},
+			},
+		},
+		{
+			description: "Excluded owner kind",
+			match:       false,
+			selector: &ContainerSelector{
+				OwnerKind: "!Job",
+			},
+			container: &Container{
+				Namespace: "this-namespace",
+				Podname:   "this-pod",
+				Name:      "this-container",
+				ownerReference: &metav1.OwnerReference{
+					Kind: "Job",
+					Name: "my-job",
+				},
+			},
+		},
+		{
+			description: "Excluded owner kind with unresolved owner",
+			match:       true,
+			selector: &ContainerSelector{
+				OwnerKind: "!Job",
+			},
+			container: &Container{
+				Namespace: "this-namespace",
+				Podname:   "this-pod",
+				Name:      "this-container",
+			},
+		},
+		{
+			description: "Host cgroup selected by systemd unit",
+			match:       true,
+			selector: &ContainerSelector{
+				Namespace:   "default",
+				SystemdUnit: "kubelet.service,containerd.service",
+			},
+			container: &Container{
+				Name:        "kubelet.service",
+				CgroupV2:    "/system.slice/kubelet.service",
+				SystemdUnit: "kubelet.service",
+				HostCgroup:  true,
+			},
+		},
+		{
+			description: "Host cgroup selected by cgroup path",
+			match:       true,
+			selector: &ContainerSelector{
+				CgroupPath: "/system.slice/*",
+			},
+			container: &Container{
+				Name:        "kubelet.service",
+				CgroupV2:    "/system.slice/kubelet.service",
+				SystemdUnit: "kubelet.service",
+				HostCgroup:  true,
+			},
+		},
+		{
+			description: "Host cgroup not selected without cgroup filters",
+			match:       false,
+			selector:    &ContainerSelector{},
+			container: &Container{
+				Name:        "kubelet.service",
+				CgroupV2:    "/system.slice/kubelet.service",
+				SystemdUnit: "kubelet.service",
+				HostCgroup:  true,
+			},
+		},
+		{
+			description: "Container not matching systemd unit",
+			match:       false,
+			selector: &ContainerSelector{
+				SystemdUnit: "kubelet.service",
+			},
+			container: &Container{
+				Namespace:   "this-namespace",
+				Podname:     "this-pod",
+				Name:        "this-container",
+				CgroupV2:    "/kubepods.slice/cri-containerd-0123.scope",
+				SystemdUnit: "cri-containerd-0123.scope",
+			},
+		},
 	}
 
 	for i, entry := range table {
@@ -135,6 +301,78 @@ func TestSelector(t *testing.T) {
 	}
 }
 
+func mustParseLabelSelector(t *testing.T, selector string) labels.Selector {
+	t.Helper()
+
+	s, err := labels.Parse(selector)
+	if err != nil {
+		t.Fatalf("Failed to parse label selector %q: %s", selector, err)
+	}
+	return s
+}
+
+func TestMatchPatterns(t *testing.T) {
+	table := []struct {
+		patterns string
+		value    string
+		match    bool
+	}{
+		{"", "anything", true},
+		{"foo", "foo", true},
+		{"foo", "bar", false},
+		{"foo,bar", "bar", true},
+		{"foo-*", "foo-1", true},
+		{"foo-?", "foo-12", false},
+		{"/^foo-[0-9]+$/", "foo-12", true},
+		{"/^foo-[0-9]+$/", "foo-bar", false},
+		{"!foo", "foo", false},
+		{"!foo", "bar", true},
+		{"!foo", "", true},
+		{"foo-*,!foo-2", "foo-1", true},
+		{"foo-*,!foo-2", "foo-2", false},
+		{"foo", "", false},
+		{"/^a{1,3}$/", "aa", true},
+		{"/^a{1,3}$/", "aaaa", false},
+		{"bar,/^a{1,3}$/,!/^a{2,2}$/", "aaa", true},
+		{"bar,/^a{1,3}$/,!/^a{2,2}$/", "aa", false},
+		{`/^a\/b,c$/`, "a/b,c", true},
+	}
+
+	for _, entry := range table {
+		if result := MatchPatterns(entry.patterns, entry.value); result != entry.match {
+			t.Fatalf("MatchPatterns(%q, %q): result %v expected %v",
+				entry.patterns, entry.value, result, entry.match)
+		}
+	}
+
+	if err := ValidatePatterns("foo,!bar-*,/^baz$/"); err != nil {
+		t.Fatalf("Unexpected error validating patterns: %s", err)
+	}
+	if err := ValidatePatterns("/^a{1,3}$/,!/[0-9]{2,}/"); err != nil {
+		t.Fatalf("Unexpected error validating regular expressions with commas: %s", err)
+	}
+	if err := ValidatePatterns("/foo(/"); err == nil {
+		t.Fatalf("Expected error validating invalid regular expression")
+	}
+	if err := ValidatePatterns("foo["); err == nil {
+		t.Fatalf("Expected error validating invalid glob")
+	}
+
+	split := SplitPatterns("foo,!/^a{1,3}$/,bar")
+	if !reflect.DeepEqual(split, []string{"foo", "!/^a{1,3}$/", "bar"}) {
+		t.Fatalf("SplitPatterns: unexpected result %q", split)
+	}
+}
+
+func TestGetOwnerReferenceCached(t *testing.T) {
+	// A pod without owner must not be looked up again
+	c := &Container{ownerReferenceResolved: true}
+	ownerRef, err := c.GetOwnerReference()
+	if err != nil || ownerRef != nil {
+		t.Fatalf("GetOwnerReference(): got %v, %v; expected nil, nil", ownerRef, err)
+	}
+}
+
 func TestContainerResolver(t *testing.T) {
 	opts := []ContainerCollectionOption{}
 
@@ -28,6 +28,14 @@ func (cc *ContainerCollection) EnrichEventByMntNs(event operators.ContainerInfoF
 	}
 	if container != nil {
 		event.SetContainerInfo(container.Podname, container.Namespace, container.Name)
+		cc.setContainerImage(event, container)
+	}
+}
+
+func (cc *ContainerCollection) setContainerImage(event any, container *Container) {
+	if setter, ok := event.(operators.ContainerImageSetter); ok {
+		setter.SetContainerImage(container.ImageName, container.ImageTag, container.ImageDigest)
+		setter.SetContainerLabels(selectEventLabels(container, cc.eventLabels))
 	}
 }
 
@@ -44,6 +52,7 @@ func (cc *ContainerCollection) EnrichEventByNetNs(event operators.ContainerInfoF
 	}
 	if len(containers) == 1 {
 		event.SetContainerInfo(containers[0].Podname, containers[0].Namespace, containers[0].Name)
+		cc.setContainerImage(event, containers[0])
 		return
 	}
 	if containers[0].Podname != "" && containers[0].Namespace != "" {
@@ -57,3 +66,30 @@ func (cc *ContainerCollection) EnrichEventByNetNs(event operators.ContainerInfoF
 
 	return
 }
+
+// EnrichEventLabels sets the labels with the given keys of the container the
+// event comes from, instead of the ones configured with WithEventLabels().
+// It's used when the labels are chosen for each gadget run.
+func (cc *ContainerCollection) EnrichEventLabels(event operators.ContainerImageSetter, keys []string) {
+	var container *Container
+	switch e := event.(type) {
+	case operators.ContainerInfoFromMountNSID:
+		container = cc.LookupContainerByMntns(e.GetMountNSID())
+		if container == nil && cc.cachedContainers != nil {
+			container = lookupContainerByMntns(cc.cachedContainers, e.GetMountNSID())
+		}
+	case operators.ContainerInfoFromNetNSID:
+		containers := cc.LookupContainersByNetns(e.GetNetNSID())
+		if len(containers) == 0 && cc.cachedContainers != nil {
+			containers = lookupContainersByNetns(cc.cachedContainers, e.GetNetNSID())
+		}
+		if len(containers) == 1 && !containers[0].HostNetwork {
+			container = containers[0]
+		}
+	}
+	if container == nil {
+		return
+	}
+
+	event.SetContainerLabels(selectEventLabels(container, keys))
+}
@@ -18,14 +18,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"math"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -57,6 +61,28 @@ func enrichContainerWithContainerData(containerData *runtimeclient.ContainerData
 	// Kubernetes container name because the Container struct doesn't have that
 	// field, and we don't support filtering by runtime container name yet.
 	container.Name = containerData.Name
+
+	// Image
+	enrichContainerWithImage(container, containerData.Image, containerData.ImageDigest)
+	if containerData.Labels != nil {
+		container.RuntimeLabels = containerData.Labels
+	}
+}
+
+// enrichContainerWithImage sets the image fields of the container that are
+// not set yet.
+func enrichContainerWithImage(container *Container, image, digest string) {
+	name, tag, refDigest := runtimeclient.ParseImageReference(image)
+	if container.ImageName == "" {
+		container.ImageName = name
+		container.ImageTag = tag
+	}
+	if container.ImageDigest == "" {
+		container.ImageDigest = refDigest
+		if digest != "" {
+			container.ImageDigest = digest
+		}
+	}
 }
 
 func containerRuntimeEnricher(
@@ -322,6 +348,151 @@ func WithHost() ContainerCollectionOption {
 	}
 }
 
+// WithHostCgroups adds the systemd services running on the host, like kubelet
+// or containerd, as pseudo-containers. They are found by walking the cgroup v2
+// hierarchy and only selected by selectors filtering on cgroup path or systemd
+// unit, see ContainerSelector. Services are rescanned periodically to track
+// the ones that start, stop or restart.
+//
+// Host cgroups usually share the mount namespace of the host, hence they're
+// only filtered by cgroup ID: the tracer collection doesn't add their mount
+// namespace to the maps of the tracers.
+func WithHostCgroups() ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		root, err := cgroups.CgroupPathV2AddMountpoint("/")
+		if err != nil {
+			log.Warnf("host cgroups: finding cgroup v2 mountpoint: %s", err)
+			return nil
+		}
+
+		known := make(map[string]uint64)
+		for _, c := range syncHostCgroups(root, known, cc.RemoveContainer) {
+			cc.initialContainers = append(cc.initialContainers, c)
+		}
+
+		go func() {
+			ticker := time.NewTicker(hostCgroupsRescanInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					for _, c := range syncHostCgroups(root, known, cc.RemoveContainer) {
+						cc.AddContainer(c)
+					}
+				case <-cc.done:
+					return
+				}
+			}
+		}()
+
+		return nil
+	}
+}
+
+const hostCgroupsRescanInterval = 10 * time.Second
+
+type hostCgroup struct {
+	// path is relative to the cgroup v2 mountpoint
+	path string
+	pid  int
+}
+
+// syncHostCgroups scans the host cgroups under root and returns the
+// pseudo-containers of the new ones. known maps the path of the cgroups
+// already added to their ID, and it's updated accordingly. Cgroups that
+// disappeared or were recreated are removed with remove.
+func syncHostCgroups(root string, known map[string]uint64, remove func(id string)) []*Container {
+	found, err := scanHostCgroups(root)
+	if err != nil {
+		log.Warnf("host cgroups: scanning %q: %s", root, err)
+		return nil
+	}
+
+	var containers []*Container
+	current := make(map[string]struct{}, len(found))
+	for _, hc := range found {
+		cgroupID, err := cgroups.GetCgroupID(filepath.Join(root, hc.path))
+		if err != nil {
+			log.Debugf("host cgroups: %s", err)
+			continue
+		}
+		current[hc.path] = struct{}{}
+
+		if oldID, ok := known[hc.path]; ok {
+			if oldID == cgroupID {
+				continue
+			}
+			// The cgroup was recreated, e.g. because the service restarted
+			remove(hostCgroupContainerID(oldID))
+		}
+		known[hc.path] = cgroupID
+
+		containers = append(containers, &Container{
+			ID:         hostCgroupContainerID(cgroupID),
+			Name:       filepath.Base(hc.path),
+			Pid:        uint32(hc.pid),
+			HostCgroup: true,
+		})
+	}
+
+	for path, cgroupID := range known {
+		if _, ok := current[path]; !ok {
+			remove(hostCgroupContainerID(cgroupID))
+			delete(known, path)
+		}
+	}
+
+	return containers
+}
+
+func hostCgroupContainerID(cgroupID uint64) string {
+	return fmt.Sprintf("hostcgroup-%d", cgroupID)
+}
+
+// scanHostCgroups returns the systemd services with at least one process in
+// the cgroup v2 hierarchy mounted at root. Only slices are walked into, so
+// containers and sessions, which run in scopes, are ignored.
+func scanHostCgroups(root string) ([]hostCgroup, error) {
+	var found []hostCgroup
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == root {
+			return nil
+		}
+		name := d.Name()
+		if strings.HasSuffix(name, ".slice") {
+			return nil
+		}
+		if strings.HasSuffix(name, ".service") {
+			if pid := firstCgroupPid(path); pid != 0 {
+				found = append(found, hostCgroup{
+					path: strings.TrimPrefix(path, root),
+					pid:  pid,
+				})
+			}
+		}
+		return filepath.SkipDir
+	})
+	return found, err
+}
+
+// firstCgroupPid returns the first process in a cgroup, or zero if there are
+// none.
+func firstCgroupPid(path string) int {
+	content, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+	if err != nil {
+		return 0
+	}
+	line, _, _ := strings.Cut(string(content), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
 // WithInitialKubernetesContainers gets initial containers from the Kubernetes
 // API with the process ID from CRI.
 //
@@ -453,7 +624,8 @@ func WithKubernetesEnrichment(nodeName string, kubeconfig *rest.Config) Containe
 
 		// Future containers
 		cc.containerEnrichers = append(cc.containerEnrichers, func(container *Container) bool {
-			if container.Podname != "" {
+			// Host cgroups don't belong to any pod
+			if container.Podname != "" || container.HostCgroup {
 				return true
 			}
 
@@ -476,6 +648,7 @@ func WithKubernetesEnrichment(nodeName string, kubeconfig *rest.Config) Containe
 			podname := ""
 			podUID := ""
 			containerName := ""
+			var containerStatuses []v1.ContainerStatus
 			labels := make(map[string]string)
 			for _, pod := range pods.Items {
 				uid := string(pod.ObjectMeta.UID)
@@ -497,6 +670,10 @@ func WithKubernetesEnrichment(nodeName string, kubeconfig *rest.Config) Containe
 					labels[k] = v
 				}
 
+				containerStatuses = append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+				containerStatuses = append(containerStatuses, pod.Status.ContainerStatuses...)
+				containerStatuses = append(containerStatuses, pod.Status.EphemeralContainerStatuses...)
+
 				containerNames := []string{}
 				for _, c := range pod.Spec.Containers {
 					containerNames = append(containerNames, c.Name)
@@ -525,6 +702,13 @@ func WithKubernetesEnrichment(nodeName string, kubeconfig *rest.Config) Containe
 			container.Name = containerName
 			container.Labels = labels
 
+			for _, s := range containerStatuses {
+				if s.Name == containerName {
+					enrichContainerWithImage(container, s.Image, k8sImageDigest(s.ImageID))
+					break
+				}
+			}
+
 			// drop pause containers
 			if container.Podname != "" && containerName == "" {
 				return false
@@ -536,6 +720,49 @@ func WithKubernetesEnrichment(nodeName string, kubeconfig *rest.Config) Containe
 	}
 }
 
+// WithOwnerReferenceEnrichment enables an enricher to resolve the workload
+// owning the pod of the containers, as returned by
+// Container.GetOwnerReference(). It's needed to select containers by
+// ContainerSelector.OwnerKind and OwnerName and must be used after
+// WithKubernetesEnrichment() or WithPodInformer().
+//
+// ContainerCollection.Initialize(WithOwnerReferenceEnrichment(kubeconfig))
+func WithOwnerReferenceEnrichment(kubeconfig *rest.Config) ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		if kubeconfig == nil {
+			var err error
+			kubeconfig, err = rest.InClusterConfig()
+			if err != nil {
+				return fmt.Errorf("getting Kubernetes config: %w", err)
+			}
+		}
+		dynamicClient, err := dynamic.NewForConfig(kubeconfig)
+		if err != nil {
+			return fmt.Errorf("getting dynamic Kubernetes client: %w", err)
+		}
+
+		cc.resolveOwnerReference = func(container *Container) error {
+			return ownerReferenceEnrichment(dynamicClient, container, nil)
+		}
+
+		cc.containerEnrichers = append(cc.containerEnrichers, func(container *Container) bool {
+			if container.Podname == "" || container.HostCgroup {
+				return true
+			}
+
+			// On errors, the owner reference is left unresolved so that
+			// GetOwnerReference() or EnrichEventWithWorkload() try again
+			// later.
+			err := cc.resolveOwnerReference(container)
+			if err != nil {
+				log.Warnf("owner reference enricher: %s", err)
+			}
+			return true
+		})
+		return nil
+	}
+}
+
 // WithRuncFanotify uses fanotify to detect when containers are created and add
 // them in the ContainerCollection.
 //
@@ -623,6 +850,158 @@ func WithContainerFanotifyEbpf() ContainerCollectionOption {
 	}
 }
 
+// WithRuntimeEvents subscribes to the event streams of the given container
+// runtimes (containerd events API, Docker and Podman events endpoints and CRI
+// GetContainerEvents) to detect when containers are started and stopped, and
+// add or remove them in the ContainerCollection.
+//
+// Contrary to WithRuncFanotify() and WithContainerFanotifyEbpf(), it doesn't
+// depend on the OCI runtime (runc, crun, youki...) or on the shims being
+// used. It only detects future containers: use it together with
+// WithContainerRuntimeEnrichment() to get the initial ones. Lost connections
+// with the runtimes are retried until the ContainerCollection is closed.
+//
+// ContainerCollection.Initialize(WithRuntimeEvents([]*RuntimeConfig))
+func WithRuntimeEvents(runtimes []*containerutils.RuntimeConfig) ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		var clients []runtimeclient.ContainerRuntimeClient
+
+		for _, runtime := range runtimes {
+			runtimeClient, err := newContainerRuntimeClient(runtime)
+			if err != nil {
+				if !cc.disableContainerRuntimeWarnings {
+					log.Warnf("Runtime events (%s): failed to initialize container runtime: %s",
+						runtime.Name, err)
+				}
+				continue
+			}
+			clients = append(clients, runtimeClient)
+
+			eventsClient, ok := runtimeClient.(runtimeclient.ContainerEventsClient)
+			if !ok {
+				log.Warnf("Runtime events (%s): container runtime doesn't support events", runtime.Name)
+				continue
+			}
+
+			wg.Add(1)
+			go func(runtimeName string) {
+				defer wg.Done()
+				watchRuntimeEvents(ctx, cc, runtimeName, runtimeClient, eventsClient)
+			}(runtime.Name)
+		}
+
+		cc.cleanUpFuncs = append(cc.cleanUpFuncs, func() {
+			cancel()
+			wg.Wait()
+			for _, runtimeClient := range clients {
+				if err := runtimeClient.Close(); err != nil {
+					log.Warnf("failed to close container runtime: %s", err)
+				}
+			}
+		})
+
+		return nil
+	}
+}
+
+// WithKubernetesRuntimeEvents is like WithRuntimeEvents() but subscribes to
+// the events of the container runtime used by the given Kubernetes node.
+//
+// ContainerCollection.Initialize(WithKubernetesRuntimeEvents(nodeName))
+func WithKubernetesRuntimeEvents(nodeName string) ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		k8sClient, err := NewK8sClient(nodeName)
+		if err != nil {
+			return fmt.Errorf("creating Kubernetes client: %w", err)
+		}
+		runtimeName := k8sClient.runtimeName
+		k8sClient.Close()
+
+		return WithRuntimeEvents([]*containerutils.RuntimeConfig{{Name: runtimeName}})(cc)
+	}
+}
+
+// newContainerRuntimeClient is a variable so that tests can use fake clients
+var newContainerRuntimeClient = containerutils.NewContainerRuntimeClient
+
+const (
+	runtimeEventsMinBackoff = time.Second
+	runtimeEventsMaxBackoff = 30 * time.Second
+)
+
+func watchRuntimeEvents(
+	ctx context.Context,
+	cc *ContainerCollection,
+	runtimeName string,
+	runtimeClient runtimeclient.ContainerRuntimeClient,
+	eventsClient runtimeclient.ContainerEventsClient,
+) {
+	backoff := runtimeEventsMinBackoff
+	for {
+		receivedEvents := false
+		err := eventsClient.WatchContainerEvents(ctx, func(event runtimeclient.ContainerEvent) {
+			receivedEvents = true
+			handleRuntimeEvent(cc, runtimeName, runtimeClient, event)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if receivedEvents {
+			backoff = runtimeEventsMinBackoff
+		}
+
+		log.Debugf("Runtime events (%s): %s. Retrying in %s", runtimeName, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > runtimeEventsMaxBackoff {
+			backoff = runtimeEventsMaxBackoff
+		}
+	}
+}
+
+func handleRuntimeEvent(
+	cc *ContainerCollection,
+	runtimeName string,
+	runtimeClient runtimeclient.ContainerRuntimeClient,
+	event runtimeclient.ContainerEvent,
+) {
+	switch event.Type {
+	case runtimeclient.ContainerEventStarted:
+		// The container could have been already added by another mechanism.
+		if cc.GetContainer(event.ContainerID) != nil {
+			return
+		}
+
+		containerDetails, err := runtimeClient.GetContainerDetails(event.ContainerID)
+		if err != nil {
+			log.Debugf("Runtime events (%s): Skip container %q: couldn't find container: %s",
+				runtimeName, event.ContainerID, err)
+			return
+		}
+
+		pid := containerDetails.Pid
+		if pid == 0 || pid > math.MaxUint32 {
+			log.Debugf("Runtime events (%s): Skip container %q: invalid pid %d",
+				runtimeName, event.ContainerID, pid)
+			return
+		}
+
+		container := &Container{Pid: uint32(pid)}
+		enrichContainerWithContainerData(&containerDetails.ContainerData, container)
+		cc.AddContainer(container)
+	case runtimeclient.ContainerEventStopped:
+		cc.RemoveContainer(event.ContainerID)
+	}
+}
+
 // WithCgroupEnrichment enables an enricher to add the cgroup metadata
 func WithCgroupEnrichment() ContainerCollectionOption {
 	return func(cc *ContainerCollection) error {
@@ -645,6 +1024,11 @@ func WithCgroupEnrichment() ContainerCollectionOption {
 			container.CgroupID = cgroupID
 			container.CgroupV1 = cgroupPathV1
 			container.CgroupV2 = cgroupPathV2
+			if cgroupPathV2 != "" {
+				container.SystemdUnit = cgroups.GetSystemdUnit(cgroupPathV2)
+			} else {
+				container.SystemdUnit = cgroups.GetSystemdUnit(cgroupPathV1)
+			}
 			return true
 		})
 		return nil
@@ -734,6 +1118,16 @@ func WithOCIConfigEnrichment() ContainerCollectionOption {
 	}
 }
 
+// WithEventLabels sets the keys of the container labels that are added to the
+// events when enriching them. Both Kubernetes and container runtime labels
+// are considered.
+func WithEventLabels(keys []string) ContainerCollectionOption {
+	return func(cc *ContainerCollection) error {
+		cc.eventLabels = keys
+		return nil
+	}
+}
+
 func WithNodeName(nodeName string) ContainerCollectionOption {
 	return func(cc *ContainerCollection) error {
 		cc.nodeName = nodeName
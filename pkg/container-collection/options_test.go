@@ -15,9 +15,19 @@
 package containercollection
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
+	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
 )
 
 func TestGetExpectedOwnerReference(t *testing.T) {
@@ -268,3 +278,263 @@ func TestGetExpectedOwnerReference(t *testing.T) {
 		}
 	}
 }
+
+type fakeRuntimeClient struct {
+	runtimeclient.ContainerRuntimeClient
+	containers map[string]*runtimeclient.ContainerDetailsData
+}
+
+func (f *fakeRuntimeClient) GetContainerDetails(containerID string) (*runtimeclient.ContainerDetailsData, error) {
+	c, ok := f.containers[containerID]
+	if !ok {
+		return nil, fmt.Errorf("container %q not found", containerID)
+	}
+	return c, nil
+}
+
+func TestHandleRuntimeEvent(t *testing.T) {
+	client := &fakeRuntimeClient{
+		containers: map[string]*runtimeclient.ContainerDetailsData{
+			"abcde": {
+				ContainerData: runtimeclient.ContainerData{
+					ID:           "abcde",
+					Name:         "mycontainer",
+					Runtime:      runtimeclient.DockerName,
+					PodName:      "mypod",
+					PodNamespace: "myns",
+				},
+				Pid: 1234,
+			},
+			"nopid": {
+				ContainerData: runtimeclient.ContainerData{ID: "nopid"},
+			},
+		},
+	}
+
+	cc := &ContainerCollection{}
+	handle := func(eventType runtimeclient.ContainerEventType, id string) {
+		handleRuntimeEvent(cc, runtimeclient.DockerName, client, runtimeclient.ContainerEvent{
+			Type:        eventType,
+			ContainerID: id,
+		})
+	}
+
+	handle(runtimeclient.ContainerEventStarted, "abcde")
+	container := cc.GetContainer("abcde")
+	require.NotNil(t, container)
+	require.Equal(t, uint32(1234), container.Pid)
+	require.Equal(t, "mycontainer", container.Name)
+	require.Equal(t, runtimeclient.DockerName, container.Runtime)
+	require.Equal(t, "mypod", container.Podname)
+	require.Equal(t, "myns", container.Namespace)
+
+	// A second start event must not replace the container
+	handle(runtimeclient.ContainerEventStarted, "abcde")
+	require.Same(t, container, cc.GetContainer("abcde"))
+
+	handle(runtimeclient.ContainerEventStarted, "nopid")
+	require.Nil(t, cc.GetContainer("nopid"))
+
+	handle(runtimeclient.ContainerEventStarted, "unknown")
+	require.Nil(t, cc.GetContainer("unknown"))
+
+	handle(runtimeclient.ContainerEventStopped, "abcde")
+	require.Nil(t, cc.GetContainer("abcde"))
+}
+
+func TestEnrichContainerWithImage(t *testing.T) {
+	table := []struct {
+		description    string
+		image          string
+		digest         string
+		expectedName   string
+		expectedTag    string
+		expectedDigest string
+	}{
+		{
+			description:  "Name and tag",
+			image:        "docker.io/library/nginx:1.25",
+			expectedName: "docker.io/library/nginx",
+			expectedTag:  "1.25",
+		},
+		{
+			description:  "Registry with port and no tag",
+			image:        "localhost:5000/nginx",
+			expectedName: "localhost:5000/nginx",
+		},
+		{
+			description:    "Reference with digest",
+			image:          "localhost:5000/nginx:1.25@sha256:1234",
+			expectedName:   "localhost:5000/nginx",
+			expectedTag:    "1.25",
+			expectedDigest: "sha256:1234",
+		},
+		{
+			description:    "Digest reported by the runtime",
+			image:          "nginx:1.25@sha256:1234",
+			digest:         "sha256:5678",
+			expectedName:   "nginx",
+			expectedTag:    "1.25",
+			expectedDigest: "sha256:5678",
+		},
+		{
+			description:    "Image ID only",
+			image:          "sha256:1234",
+			expectedDigest: "sha256:1234",
+		},
+	}
+
+	for _, entry := range table {
+		t.Run(entry.description, func(t *testing.T) {
+			container := &Container{}
+			enrichContainerWithImage(container, entry.image, entry.digest)
+			require.Equal(t, entry.expectedName, container.ImageName)
+			require.Equal(t, entry.expectedTag, container.ImageTag)
+			require.Equal(t, entry.expectedDigest, container.ImageDigest)
+		})
+	}
+}
+
+func TestSelectEventLabels(t *testing.T) {
+	cc := &ContainerCollection{}
+	require.NoError(t, WithEventLabels([]string{"app", "org.opencontainers.image.version", "missing"})(cc))
+
+	container := &Container{
+		Labels: map[string]string{
+			"app":   "from-pod",
+			"other": "ignored",
+		},
+		RuntimeLabels: map[string]string{
+			"app":                              "from-runtime",
+			"org.opencontainers.image.version": "1.25",
+		},
+	}
+
+	require.Equal(t, map[string]string{
+		"app":                              "from-pod",
+		"org.opencontainers.image.version": "1.25",
+	}, selectEventLabels(container, cc.eventLabels))
+
+	require.Nil(t, selectEventLabels(&Container{}, cc.eventLabels))
+}
+
+type labelsEvent struct {
+	eventtypes.CommonData
+	eventtypes.WithMountNsID
+}
+
+func TestEnrichEventLabels(t *testing.T) {
+	cc := &ContainerCollection{}
+	require.NoError(t, cc.Initialize(WithEventLabels([]string{"app"})))
+	t.Cleanup(cc.Close)
+
+	cc.AddContainer(&Container{
+		ID:    "abcde",
+		Mntns: 1234,
+		Labels: map[string]string{
+			"app":  "myapp",
+			"tier": "frontend",
+		},
+	})
+
+	event := &labelsEvent{WithMountNsID: eventtypes.WithMountNsID{MountNsID: 1234}}
+	cc.EnrichEventByMntNs(event)
+	require.Equal(t, map[string]string{"app": "myapp"}, event.ContainerLabels)
+
+	// The labels chosen for a gadget run replace the ones of the collection
+	cc.EnrichEventLabels(event, []string{"tier"})
+	require.Equal(t, map[string]string{"tier": "frontend"}, event.ContainerLabels)
+
+	unknown := &labelsEvent{WithMountNsID: eventtypes.WithMountNsID{MountNsID: 5678}}
+	cc.EnrichEventLabels(unknown, []string{"tier"})
+	require.Nil(t, unknown.ContainerLabels)
+}
+
+// fakeEventsClient streams the events sent to its channel
+type fakeEventsClient struct {
+	*fakeRuntimeClient
+	events chan runtimeclient.ContainerEvent
+	closed bool
+}
+
+func (f *fakeEventsClient) WatchContainerEvents(ctx context.Context, callback func(runtimeclient.ContainerEvent)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-f.events:
+			callback(event)
+		}
+	}
+}
+
+func (f *fakeEventsClient) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestWithRuntimeEvents(t *testing.T) {
+	client := &fakeEventsClient{
+		fakeRuntimeClient: &fakeRuntimeClient{
+			containers: map[string]*runtimeclient.ContainerDetailsData{
+				"abcde": {
+					ContainerData: runtimeclient.ContainerData{
+						ID:      "abcde",
+						Name:    "mycontainer",
+						Runtime: runtimeclient.ContainerdName,
+					},
+					Pid: 1234,
+				},
+			},
+		},
+		events: make(chan runtimeclient.ContainerEvent),
+	}
+
+	oldNewContainerRuntimeClient := newContainerRuntimeClient
+	t.Cleanup(func() { newContainerRuntimeClient = oldNewContainerRuntimeClient })
+	newContainerRuntimeClient = func(runtime *containerutils.RuntimeConfig) (runtimeclient.ContainerRuntimeClient, error) {
+		require.Equal(t, runtimeclient.ContainerdName, runtime.Name)
+		return client, nil
+	}
+
+	cc := &ContainerCollection{}
+	err := cc.Initialize(WithRuntimeEvents([]*containerutils.RuntimeConfig{{Name: runtimeclient.ContainerdName}}))
+	require.NoError(t, err)
+
+	client.events <- runtimeclient.ContainerEvent{Type: runtimeclient.ContainerEventStarted, ContainerID: "abcde"}
+	require.Eventually(t, func() bool {
+		return cc.GetContainer("abcde") != nil
+	}, time.Second, 10*time.Millisecond)
+
+	client.events <- runtimeclient.ContainerEvent{Type: runtimeclient.ContainerEventStopped, ContainerID: "abcde"}
+	require.Eventually(t, func() bool {
+		return cc.GetContainer("abcde") == nil
+	}, time.Second, 10*time.Millisecond)
+
+	cc.Close()
+	require.True(t, client.closed)
+}
+
+func TestScanHostCgroups(t *testing.T) {
+	root := t.TempDir()
+	cgroups := map[string]string{
+		"/system.slice/kubelet.service":                            "1234\n1235\n",
+		"/system.slice/stopped.service":                            "",
+		"/system.slice/docker-0123.scope":                          "2000\n",
+		"/user.slice/user-1000.slice/user@1000.service":            "3000\n",
+		"/kubepods.slice/kubepods-pod1.slice/cri-containerd.scope": "4000\n",
+		"/init.scope": "1\n",
+	}
+	for path, procs := range cgroups {
+		dir := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(procs), 0o644))
+	}
+
+	found, err := scanHostCgroups(root)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []hostCgroup{
+		{path: "/system.slice/kubelet.service", pid: 1234},
+		{path: "/user.slice/user-1000.slice/user@1000.service", pid: 3000},
+	}, found)
+}
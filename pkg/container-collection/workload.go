@@ -0,0 +1,104 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+)
+
+// workloadRetryInterval is the time to wait before trying again to resolve the
+// owner reference of a container after an error
+const workloadRetryInterval = time.Minute
+
+// EnrichEventWithWorkload sets the kind and name of the workload owning the pod
+// the event comes from. The event must also implement
+// operators.ContainerInfoFromMountNSID or operators.ContainerInfoFromNetNSID
+// to find its container.
+//
+// The workload is the owner reference resolved by
+// WithOwnerReferenceEnrichment(), which is needed for this. Containers whose
+// owner reference couldn't be resolved when they were added are retried in the
+// background, so their events aren't enriched until it's known.
+func (cc *ContainerCollection) EnrichEventWithWorkload(event operators.ContainerWorkloadSetter) {
+	var container *Container
+	switch e := event.(type) {
+	case operators.ContainerInfoFromMountNSID:
+		container = cc.LookupContainerByMntns(e.GetMountNSID())
+		if container == nil && cc.cachedContainers != nil {
+			container = lookupContainerByMntns(cc.cachedContainers, e.GetMountNSID())
+		}
+	case operators.ContainerInfoFromNetNSID:
+		// Containers sharing a network namespace belong to the same pod
+		containers := cc.LookupContainersByNetns(e.GetNetNSID())
+		if len(containers) == 0 && cc.cachedContainers != nil {
+			containers = lookupContainersByNetns(cc.cachedContainers, e.GetNetNSID())
+		}
+		if len(containers) > 0 && !containers[0].HostNetwork {
+			container = containers[0]
+		}
+	}
+	if container == nil || container.Podname == "" || container.HostCgroup {
+		return
+	}
+
+	ownerRef, resolved := container.cachedOwnerReference()
+	switch {
+	case !resolved:
+		cc.retryOwnerReference(container)
+	case ownerRef != nil:
+		event.SetWorkload(ownerRef.Kind, ownerRef.Name)
+	default:
+		// Pods without owner are their own workload
+		event.SetWorkload("Pod", container.Podname)
+	}
+}
+
+// retryOwnerReference resolves the owner reference of a container in the
+// background, unless it's already being resolved or failed recently.
+func (cc *ContainerCollection) retryOwnerReference(container *Container) {
+	if cc.resolveOwnerReference == nil {
+		return
+	}
+
+	cc.ownerReferenceRetriesMu.Lock()
+	defer cc.ownerReferenceRetriesMu.Unlock()
+
+	if cc.ownerReferenceRetries == nil {
+		cc.ownerReferenceRetries = make(map[string]time.Time)
+	}
+	if retryAt, ok := cc.ownerReferenceRetries[container.ID]; ok && time.Now().Before(retryAt) {
+		return
+	}
+	cc.ownerReferenceRetries[container.ID] = time.Now().Add(workloadRetryInterval)
+
+	go func() {
+		if err := cc.resolveOwnerReference(container); err != nil {
+			log.Debugf("resolving workload of pod %s/%s: %s", container.Namespace, container.Podname, err)
+		}
+	}()
+}
+
+// forgetOwnerReferenceRetry removes the retry state of a container removed
+// from the collection.
+func (cc *ContainerCollection) forgetOwnerReferenceRetry(id string) {
+	cc.ownerReferenceRetriesMu.Lock()
+	defer cc.ownerReferenceRetriesMu.Unlock()
+
+	delete(cc.ownerReferenceRetries, id)
+}
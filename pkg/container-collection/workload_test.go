@@ -0,0 +1,132 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containercollection
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeWorkloadEvent struct {
+	mntnsid      uint64
+	workloadKind string
+	workloadName string
+}
+
+func (e *fakeWorkloadEvent) GetMountNSID() uint64 {
+	return e.mntnsid
+}
+
+func (e *fakeWorkloadEvent) SetNode(string) {}
+
+func (e *fakeWorkloadEvent) SetContainerInfo(pod, namespace, container string) {}
+
+func (e *fakeWorkloadEvent) SetWorkload(kind, name string) {
+	e.workloadKind = kind
+	e.workloadName = name
+}
+
+func TestEnrichEventWithWorkload(t *testing.T) {
+	cc := &ContainerCollection{}
+
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	fail := true
+	cc.resolveOwnerReference = func(c *Container) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[c.ID]++
+		if fail {
+			return errors.New("forbidden")
+		}
+		c.setOwnerReference(&metav1.OwnerReference{Kind: "StatefulSet", Name: "redis"})
+		return nil
+	}
+
+	nginx := &Container{ID: "c1", Mntns: 1, Podname: "nginx-6d4cf56db6-abcde", PodUID: "uid1"}
+	nginx.setOwnerReference(&metav1.OwnerReference{Kind: "Deployment", Name: "nginx"})
+	debug := &Container{ID: "c2", Mntns: 2, Podname: "debug", PodUID: "uid2"}
+	debug.setOwnerReference(nil)
+	cc.AddContainer(nginx)
+	cc.AddContainer(debug)
+	cc.AddContainer(&Container{ID: "c3", Mntns: 3, Podname: "redis-0", PodUID: "uid3"})
+
+	// The owner reference resolved when the container was added is used
+	event := &fakeWorkloadEvent{mntnsid: 1}
+	cc.EnrichEventWithWorkload(event)
+	require.Equal(t, "Deployment", event.workloadKind)
+	require.Equal(t, "nginx", event.workloadName)
+
+	// Pods without owner are their own workload
+	event = &fakeWorkloadEvent{mntnsid: 2}
+	cc.EnrichEventWithWorkload(event)
+	require.Equal(t, "Pod", event.workloadKind)
+	require.Equal(t, "debug", event.workloadName)
+
+	// Unresolved owner references are retried in the background, but not on
+	// every event
+	retried := func(n int) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return calls["c3"] == n
+		}
+	}
+	event = &fakeWorkloadEvent{mntnsid: 3}
+	cc.EnrichEventWithWorkload(event)
+	require.Empty(t, event.workloadKind)
+	require.Eventually(t, retried(1), time.Second, 10*time.Millisecond)
+	cc.EnrichEventWithWorkload(&fakeWorkloadEvent{mntnsid: 3})
+	time.Sleep(50 * time.Millisecond)
+	require.True(t, retried(1)())
+
+	cc.ownerReferenceRetriesMu.Lock()
+	cc.ownerReferenceRetries["c3"] = time.Now()
+	cc.ownerReferenceRetriesMu.Unlock()
+	mu.Lock()
+	fail = false
+	mu.Unlock()
+
+	cc.EnrichEventWithWorkload(&fakeWorkloadEvent{mntnsid: 3})
+	require.Eventually(t, retried(2), time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		_, resolved := cc.LookupContainerByMntns(3).cachedOwnerReference()
+		return resolved
+	}, time.Second, 10*time.Millisecond)
+	event = &fakeWorkloadEvent{mntnsid: 3}
+	cc.EnrichEventWithWorkload(event)
+	require.Equal(t, "StatefulSet", event.workloadKind)
+	require.Equal(t, "redis", event.workloadName)
+
+	mu.Lock()
+	require.Equal(t, map[string]int{"c3": 2}, calls)
+	mu.Unlock()
+
+	// Unknown containers aren't enriched
+	event = &fakeWorkloadEvent{mntnsid: 5}
+	cc.EnrichEventWithWorkload(event)
+	require.Empty(t, event.workloadKind)
+
+	// The retry state is removed with the container
+	cc.RemoveContainer("c3")
+	cc.ownerReferenceRetriesMu.Lock()
+	require.NotContains(t, cc.ownerReferenceRetries, "c3")
+	cc.ownerReferenceRetriesMu.Unlock()
+}
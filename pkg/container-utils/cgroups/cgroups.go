@@ -93,3 +93,26 @@ func GetCgroupPaths(pid int) (string, string, error) {
 
 	return cgroupPathV1, cgroupPathV2, nil
 }
+
+// GetSystemdUnit returns the systemd unit (service or scope) a cgroup path
+// belongs to, e.g. "kubelet.service" for "/system.slice/kubelet.service". It
+// returns an empty string if the path isn't managed by systemd.
+func GetSystemdUnit(cgroupPath string) string {
+	elems := strings.Split(cgroupPath, "/")
+	for i := len(elems) - 1; i >= 0; i-- {
+		if strings.HasSuffix(elems[i], ".service") || strings.HasSuffix(elems[i], ".scope") {
+			return elems[i]
+		}
+	}
+	return ""
+}
+
+// IsUnifiedHierarchy returns true if only the cgroup2 hierarchy is mounted,
+// i.e. if "/sys/fs/cgroup" is a cgroup2 file system.
+func IsUnifiedHierarchy() bool {
+	var st unix.Statfs_t
+	if err := unix.Statfs("/sys/fs/cgroup", &st); err != nil {
+		return false
+	}
+	return st.Type == unix.CGROUP2_SUPER_MAGIC
+}
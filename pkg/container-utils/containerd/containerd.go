@@ -20,11 +20,13 @@ import (
 	"time"
 
 	"github.com/containerd/containerd"
+	apievents "github.com/containerd/containerd/api/events"
 	"github.com/containerd/containerd/errdefs"
 	"github.com/containerd/containerd/namespaces"
 	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
 
 	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
 )
@@ -83,6 +85,52 @@ func (c *ContainerdClient) Close() error {
 	return nil
 }
 
+func (c *ContainerdClient) WatchContainerEvents(ctx context.Context, callback func(runtimeclient.ContainerEvent)) error {
+	namespace, _ := namespaces.Namespace(c.ctx)
+	envelopes, errs := c.client.Subscribe(ctx,
+		fmt.Sprintf(`namespace==%q,topic=="/tasks/start"`, namespace),
+		fmt.Sprintf(`namespace==%q,topic=="/tasks/exit"`, namespace),
+	)
+	for {
+		select {
+		case envelope := <-envelopes:
+			if envelope.Event == nil {
+				continue
+			}
+
+			switch envelope.Topic {
+			case "/tasks/start":
+				e := &apievents.TaskStart{}
+				if err := proto.Unmarshal(envelope.Event.GetValue(), e); err != nil {
+					log.Debugf("decoding task start event: %s", err)
+					continue
+				}
+				callback(runtimeclient.ContainerEvent{
+					Type:        runtimeclient.ContainerEventStarted,
+					ContainerID: e.ContainerID,
+				})
+			case "/tasks/exit":
+				e := &apievents.TaskExit{}
+				if err := proto.Unmarshal(envelope.Event.GetValue(), e); err != nil {
+					log.Debugf("decoding task exit event: %s", err)
+					continue
+				}
+				// Exits of processes executed in the container (exec) are
+				// reported with their own ID.
+				if e.ID != e.ContainerID {
+					continue
+				}
+				callback(runtimeclient.ContainerEvent{
+					Type:        runtimeclient.ContainerEventStopped,
+					ContainerID: e.ContainerID,
+				})
+			}
+		case err := <-errs:
+			return fmt.Errorf("watching containerd events: %w", err)
+		}
+	}
+}
+
 func (c *ContainerdClient) GetContainers() ([]*runtimeclient.ContainerData, error) {
 	containers, err := c.client.Containers(c.ctx)
 	if err != nil {
@@ -120,10 +168,11 @@ func (c *ContainerdClient) GetContainer(containerID string) (*runtimeclient.Cont
 		return nil, err
 	}
 
-	labels, err := container.Labels(c.ctx)
+	info, err := container.Info(c.ctx)
 	if err != nil {
-		return nil, fmt.Errorf("listing labels of container %q: %w", container.ID(), err)
+		return nil, fmt.Errorf("getting info of container %q: %w", container.ID(), err)
 	}
+	labels := info.Labels
 
 	// State is getting set to `Created` here for the following reasons:
 	// 1. GetContainer is only getting called on new created containers
@@ -135,6 +184,7 @@ func (c *ContainerdClient) GetContainer(containerID string) (*runtimeclient.Cont
 		State:   runtimeclient.StateCreated,
 		Runtime: runtimeclient.ContainerdName,
 	}
+	c.enrichWithImage(containerData, info.Image, labels)
 	runtimeclient.EnrichWithK8sMetadata(containerData, labels)
 	return containerData, nil
 }
@@ -252,10 +302,11 @@ func (c *ContainerdClient) getContainerTask(container containerd.Container) (*co
 // Constructs a ContainerData from a containerTask and containerd.Container
 // The extra containerd.Container parameter saves an additional call to the API
 func (c *ContainerdClient) taskAndContainerToContainerData(task *containerTask, container containerd.Container) (*runtimeclient.ContainerData, error) {
-	labels, err := container.Labels(c.ctx)
+	info, err := container.Info(c.ctx)
 	if err != nil {
-		return nil, fmt.Errorf("listing labels of container %q: %w", container.ID(), err)
+		return nil, fmt.Errorf("getting info of container %q: %w", container.ID(), err)
 	}
+	labels := info.Labels
 
 	containerData := &runtimeclient.ContainerData{
 		ID:      container.ID(),
@@ -263,10 +314,29 @@ func (c *ContainerdClient) taskAndContainerToContainerData(task *containerTask,
 		State:   task.status,
 		Runtime: runtimeclient.ContainerdName,
 	}
+	c.enrichWithImage(containerData, info.Image, labels)
 	runtimeclient.EnrichWithK8sMetadata(containerData, labels)
 	return containerData, nil
 }
 
+// enrichWithImage sets the image information of the container. The digest
+// requires an additional request to the image service, not finding the image
+// (e.g. because it was removed) is not considered an error.
+func (c *ContainerdClient) enrichWithImage(containerData *runtimeclient.ContainerData, image string, labels map[string]string) {
+	containerData.Image = image
+	containerData.Labels = labels
+
+	if image == "" {
+		return
+	}
+	img, err := c.client.ImageService().Get(c.ctx, image)
+	if err != nil {
+		log.Debugf("getting image %q: %s", image, err)
+		return
+	}
+	containerData.ImageDigest = img.Target.Digest.String()
+}
+
 // Checks if the K8s Label for the Containerkind equals to sandbox
 func (c *ContainerdClient) isSandboxContainer(container containerd.Container) bool {
 	labels, err := container.Labels(c.ctx)
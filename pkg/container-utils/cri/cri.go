@@ -177,6 +177,35 @@ func (c *CRIClient) GetContainerDetails(containerID string) (*runtimeclient.Cont
 	return parseContainerDetailsData(c.Name, res.Status, res.Info)
 }
 
+func (c *CRIClient) WatchContainerEvents(ctx context.Context, callback func(runtimeclient.ContainerEvent)) error {
+	if c.useV1alpha2() {
+		return fmt.Errorf("container events are not supported by CRI v1alpha2 for %s", c.Name)
+	}
+
+	stream, err := c.client.GetContainerEvents(ctx, &runtime.GetEventsRequest{})
+	if err != nil {
+		return fmt.Errorf("getting container events from %s: %w", c.Name, err)
+	}
+
+	for {
+		res, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("receiving container events from %s: %w", c.Name, err)
+		}
+
+		event := runtimeclient.ContainerEvent{ContainerID: res.GetContainerId()}
+		switch res.GetContainerEventType() {
+		case runtime.ContainerEventType_CONTAINER_STARTED_EVENT:
+			event.Type = runtimeclient.ContainerEventStarted
+		case runtime.ContainerEventType_CONTAINER_STOPPED_EVENT:
+			event.Type = runtimeclient.ContainerEventStopped
+		default:
+			continue
+		}
+		callback(event)
+	}
+}
+
 func (c *CRIClient) Close() error {
 	if c.conn != nil {
 		return c.conn.Close()
@@ -197,10 +226,13 @@ func parseContainerDetailsData(runtimeName string, containerStatus *runtime.Cont
 	// Create container details structure to be filled.
 	containerDetailsData := &runtimeclient.ContainerDetailsData{
 		ContainerData: runtimeclient.ContainerData{
-			ID:      containerStatus.Id,
-			Name:    strings.TrimPrefix(containerStatus.GetMetadata().Name, "/"),
-			State:   containerStatusStateToRuntimeClientState(containerStatus.GetState()),
-			Runtime: runtimeName,
+			ID:          containerStatus.Id,
+			Name:        strings.TrimPrefix(containerStatus.GetMetadata().Name, "/"),
+			State:       containerStatusStateToRuntimeClientState(containerStatus.GetState()),
+			Runtime:     runtimeName,
+			Image:       containerStatus.GetImage().GetImage(),
+			ImageDigest: imageDigest(containerStatus.GetImageRef()),
+			Labels:      containerStatus.Labels,
 		},
 	}
 
@@ -330,12 +362,25 @@ func containerStatusStateToRuntimeClientState(containerStatusState runtime.Conta
 	return
 }
 
+// imageDigest returns the digest of an image reference like
+// "docker.io/library/nginx@sha256:...". References without digest (e.g. image
+// IDs) are returned as they are.
+func imageDigest(imageRef string) string {
+	if _, digest, ok := strings.Cut(imageRef, "@"); ok {
+		return digest
+	}
+	return imageRef
+}
+
 func CRIContainerToContainerData(runtimeName string, container *runtime.Container) *runtimeclient.ContainerData {
 	containerData := &runtimeclient.ContainerData{
-		ID:      container.Id,
-		Name:    strings.TrimPrefix(container.GetMetadata().Name, "/"),
-		State:   containerStatusStateToRuntimeClientState(container.GetState()),
-		Runtime: runtimeName,
+		ID:          container.Id,
+		Name:        strings.TrimPrefix(container.GetMetadata().Name, "/"),
+		State:       containerStatusStateToRuntimeClientState(container.GetState()),
+		Runtime:     runtimeName,
+		Image:       container.GetImage().GetImage(),
+		ImageDigest: imageDigest(container.GetImageRef()),
+		Labels:      container.Labels,
 	}
 
 	// Fill K8S information.
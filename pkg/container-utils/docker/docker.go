@@ -160,10 +160,13 @@ func (c *DockerClient) GetContainerDetails(containerID string) (*runtimeclient.C
 
 	containerDetailsData := runtimeclient.ContainerDetailsData{
 		ContainerData: runtimeclient.ContainerData{
-			ID:      containerJSON.ID,
-			Name:    strings.TrimPrefix(containerJSON.Name, "/"),
-			State:   containerStatusStateToRuntimeClientState(containerJSON.State.Status),
-			Runtime: runtimeclient.DockerName,
+			ID:          containerJSON.ID,
+			Name:        strings.TrimPrefix(containerJSON.Name, "/"),
+			State:       containerStatusStateToRuntimeClientState(containerJSON.State.Status),
+			Runtime:     runtimeclient.DockerName,
+			Image:       containerJSON.Config.Image,
+			ImageDigest: containerJSON.Image,
+			Labels:      containerJSON.Config.Labels,
 		},
 		Pid:         containerJSON.State.Pid,
 		CgroupsPath: string(containerJSON.HostConfig.Cgroup),
@@ -205,6 +208,48 @@ func (c *DockerClient) GetContainerDetails(containerID string) (*runtimeclient.C
 	return &containerDetailsData, nil
 }
 
+func (c *DockerClient) WatchContainerEvents(ctx context.Context, callback func(runtimeclient.ContainerEvent)) error {
+	// The client used for the other requests has a timeout that would
+	// interrupt the stream of events, so use a dedicated one.
+	cli, err := client.NewClientWithOpts(
+		client.WithAPIVersionNegotiation(),
+		client.WithHost("unix://"+c.socketPath),
+	)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	filter := dockerfilters.NewArgs()
+	filter.Add("type", "container")
+	filter.Add("event", "start")
+	filter.Add("event", "die")
+
+	msgs, errs := cli.Events(ctx, dockertypes.EventsOptions{Filters: filter})
+	for {
+		select {
+		case msg := <-msgs:
+			// See listContainers() about pod sandbox containers.
+			if msg.Actor.Attributes["io.kubernetes.docker.type"] == "podsandbox" {
+				continue
+			}
+
+			event := runtimeclient.ContainerEvent{ContainerID: msg.Actor.ID}
+			switch msg.Action {
+			case "start":
+				event.Type = runtimeclient.ContainerEventStarted
+			case "die":
+				event.Type = runtimeclient.ContainerEventStopped
+			default:
+				continue
+			}
+			callback(event)
+		case err := <-errs:
+			return fmt.Errorf("watching docker events: %w", err)
+		}
+	}
+}
+
 func (c *DockerClient) Close() error {
 	if c.client != nil {
 		return c.client.Close()
@@ -232,10 +277,13 @@ func containerStatusStateToRuntimeClientState(containerState string) (runtimeCli
 
 func DockerContainerToContainerData(container *dockertypes.Container) *runtimeclient.ContainerData {
 	containerData := &runtimeclient.ContainerData{
-		ID:      container.ID,
-		Name:    strings.TrimPrefix(container.Names[0], "/"),
-		State:   containerStatusStateToRuntimeClientState(container.State),
-		Runtime: runtimeclient.DockerName,
+		ID:          container.ID,
+		Name:        strings.TrimPrefix(container.Names[0], "/"),
+		State:       containerStatusStateToRuntimeClientState(container.State),
+		Runtime:     runtimeclient.DockerName,
+		Image:       container.Image,
+		ImageDigest: container.ImageID,
+		Labels:      container.Labels,
 	}
 
 	// Fill K8S information.
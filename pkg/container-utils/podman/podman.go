@@ -32,6 +32,7 @@ const (
 	defaultConnectionTimeout = 2 * time.Second
 	containerListAllURL      = "http://d/v4.0.0/libpod/containers/json?all=true"
 	containerInspectURL      = "http://d/v4.0.0/libpod/containers/%s/json"
+	eventsURL                = "http://d/v4.0.0/libpod/events?stream=true"
 )
 
 type PodmanClient struct {
@@ -76,9 +77,12 @@ func (p *PodmanClient) listContainers(containerID string) ([]*runtimeclient.Cont
 	}
 
 	var containers []struct {
-		ID    string   `json:"Id"`
-		Names []string `json:"Names"`
-		State string   `json:"State"`
+		ID      string            `json:"Id"`
+		Names   []string          `json:"Names"`
+		State   string            `json:"State"`
+		Image   string            `json:"Image"`
+		ImageID string            `json:"ImageID"`
+		Labels  map[string]string `json:"Labels"`
 	}
 	if err = json.NewDecoder(resp.Body).Decode(&containers); err != nil {
 		return nil, fmt.Errorf("decoding containers: %w", err)
@@ -87,10 +91,13 @@ func (p *PodmanClient) listContainers(containerID string) ([]*runtimeclient.Cont
 	ret := make([]*runtimeclient.ContainerData, len(containers))
 	for i, c := range containers {
 		ret[i] = &runtimeclient.ContainerData{
-			ID:      c.ID,
-			Name:    c.Names[0],
-			State:   containerStatusStateToRuntimeClientState(c.State),
-			Runtime: runtimeclient.PodmanName,
+			ID:          c.ID,
+			Name:        c.Names[0],
+			State:       containerStatusStateToRuntimeClientState(c.State),
+			Runtime:     runtimeclient.PodmanName,
+			Image:       c.Image,
+			ImageDigest: c.ImageID,
+			Labels:      c.Labels,
 		}
 	}
 	return ret, nil
@@ -127,13 +134,18 @@ func (p *PodmanClient) GetContainerDetails(containerID string) (*runtimeclient.C
 	}
 
 	var container struct {
-		ID    string `json:"Id"`
-		Name  string `json:"Name"`
-		State struct {
+		ID        string `json:"Id"`
+		Name      string `json:"Name"`
+		Image     string `json:"Image"`
+		ImageName string `json:"ImageName"`
+		State     struct {
 			Status     string `json:"Status"`
 			Pid        int    `json:"Pid"`
 			CgroupPath string `json:"CgroupPath"`
 		} `json:"State"`
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"Config"`
 	}
 
 	if err = json.NewDecoder(resp.Body).Decode(&container); err != nil {
@@ -142,16 +154,80 @@ func (p *PodmanClient) GetContainerDetails(containerID string) (*runtimeclient.C
 
 	return &runtimeclient.ContainerDetailsData{
 		ContainerData: runtimeclient.ContainerData{
-			ID:      container.ID,
-			Name:    container.Name,
-			State:   containerStatusStateToRuntimeClientState(container.State.Status),
-			Runtime: runtimeclient.PodmanName,
+			ID:          container.ID,
+			Name:        container.Name,
+			State:       containerStatusStateToRuntimeClientState(container.State.Status),
+			Runtime:     runtimeclient.PodmanName,
+			Image:       container.ImageName,
+			ImageDigest: container.Image,
+			Labels:      container.Config.Labels,
 		},
 		Pid:         container.State.Pid,
 		CgroupsPath: container.State.CgroupPath,
 	}, nil
 }
 
+func (p *PodmanClient) WatchContainerEvents(ctx context.Context, callback func(runtimeclient.ContainerEvent)) error {
+	f, err := json.Marshal(map[string][]string{
+		"type":  {"container"},
+		"event": {"start", "died"},
+	})
+	if err != nil {
+		return fmt.Errorf("setting up filters: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, eventsURL+"&filters="+url.QueryEscape(string(f)), nil)
+	if err != nil {
+		return fmt.Errorf("creating events request: %w", err)
+	}
+
+	// The stream of events is long-lived: don't use the client timeout.
+	client := http.Client{Transport: p.client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("getting events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("getting events via rest api: %s", resp.Status)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var e struct {
+			Type   string `json:"Type"`
+			Action string `json:"Action"`
+			Status string `json:"Status"`
+			Actor  struct {
+				ID string `json:"ID"`
+			} `json:"Actor"`
+		}
+		if err := decoder.Decode(&e); err != nil {
+			return fmt.Errorf("decoding event: %w", err)
+		}
+		if e.Type != "container" {
+			continue
+		}
+
+		action := e.Action
+		if action == "" {
+			action = e.Status
+		}
+
+		event := runtimeclient.ContainerEvent{ContainerID: e.Actor.ID}
+		switch action {
+		case "start":
+			event.Type = runtimeclient.ContainerEventStarted
+		case "died", "die":
+			event.Type = runtimeclient.ContainerEventStopped
+		default:
+			continue
+		}
+		callback(event)
+	}
+}
+
 func (p *PodmanClient) Close() error {
 	return nil
 }
@@ -15,6 +15,7 @@
 package runtimeclient
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
@@ -64,6 +65,18 @@ type ContainerData struct {
 
 	// Namespace of the pod running the container.
 	PodNamespace string
+
+	// Image is the image reference used to create the container (e.g.
+	// docker.io/library/nginx:1.25), as reported by the runtime.
+	Image string
+
+	// ImageDigest is the digest of the image. It's the repository digest
+	// when the runtime provides it, otherwise the image ID.
+	ImageDigest string
+
+	// Labels of the container. Some runtimes (e.g. Docker and Podman) also
+	// include the labels of the image.
+	Labels map[string]string
 }
 
 // ContainerDetailsData contains container extra information returned from the
@@ -132,6 +145,47 @@ type ContainerRuntimeClient interface {
 	Close() error
 }
 
+// ContainerEventType is the type of a container lifecycle event reported by a
+// container runtime.
+type ContainerEventType int
+
+const (
+	// ContainerEventStarted is reported when the container process started.
+	ContainerEventStarted ContainerEventType = iota
+
+	// ContainerEventStopped is reported when the container process exited.
+	ContainerEventStopped
+)
+
+func (t ContainerEventType) String() string {
+	switch t {
+	case ContainerEventStarted:
+		return "started"
+	case ContainerEventStopped:
+		return "stopped"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(t))
+	}
+}
+
+// ContainerEvent is a container lifecycle event reported by a container
+// runtime.
+type ContainerEvent struct {
+	Type ContainerEventType
+
+	// ContainerID is the container ID without the container runtime prefix.
+	ContainerID string
+}
+
+// ContainerEventsClient is implemented by the container runtime clients able
+// to stream container lifecycle events.
+type ContainerEventsClient interface {
+	// WatchContainerEvents calls callback for each container event until ctx
+	// is cancelled or the connection with the container runtime is lost. It
+	// always returns a non-nil error.
+	WatchContainerEvents(ctx context.Context, callback func(ContainerEvent)) error
+}
+
 func ParseContainerID(expectedRuntime, containerID string) (string, error) {
 	// If ID contains a prefix, it must match the format "<runtime>://<ID>"
 	split := strings.SplitN(containerID, "://", 2)
@@ -146,6 +200,26 @@ func ParseContainerID(expectedRuntime, containerID string) (string, error) {
 	return split[0], nil
 }
 
+// ParseImageReference splits an image reference like
+// "registry:5000/repo/nginx:1.25@sha256:..." into its name, tag and digest
+// parts. A reference that is only a digest (e.g. an image ID as reported by
+// some runtimes) is returned as digest.
+func ParseImageReference(image string) (name, tag, digest string) {
+	if strings.HasPrefix(image, "sha256:") {
+		return "", "", image
+	}
+
+	name, digest, _ = strings.Cut(image, "@")
+
+	// The tag is after the last colon, unless that colon separates the
+	// registry host and port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	return name, tag, digest
+}
+
 func EnrichWithK8sMetadata(container *ContainerData, labels map[string]string) {
 	if podName, ok := labels[containerLabelK8sPodName]; ok {
 		container.PodName = podName
@@ -39,7 +39,10 @@ import (
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/kubeipresolver"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/kubemanager"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/kubenameresolver"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/otellogs"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/processtree"
 	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/prometheus"
+	_ "github.com/inspektor-gadget/inspektor-gadget/pkg/operators/ratelimit"
 )
 
 type Config struct {
@@ -134,6 +137,15 @@ func (s *Service) RunGadget(runGadget pb.GadgetManager_RunGadgetServer) error {
 		}
 	}
 
+	// Apply filters that the client wants to have pushed down to the gadget; the local runtime will take care of
+	// the rest
+	if kernelFilters := gadgets.KernelFiltersFromMap(request.Params); parser != nil && len(kernelFilters) > 0 {
+		err = parser.SetFilters(kernelFilters)
+		if err != nil {
+			return fmt.Errorf("setting filters: %w", err)
+		}
+	}
+
 	// Create payload buffer
 	outputBuffer := make(chan *pb.GadgetEvent, 1024) // TODO: Discuss 1024
 
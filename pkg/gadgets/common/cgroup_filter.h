@@ -0,0 +1,54 @@
+/* SPDX-License-Identifier: (GPL-2.0 WITH Linux-syscall-note) OR Apache-2.0 */
+
+#ifndef CGROUP_FILTER_H
+#define CGROUP_FILTER_H
+
+#include <bpf/bpf_helpers.h>
+#include <bpf/bpf_core_read.h>
+#include <bpf/bpf_tracing.h>
+
+#include "mntns_filter.h"
+
+// Filtering by cgroup ID is an alternative to filtering by mount namespace
+// (see mntns_filter.h) for processes that share or change mount namespaces,
+// like host services, and for network events handled in softirq context,
+// where the current task is unrelated to the packet.
+
+const volatile bool gadget_filter_by_cgroup = false;
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__type(key, __u64);
+	__type(value, __u32);
+	__uint(max_entries, 1024);
+} gadget_cgroup_filter_map SEC(".maps");
+
+// gadget_should_discard_cgroup_id returns true if events generated from the given cgroup_id should
+// not be taken into consideration.
+static __always_inline bool gadget_should_discard_cgroup_id(__u64 cgroup_id) {
+	return gadget_filter_by_cgroup && !bpf_map_lookup_elem(&gadget_cgroup_filter_map, &cgroup_id);
+}
+
+// gadget_get_cgroup_id returns the cgroup v2 ID of the current task.
+static __always_inline __u64 gadget_get_cgroup_id() {
+	return bpf_get_current_cgroup_id();
+}
+
+// gadget_should_discard_current_task returns true if events generated by the
+// current task, whose mount namespace is mntns_id, should not be taken into
+// consideration. Tasks are kept if either their cgroup or their mount namespace
+// is selected: host services share the mount namespace of the host, and
+// containers without cgroup ID are only in the mount namespace map.
+static __always_inline bool gadget_should_discard_current_task(__u64 mntns_id) {
+	if (gadget_filter_by_cgroup && !gadget_should_discard_cgroup_id(gadget_get_cgroup_id()))
+		return false;
+	return gadget_should_discard_mntns_id(mntns_id);
+}
+
+// gadget_get_skb_cgroup_id returns the cgroup v2 ID of the socket owning the
+// skb. It's only available to tc and cgroup skb programs.
+static __always_inline __u64 gadget_get_skb_cgroup_id(struct __sk_buff *skb) {
+	return bpf_skb_cgroup_id(skb);
+}
+
+#endif
@@ -31,6 +31,7 @@ import (
 	"github.com/cilium/ebpf/link"
 	"golang.org/x/sys/unix"
 
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/cgroups"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
 )
 
@@ -53,6 +54,14 @@ const (
 	// Name of the map that stores the mount namespace inode id to filter on.
 	// Keep in syn with name used in pkg/gadgets/common/mntns_filter.h.
 	MntNsFilterMapName = "gadget_mntns_filter_map"
+
+	// Constant used to enable filtering by cgroup ID in eBPF.
+	// Keep in sync with variable defined in pkg/gadgets/common/cgroup_filter.h.
+	FilterByCgroupName = "gadget_filter_by_cgroup"
+
+	// Name of the map that stores the cgroup IDs to filter on.
+	// Keep in sync with name used in pkg/gadgets/common/cgroup_filter.h.
+	CgroupFilterMapName = "gadget_cgroup_filter_map"
 )
 
 // CloseLink closes l if it's not nil and returns nil
@@ -274,6 +283,30 @@ func LoadeBPFSpec(
 	spec *ebpf.CollectionSpec,
 	consts map[string]interface{},
 	objs interface{},
+) error {
+	return loadeBPFSpec(mountnsMap, nil, false, spec, consts, objs)
+}
+
+// LoadeBPFSpecWithCgroupFilter is like LoadeBPFSpec but for gadgets including
+// pkg/gadgets/common/cgroup_filter.h: it also replaces the cgroup filter map
+// and enables filtering by cgroup ID when cgroupMap is set.
+func LoadeBPFSpecWithCgroupFilter(
+	mountnsMap *ebpf.Map,
+	cgroupMap *ebpf.Map,
+	spec *ebpf.CollectionSpec,
+	consts map[string]interface{},
+	objs interface{},
+) error {
+	return loadeBPFSpec(mountnsMap, cgroupMap, true, spec, consts, objs)
+}
+
+func loadeBPFSpec(
+	mountnsMap *ebpf.Map,
+	cgroupMap *ebpf.Map,
+	hasCgroupFilter bool,
+	spec *ebpf.CollectionSpec,
+	consts map[string]interface{},
+	objs interface{},
 ) error {
 	FixBpfKtimeGetBootNs(spec.Programs)
 
@@ -291,6 +324,22 @@ func LoadeBPFSpec(
 
 	consts[FilterByMntNsName] = filterByMntNs
 
+	if hasCgroupFilter {
+		// Rewriting a missing constant fails, only objects including
+		// cgroup_filter.h have it
+		if _, ok := spec.Maps[CgroupFilterMapName]; ok {
+			// In hybrid mode, containers could share the cgroup v2 of
+			// their runtime, only filter by cgroup ID with the unified
+			// hierarchy
+			filterByCgroup := false
+			if cgroupMap != nil && cgroups.IsUnifiedHierarchy() {
+				filterByCgroup = true
+				mapReplacements[CgroupFilterMapName] = cgroupMap
+			}
+			consts[FilterByCgroupName] = filterByCgroup
+		}
+	}
+
 	if err := spec.RewriteConstants(consts); err != nil {
 		return fmt.Errorf("rewriting constants: %w", err)
 	}
@@ -15,6 +15,7 @@
 package gadgets
 
 import (
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
@@ -78,6 +79,12 @@ type GadgetDescSkipParams interface {
 	SkipParams() []params.ValueHint
 }
 
+// GadgetColorRules can be implemented by gadgets to highlight events (e.g. failed calls) when printing colorized
+// columns
+type GadgetColorRules interface {
+	ColorRules() []columns.ColorRule
+}
+
 type OutputFormats map[string]OutputFormat
 
 // OutputFormat can hold alternative output formats for a gadget. Whenever
@@ -141,6 +148,20 @@ type EventEnricherSetter interface {
 	SetEventEnricher(func(ev any) error)
 }
 
+// GadgetKernelFilters can be implemented by gadgets that are able to filter events by the value of some of their
+// columns in eBPF already, so that those events don't have to be sent to user space at all.
+type GadgetKernelFilters interface {
+	// KernelFilterColumns returns the names of the columns that can be filtered in eBPF
+	KernelFilterColumns() []string
+}
+
+// KernelFiltersSetter is implemented by gadget instances of gadgets implementing GadgetKernelFilters. filters maps
+// column names to the exact value events need to have in that column. SetKernelFilters is called before the gadget
+// is run. Filters are still applied in user space afterwards, so gadgets can ignore filters they can't handle.
+type KernelFiltersSetter interface {
+	SetKernelFilters(filters map[string]string) error
+}
+
 // RunGadget is an interface that will be implemented by gadgets that are run in
 // the background and emit events as soon as they occur.
 type RunGadget interface {
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"syscall"
 	"unsafe"
 
@@ -66,6 +67,11 @@ type Tracer[Event any] struct {
 	baseEvent    func(ev types.Event) *Event
 	processEvent func(rawSample []byte, netns uint64) (*Event, error)
 	eventHandler func(ev *Event)
+
+	// netnsFilter, if set, is the only network namespace the tracer attaches to
+	netnsFilter uint64
+	// skipped contains the pids that weren't attached because of netnsFilter
+	skipped map[uint32]struct{}
 }
 
 func (t *Tracer[Event]) newAttachment(
@@ -130,6 +136,7 @@ func NewTracer[Event any](
 
 	t := &Tracer[Event]{
 		attachments:  make(map[uint64]*attachment),
+		skipped:      make(map[uint32]struct{}),
 		baseEvent:    baseEvent,
 		processEvent: processEvent,
 	}
@@ -192,6 +199,10 @@ func (t *Tracer[Event]) Attach(pid uint32) error {
 	if err != nil {
 		return fmt.Errorf("getting network namespace of pid %d: %w", pid, err)
 	}
+	if t.netnsFilter != 0 && netns != t.netnsFilter {
+		t.skipped[pid] = struct{}{}
+		return nil
+	}
 	if a, ok := t.attachments[netns]; ok {
 		a.users[pid] = struct{}{}
 		return nil
@@ -206,6 +217,29 @@ func (t *Tracer[Event]) Attach(pid uint32) error {
 	return nil
 }
 
+// NetNsFilterColumn is the column network gadgets can filter on before events reach userspace: the tracer doesn't
+// attach to network namespaces other than the requested one
+const NetNsFilterColumn = "netns"
+
+// ParseNetNsFilter returns the network namespace requested by a kernel filter on NetNsFilterColumn, or 0 if there
+// is none
+func ParseNetNsFilter(filters map[string]string) (uint64, error) {
+	value, ok := filters[NetNsFilterColumn]
+	if !ok {
+		return 0, nil
+	}
+	netns, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for filter on column %q: %w", value, NetNsFilterColumn, err)
+	}
+	return netns, nil
+}
+
+// SetNetNsFilter makes the tracer ignore Attach() calls for processes in a network namespace other than netns
+func (t *Tracer[Event]) SetNetNsFilter(netns uint64) {
+	t.netnsFilter = netns
+}
+
 func (t *Tracer[Event]) SetEventHandler(handler any) {
 	if t.eventHandler != nil {
 		panic("handler already set")
@@ -289,6 +323,10 @@ func (t *Tracer[Event]) releaseAttachment(netns uint64, a *attachment) {
 }
 
 func (t *Tracer[Event]) Detach(pid uint32) error {
+	if _, ok := t.skipped[pid]; ok {
+		delete(t.skipped, pid)
+		return nil
+	}
 	for netns, a := range t.attachments {
 		if _, ok := a.users[pid]; ok {
 			delete(a.users, pid)
@@ -16,6 +16,7 @@ package gadgets
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
@@ -133,3 +134,35 @@ func ParamsToMap(
 	runtimeParams.CopyToMap(paramMap, "runtime.")
 	operatorParams.CopyToMap(paramMap, "operator.")
 }
+
+// KernelFiltersPrefix is the prefix used to store filters that should be pushed down to the gadget (see
+// GadgetKernelFilters) in a param map
+const KernelFiltersPrefix = "kernelfilter."
+
+// KernelFiltersToMap adds the given filters (column name to value) to the paramMap
+func KernelFiltersToMap(paramMap map[string]string, filters map[string]string) {
+	for columnName, value := range filters {
+		paramMap[KernelFiltersPrefix+columnName] = value
+	}
+}
+
+// KernelFiltersFromMap returns the filters stored in paramMap by KernelFiltersToMap as filter rules in the form
+// "columnName:value"
+func KernelFiltersFromMap(paramMap map[string]string) []string {
+	var filters []string
+	for k, v := range paramMap {
+		if strings.HasPrefix(k, KernelFiltersPrefix) {
+			filters = append(filters, strings.TrimPrefix(k, KernelFiltersPrefix)+":"+v)
+		}
+	}
+	return filters
+}
+
+// ParseKernelFilterID parses the value of a kernel filter on a column holding an id like a pid or an uid
+func ParseKernelFilterID(columnName string, value string) (uint32, error) {
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q for filter on column %q: %w", value, columnName, err)
+	}
+	return uint32(id), nil
+}
@@ -23,7 +23,10 @@ import (
 )
 
 const (
-	ParamConfig = "config"
+	ParamConfig       = "config"
+	ParamConfigFile   = "config-file"
+	ParamConfigMap    = "config-map"
+	ParamConfigMapKey = "config-map-key"
 )
 
 type fakeEvent struct{}
@@ -52,13 +55,31 @@ func (g *GadgetDesc) ParamDescs() params.ParamDescs {
 			Key:         ParamConfig,
 			Title:       "config",
 			Description: "Metrics configuration (prefix with @ to load from a file)",
-			IsMandatory: true,
 			TypeHint:    params.TypeBytes,
 			Validator: func(value string) error {
+				if value == "" {
+					return nil
+				}
 				_, err := igprometheus.ParseConfig([]byte(value))
 				return err
 			},
 		},
+		{
+			Key:         ParamConfigFile,
+			Title:       "config file",
+			Description: "Path of the metrics configuration; the file is watched and metrics are updated when it changes",
+		},
+		{
+			Key:         ParamConfigMap,
+			Title:       "config map",
+			Description: "Name of a ConfigMap in the namespace of the gadget pod holding the metrics configuration; metrics are updated when it changes",
+		},
+		{
+			Key:          ParamConfigMapKey,
+			Title:        "config map key",
+			DefaultValue: "config.yaml",
+			Description:  "Key of the metrics configuration in the ConfigMap",
+		},
 	}
 }
 
@@ -17,36 +17,77 @@
 package tracer
 
 import (
-	"go.opentelemetry.io/otel/metric"
+	"errors"
+	"fmt"
+	"os"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	igprometheus "github.com/inspektor-gadget/inspektor-gadget/pkg/prometheus"
 )
 
+const defaultNamespace = "gadget"
+
 type Tracer struct {
-	meterProvider metric.MeterProvider
+	newMeterProvider igprometheus.MeterProviderFactory
 }
 
 func (t *Tracer) Run(gadgetCtx gadgets.GadgetContext) error {
 	ctx := gadgetCtx.Context()
+	logger := gadgetCtx.Logger()
 
 	params := gadgetCtx.GadgetParams()
 	metricsConfig := params.Get(ParamConfig).AsBytes()
+	configFile := params.Get(ParamConfigFile).AsString()
+	configMap := params.Get(ParamConfigMap).AsString()
 
-	config, err := igprometheus.ParseConfig(metricsConfig)
-	if err != nil {
-		return err
+	sources := 0
+	for _, source := range []string{string(metricsConfig), configFile, configMap} {
+		if source != "" {
+			sources++
+		}
 	}
+	if sources != 1 {
+		return errors.New("exactly one of config, config-file and config-map needs to be set")
+	}
+
+	manager := igprometheus.NewManager(ctx, t.newMeterProvider, logger)
+	defer manager.Close()
 
-	gadgetCtx.Logger().Debugf("config: %+v", config)
+	apply := func(data []byte) error {
+		config, err := igprometheus.ParseConfig(data)
+		if err != nil {
+			return fmt.Errorf("parsing config: %w", err)
+		}
+		logger.Debugf("config: %+v", config)
+		return manager.Apply(config)
+	}
+
+	switch {
+	case configFile != "":
+		changed, err := watchFile(ctx, configFile, logger)
+		if err != nil {
+			return err
+		}
+		return reloadOnChange(ctx, logger, changed, func() ([]byte, error) {
+			return os.ReadFile(configFile)
+		}, apply)
+	case configMap != "":
+		namespace := os.Getenv("TRACELOOP_POD_NAMESPACE")
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+		changed, read, err := watchConfigMap(ctx, namespace, configMap, params.Get(ParamConfigMapKey).AsString())
+		if err != nil {
+			return err
+		}
+		return reloadOnChange(ctx, logger, changed, read, apply)
+	}
 
-	cleanup, err := igprometheus.CreateMetrics(ctx, config, t.meterProvider)
-	if err != nil {
+	if err := apply(metricsConfig); err != nil {
 		return err
 	}
-	defer cleanup()
 
-	gadgetCtx.Logger().Info("Publishing metrics...")
+	logger.Info("Publishing metrics...")
 
 	<-ctx.Done()
 
@@ -57,6 +98,6 @@ func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
 	return &Tracer{}, nil
 }
 
-func (t *Tracer) SetMetricsProvider(meter metric.MeterProvider) {
-	t.meterProvider = meter
+func (t *Tracer) SetMeterProviderFactory(newMeterProvider igprometheus.MeterProviderFactory) {
+	t.newMeterProvider = newMeterProvider
 }
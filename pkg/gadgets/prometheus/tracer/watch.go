@@ -0,0 +1,158 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !withoutebpf
+
+package tracer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/k8sutil"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+// reloadOnChange applies the configuration returned by read and applies it again every time it changes. Errors
+// applying the initial configuration are returned, later ones are only logged and keep the current metrics running.
+func reloadOnChange(
+	ctx context.Context,
+	logger logger.Logger,
+	changed <-chan struct{},
+	read func() ([]byte, error),
+	apply func([]byte) error,
+) error {
+	data, err := read()
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	if err := apply(data); err != nil {
+		return err
+	}
+
+	logger.Info("Publishing metrics...")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+
+		newData, err := read()
+		if err != nil {
+			logger.Warnf("reading config: %v; keeping current metrics", err)
+			continue
+		}
+		if bytes.Equal(newData, data) {
+			continue
+		}
+		data = newData
+
+		logger.Info("Config changed, updating metrics...")
+		if err := apply(data); err != nil {
+			logger.Warnf("updating metrics: %v", err)
+		}
+	}
+}
+
+func notify(changed chan struct{}) {
+	select {
+	case changed <- struct{}{}:
+	default:
+	}
+}
+
+// watchFile notifies about changes of the given file. The directory of the file is watched instead of the file itself
+// to also catch files that are replaced, like the ones of ConfigMaps mounted as volumes.
+func watchFile(ctx context.Context, path string, logger logger.Logger) (<-chan struct{}, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("watching %q: %w", path, err)
+	}
+
+	changed := make(chan struct{}, 1)
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				notify(changed)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Warnf("watching %q: %v", path, err)
+			}
+		}
+	}()
+
+	return changed, nil
+}
+
+// watchConfigMap notifies about changes of the given ConfigMap and returns a function to read the configuration from
+// it
+func watchConfigMap(ctx context.Context, namespace, name, key string) (<-chan struct{}, func() ([]byte, error), error) {
+	clientset, err := k8sutil.NewClientset("")
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating clientset: %w", err)
+	}
+
+	changed := make(chan struct{}, 1)
+	listWatcher := cache.NewListWatchFromClient(clientset.CoreV1().RESTClient(), "configmaps", namespace,
+		fields.OneTermEqualSelector("metadata.name", name))
+	store, controller := cache.NewInformer(listWatcher, &v1.ConfigMap{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { notify(changed) },
+		UpdateFunc: func(oldObj, newObj any) { notify(changed) },
+		DeleteFunc: func(obj any) { notify(changed) },
+	})
+	go controller.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), controller.HasSynced) {
+		return nil, nil, fmt.Errorf("waiting for ConfigMap %s/%s", namespace, name)
+	}
+
+	read := func() ([]byte, error) {
+		obj, exists, err := store.GetByKey(namespace + "/" + name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("ConfigMap %s/%s not found", namespace, name)
+		}
+		data, ok := obj.(*v1.ConfigMap).Data[key]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found in ConfigMap %s/%s", key, namespace, name)
+		}
+		return []byte(data), nil
+	}
+
+	return changed, read, nil
+}
@@ -50,6 +50,7 @@ type Config struct {
 	ProgLocation string
 	ProgContent  []byte
 	MountnsMap   *ebpf.Map
+	CgroupMap    *ebpf.Map
 }
 
 type Tracer struct {
@@ -162,6 +163,11 @@ func (t *Tracer) installTracer() error {
 			mapReplacements[gadgets.MntNsFilterMapName] = t.config.MountnsMap
 			consts[gadgets.FilterByMntNsName] = true
 		}
+		// Replace filter cgroup map
+		if m.Name == gadgets.CgroupFilterMapName && t.config.CgroupMap != nil {
+			mapReplacements[gadgets.CgroupFilterMapName] = t.config.CgroupMap
+			consts[gadgets.FilterByCgroupName] = true
+		}
 	}
 
 	if err := t.spec.RewriteConstants(consts); err != nil {
@@ -348,6 +354,10 @@ func (t *Tracer) SetMountNsMap(mountnsMap *ebpf.Map) {
 	t.config.MountnsMap = mountnsMap
 }
 
+func (t *Tracer) SetCgroupIDMap(cgroupMap *ebpf.Map) {
+	t.config.CgroupMap = cgroupMap
+}
+
 func (t *Tracer) SetEventHandler(handler any) {
 	nh, ok := handler.(func(ev *types.Event))
 	if !ok {
@@ -40,7 +40,7 @@ type Stats struct {
 	Write      bool   `json:"write,omitempty" column:"r/w,maxWidth:3"`
 	Major      int    `json:"major,omitempty" column:"major"`
 	Minor      int    `json:"minor,omitempty" column:"minor"`
-	Bytes      uint64 `json:"bytes,omitempty" column:"bytes"`
+	Bytes      uint64 `json:"bytes,omitempty" column:"bytes,unit:bytes"`
 	MicroSecs  uint64 `json:"us,omitempty" column:"time"`
 	Operations uint32 `json:"ops,omitempty" column:"ops"`
 }
@@ -39,13 +39,13 @@ type Stats struct {
 	Type               string     `json:"type,omitempty" column:"type"`
 	Name               string     `json:"name,omitempty" column:"name"`
 	Processes          []*Process `json:"processes,omitempty"`
-	CurrentRuntime     int64      `json:"currentRuntime,omitempty" column:"runtime,order:1001,align:right"`
+	CurrentRuntime     int64      `json:"currentRuntime,omitempty" column:"runtime,order:1001,align:right,unit:ns"`
 	CurrentRunCount    uint64     `json:"currentRunCount,omitempty" column:"runcount,order:1002,width:10"`
-	CumulativeRuntime  int64      `json:"cumulRuntime,omitempty" column:"cumulruntime,order:1003,hide"`
+	CumulativeRuntime  int64      `json:"cumulRuntime,omitempty" column:"cumulruntime,order:1003,hide,unit:ns"`
 	CumulativeRunCount uint64     `json:"cumulRunCount,omitempty" column:"cumulruncount,order:1004,hide"`
-	TotalRuntime       int64      `json:"totalRuntime,omitempty" column:"totalruntime,order:1005,align:right,hide"`
+	TotalRuntime       int64      `json:"totalRuntime,omitempty" column:"totalruntime,order:1005,align:right,hide,unit:ns"`
 	TotalRunCount      uint64     `json:"totalRunCount,omitempty" column:"totalRunCount,order:1006,align:right,hide"`
-	MapMemory          uint64     `json:"mapMemory,omitempty" column:"mapmemory,order:1007,align:right"`
+	MapMemory          uint64     `json:"mapMemory,omitempty" column:"mapmemory,order:1007,align:right,unit:bytes"`
 	MapCount           uint32     `json:"mapCount,omitempty" column:"mapcount,order:1008"`
 	TotalCpuUsage      float64    `json:"totalCpuUsage,omitempty" column:"totalcpu,order:1009,align:right,hide"`
 	PerCpuUsage        float64    `json:"perCpuUsage,omitempty" column:"percpu,order:1010,align:right,hide"`
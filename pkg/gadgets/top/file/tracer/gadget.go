@@ -56,6 +56,10 @@ func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Stats](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{"pid"}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Stats{}
 }
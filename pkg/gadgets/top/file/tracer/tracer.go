@@ -269,6 +269,17 @@ func (t *Tracer) SetEventHandlerArray(handler any) {
 	}
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	if value, ok := filters["pid"]; ok {
+		pid, err := gadgets.ParseKernelFilterID("pid", value)
+		if err != nil {
+			return err
+		}
+		t.config.TargetPid = int(pid)
+	}
+	return nil
+}
+
 func (t *Tracer) SetMountNsMap(mntnsMap *ebpf.Map) {
 	t.config.MountnsMap = mntnsMap
 }
@@ -43,8 +43,8 @@ type Stats struct {
 	Comm       string `json:"comm,omitempty" column:"comm,template:comm"`
 	Reads      uint64 `json:"reads,omitempty" column:"reads"`
 	Writes     uint64 `json:"writes,omitempty" column:"writes"`
-	ReadBytes  uint64 `json:"rbytes,omitempty" column:"rbytes"`
-	WriteBytes uint64 `json:"wbytes,omitempty" column:"wbytes"`
+	ReadBytes  uint64 `json:"rbytes,omitempty" column:"rbytes,unit:bytes"`
+	WriteBytes uint64 `json:"wbytes,omitempty" column:"wbytes,unit:bytes"`
 	FileType   byte   `json:"fileType,omitempty" column:"T,maxWidth:1"` // R = Regular File, S = Socket, O = Other
 	Filename   string `json:"filename,omitempty" column:"file"`
 }
@@ -55,6 +55,9 @@ type Tracer struct {
 	eventCallback      func(*top.Event[types.Stats])
 	done               chan bool
 	colMap             columns.ColumnMap[types.Stats]
+
+	// pid filter pushed down by the runtime; it is used if the pid parameter is not set
+	kernelFilterPid int32
 }
 
 func NewTracer(config *Config, enricher gadgets.DataEnricherByMntNs,
@@ -279,6 +282,17 @@ func (t *Tracer) SetEventHandlerArray(handler any) {
 	}
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	if value, ok := filters["pid"]; ok {
+		pid, err := gadgets.ParseKernelFilterID("pid", value)
+		if err != nil {
+			return err
+		}
+		t.kernelFilterPid = int32(pid)
+	}
+	return nil
+}
+
 func (t *Tracer) SetMountNsMap(mntnsMap *ebpf.Map) {
 	t.config.MountnsMap = mntnsMap
 }
@@ -301,6 +315,9 @@ func (t *Tracer) init(gadgetCtx gadgets.GadgetContext) error {
 	t.config.Interval = time.Second * time.Duration(params.Get(gadgets.ParamInterval).AsInt())
 	t.config.TargetFamily, _ = types.ParseFilterByFamily(params.Get(types.FamilyParam).AsString())
 	t.config.TargetPid = params.Get(types.PidParam).AsInt32()
+	if t.config.TargetPid == 0 {
+		t.config.TargetPid = t.kernelFilterPid
+	}
 
 	var err error
 	if t.config.Iterations, err = top.ComputeIterations(t.config.Interval, gadgetCtx.Timeout()); err != nil {
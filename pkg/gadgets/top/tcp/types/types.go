@@ -54,8 +54,8 @@ type Stats struct {
 	SrcEndpoint eventtypes.L4Endpoint `json:"src,omitempty" column:"src"`
 	DstEndpoint eventtypes.L4Endpoint `json:"dst,omitempty" column:"dst"`
 
-	Sent     uint64 `json:"sent,omitempty" column:"sent,order:1002"`
-	Received uint64 `json:"received,omitempty" column:"recv,order:1003"`
+	Sent     uint64 `json:"sent,omitempty" column:"sent,order:1002,unit:bytes"`
+	Received uint64 `json:"received,omitempty" column:"recv,order:1003,unit:bytes"`
 }
 
 func (e *Stats) GetEndpoints() []*eventtypes.L3Endpoint {
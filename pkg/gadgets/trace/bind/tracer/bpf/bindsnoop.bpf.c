@@ -6,7 +6,7 @@
 #include <bpf/bpf_tracing.h>
 #include <bpf/bpf_endian.h>
 #include "bindsnoop.h"
-#include "mntns_filter.h"
+#include "cgroup_filter.h"
 
 #define MAX_ENTRIES	10240
 #define MAX_PORTS	1024
@@ -72,7 +72,7 @@ static int probe_exit(struct pt_regs *ctx, short ver)
 
 	mntns_id = gadget_get_mntns_id();
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		goto cleanup;
 
 	ret = PT_REGS_RC(ctx);
@@ -77,6 +77,10 @@ func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Event](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{"pid", "port"}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Event{}
 }
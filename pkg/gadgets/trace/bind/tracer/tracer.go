@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"unsafe"
 
 	"github.com/cilium/ebpf"
@@ -37,6 +38,7 @@ import (
 
 type Config struct {
 	MountnsMap   *ebpf.Map
+	CgroupMap    *ebpf.Map
 	TargetPid    int32
 	TargetPorts  []uint16
 	IgnoreErrors bool
@@ -47,6 +49,10 @@ type Tracer struct {
 	enricher      gadgets.DataEnricherByMntNs
 	eventCallback func(*types.Event)
 
+	// filters pushed down by the runtime; they are used if the respective parameters are not set
+	kernelFilterPid  int32
+	kernelFilterPort uint16
+
 	objs      bindsnoopObjects
 	ipv4Entry link.Link
 	ipv4Exit  link.Link
@@ -115,7 +121,7 @@ func (t *Tracer) install() error {
 		"ignore_errors":  t.config.IgnoreErrors,
 	}
 
-	if err := gadgets.LoadeBPFSpec(t.config.MountnsMap, spec, consts, &t.objs); err != nil {
+	if err := gadgets.LoadeBPFSpecWithCgroupFilter(t.config.MountnsMap, t.config.CgroupMap, spec, consts, &t.objs); err != nil {
 		return fmt.Errorf("loading ebpf spec: %w", err)
 	}
 
@@ -283,6 +289,12 @@ func (t *Tracer) Run(gadgetCtx gadgets.GadgetContext) error {
 	t.config.TargetPid = params.Get(ParamPID).AsInt32()
 	t.config.TargetPorts = params.Get(ParamPorts).AsUint16Slice()
 	t.config.IgnoreErrors = params.Get(ParamIgnoreErrors).AsBool()
+	if t.config.TargetPid == 0 {
+		t.config.TargetPid = t.kernelFilterPid
+	}
+	if len(t.config.TargetPorts) == 0 && t.kernelFilterPort != 0 {
+		t.config.TargetPorts = []uint16{t.kernelFilterPort}
+	}
 
 	defer t.close()
 	if err := t.install(); err != nil {
@@ -301,6 +313,10 @@ func (t *Tracer) SetMountNsMap(mountnsMap *ebpf.Map) {
 	t.config.MountnsMap = mountnsMap
 }
 
+func (t *Tracer) SetCgroupIDMap(cgroupMap *ebpf.Map) {
+	t.config.CgroupMap = cgroupMap
+}
+
 func (t *Tracer) SetEventHandler(handler any) {
 	nh, ok := handler.(func(ev *types.Event))
 	if !ok {
@@ -309,6 +325,26 @@ func (t *Tracer) SetEventHandler(handler any) {
 	t.eventCallback = nh
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	for columnName, value := range filters {
+		switch columnName {
+		case "pid":
+			pid, err := gadgets.ParseKernelFilterID(columnName, value)
+			if err != nil {
+				return err
+			}
+			t.kernelFilterPid = int32(pid)
+		case "port":
+			port, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return fmt.Errorf("invalid value %q for filter on column %q: %w", value, columnName, err)
+			}
+			t.kernelFilterPort = uint16(port)
+		}
+	}
+	return nil
+}
+
 func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
 	tracer := &Tracer{
 		config: &Config{},
@@ -10,7 +10,7 @@
 #include <bpf/bpf_helpers.h>
 #include <bpf/bpf_tracing.h>
 #include "capable.h"
-#include "mntns_filter.h"
+#include "cgroup_filter.h"
 
 // include/linux/security.h
 #ifndef CAP_OPT_NOAUDIT
@@ -90,7 +90,7 @@ int BPF_KPROBE(ig_trace_cap_e, const struct cred *cred, struct user_namespace *t
 	task = (struct task_struct*) bpf_get_current_task();
 	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		return 0;
 
 	const struct cred *real_cred = BPF_CORE_READ(task, real_cred);
@@ -225,7 +225,7 @@ int ig_cap_sys_enter(struct bpf_raw_tracepoint_args *ctx)
 
 	u64 mntns_id = gadget_get_mntns_id();
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		return 0;
 
 	u64 nr = ctx->args[1];
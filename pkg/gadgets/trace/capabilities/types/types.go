@@ -35,6 +35,7 @@ const (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Pid           uint32   `json:"pid,omitempty" column:"pid,template:pid"`
 	Comm          string   `json:"comm,omitempty" column:"comm,template:comm"`
@@ -52,6 +53,10 @@ type Event struct {
 	CapsNames     []string `json:"capsNames,omitempty" column:"capsnames,hide"`
 }
 
+func (e *Event) GetPid() uint32 {
+	return e.Pid
+}
+
 func GetColumns() *columns.Columns[Event] {
 	cols := columns.MustCreateColumns[Event]()
 
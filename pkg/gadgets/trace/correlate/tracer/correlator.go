@@ -0,0 +1,234 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/correlate/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+// source holds the events of one of the correlated gadgets
+type source struct {
+	name string
+
+	key   func(any) int64
+	mntns func(any) int64
+	netns func(any) int64
+
+	columns []string
+	values  func(any) []string
+
+	// latest holds the last event seen for each key
+	latest map[uint64]sourceEntry
+}
+
+type sourceEntry struct {
+	// seen is the timestamp of the event, or the time it was received if
+	// the gadget didn't set it
+	seen   time.Time
+	mntns  uint64
+	netns  uint64
+	values []string
+}
+
+// newSource uses the parser of a gadget to get the correlation key, the
+// namespaces and the visible columns of its events.
+func newSource(name string, p parser.Parser, key string) (*source, error) {
+	keyGetter, err := p.ColIntGetter(key)
+	if err != nil {
+		return nil, fmt.Errorf("gadget %q can't be correlated by %q: %w", name, key, err)
+	}
+
+	s := &source{
+		name:   name,
+		key:    keyGetter,
+		latest: make(map[uint64]sourceEntry),
+	}
+
+	// Not all gadgets have both namespaces
+	s.mntns, _ = p.ColIntGetter("mntns")
+	s.netns, _ = p.ColIntGetter("netns")
+
+	skip := map[string]struct{}{key: {}, "timestamp": {}, "mntns": {}, "netns": {}}
+columnsLoop:
+	for _, attrs := range p.GetColumnAttributes() {
+		if !attrs.Visible {
+			continue
+		}
+		if _, ok := skip[attrs.Name]; ok {
+			continue
+		}
+		// The container information is added to the joined record
+		for _, tag := range attrs.Tags {
+			if tag == "kubernetes" || tag == "runtime" {
+				continue columnsLoop
+			}
+		}
+		s.columns = append(s.columns, attrs.Name)
+	}
+
+	s.values, err = p.ValuesFunc(s.columns)
+	if err != nil {
+		return nil, fmt.Errorf("getting columns of gadget %q: %w", name, err)
+	}
+
+	return s, nil
+}
+
+func (s *source) entry(ev any, now time.Time) sourceEntry {
+	entry := sourceEntry{
+		seen:   now,
+		values: s.values(ev),
+	}
+	// Join on the time the event happened rather than the time it was
+	// received, gadgets don't deliver their events with the same delay
+	if e, ok := ev.(interface{ GetBaseEvent() *eventtypes.Event }); ok {
+		if ts := e.GetBaseEvent().Timestamp; ts != 0 {
+			entry.seen = time.Unix(0, int64(ts))
+		}
+	}
+	if s.mntns != nil {
+		entry.mntns = uint64(s.mntns(ev))
+	}
+	if s.netns != nil {
+		entry.netns = uint64(s.netns(ev))
+	}
+	return entry
+}
+
+// correlator joins the events of several sources. The events of the last
+// source trigger a joined record with the latest events of the other sources
+// having the same key, if they were seen within the window.
+type correlator struct {
+	mu sync.Mutex
+
+	sources []*source
+	window  time.Duration
+	// partial allows joined records missing the events of some sources
+	partial bool
+	emit    func(*types.Event)
+
+	now         func() time.Time
+	lastCleanup time.Time
+}
+
+func newCorrelator(sources []*source, window time.Duration, partial bool, emit func(*types.Event)) *correlator {
+	return &correlator{
+		sources: sources,
+		window:  window,
+		partial: partial,
+		emit:    emit,
+		now:     time.Now,
+	}
+}
+
+// add handles an event of the i-th source
+func (c *correlator) add(i int, ev any) {
+	src := c.sources[i]
+	key := uint64(src.key(ev))
+	if key == 0 {
+		return
+	}
+
+	record := c.addEntry(i, key, src.entry(ev, c.now()))
+	if record != nil {
+		c.emit(record)
+	}
+}
+
+func (c *correlator) addEntry(i int, key uint64, entry sourceEntry) *types.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cleanup(entry.seen)
+
+	last := len(c.sources) - 1
+	if i < last {
+		c.sources[i].latest[key] = entry
+		return nil
+	}
+
+	record := &types.Event{
+		Event: eventtypes.Event{
+			Type:      eventtypes.NORMAL,
+			Timestamp: eventtypes.Time(entry.seen.UnixNano()),
+		},
+		Key:    key,
+		Fields: make(map[string]string),
+	}
+
+	var summaries []string
+	join := func(s *source, e sourceEntry) {
+		record.Gadgets = append(record.Gadgets, s.name)
+
+		var fields []string
+		for k, col := range s.columns {
+			record.Fields[s.name+"."+col] = e.values[k]
+			if e.values[k] != "" {
+				fields = append(fields, col+"="+e.values[k])
+			}
+		}
+		summaries = append(summaries, s.name+": "+strings.Join(fields, " "))
+
+		if e.mntns != 0 {
+			record.MountNsID = e.mntns
+		}
+		if e.netns != 0 {
+			record.NetNsID = e.netns
+		}
+	}
+
+	for _, s := range c.sources[:last] {
+		e, ok := s.latest[key]
+		if !ok || entry.seen.Sub(e.seen) > c.window {
+			if !c.partial {
+				return nil
+			}
+			continue
+		}
+		join(s, e)
+	}
+	if len(record.Gadgets) == 0 {
+		// Nothing to correlate with
+		return nil
+	}
+	// The namespaces of the triggering event take precedence
+	join(c.sources[last], entry)
+
+	record.Summary = strings.Join(summaries, " | ")
+	return record
+}
+
+// cleanup removes the events older than the window, at most once per window
+func (c *correlator) cleanup(now time.Time) {
+	if now.Sub(c.lastCleanup) < c.window {
+		return
+	}
+	c.lastCleanup = now
+
+	for _, s := range c.sources {
+		for key, e := range s.latest {
+			if now.Sub(e.seen) > c.window {
+				delete(s.latest, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/correlate/types"
+	dnstypes "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/dns/types"
+	exectypes "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/exec/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+func newTestCorrelator(t *testing.T, partial bool) (*correlator, *time.Time, *[]*types.Event) {
+	execSource, err := newSource("exec", parser.NewParser[exectypes.Event](exectypes.GetColumns()), "pid")
+	require.Nil(t, err)
+	dnsSource, err := newSource("dns", parser.NewParser[dnstypes.Event](dnstypes.GetColumns()), "pid")
+	require.Nil(t, err)
+
+	var records []*types.Event
+	c := newCorrelator([]*source{execSource, dnsSource}, 5*time.Second, partial, func(ev *types.Event) {
+		records = append(records, ev)
+	})
+
+	now := time.Unix(1000, 0)
+	c.now = func() time.Time { return now }
+
+	return c, &now, &records
+}
+
+func execEvent(pid uint32) *exectypes.Event {
+	return &exectypes.Event{
+		WithMountNsID: eventtypes.WithMountNsID{MountNsID: 1},
+		Pid:           pid,
+		Comm:          "curl",
+		Args:          []string{"curl", "example.com"},
+	}
+}
+
+func dnsEvent(pid uint32) *dnstypes.Event {
+	return &dnstypes.Event{
+		WithNetNsID: eventtypes.WithNetNsID{NetNsID: 2},
+		Pid:         pid,
+		Comm:        "curl",
+		DNSName:     "example.com.",
+	}
+}
+
+func TestCorrelatorJoin(t *testing.T) {
+	c, now, records := newTestCorrelator(t, false)
+
+	c.add(0, execEvent(42))
+	*now = now.Add(time.Second)
+	c.add(1, dnsEvent(42))
+
+	require.Len(t, *records, 1)
+	record := (*records)[0]
+	require.Equal(t, uint64(42), record.Key)
+	require.Equal(t, []string{"exec", "dns"}, record.Gadgets)
+	require.Equal(t, uint64(1), record.MountNsID)
+	require.Equal(t, uint64(2), record.NetNsID)
+	require.Equal(t, "curl", record.Fields["exec.comm"])
+	require.Equal(t, "example.com.", record.Fields["dns.name"])
+	require.Contains(t, record.Summary, "exec: ")
+	require.Contains(t, record.Summary, " | dns: ")
+	require.NotContains(t, record.Fields, "exec.pid")
+}
+
+func TestCorrelatorNoMatch(t *testing.T) {
+	c, now, records := newTestCorrelator(t, false)
+
+	// Only the last gadget triggers records
+	c.add(1, dnsEvent(42))
+	c.add(0, execEvent(42))
+	require.Empty(t, *records)
+
+	// Different key
+	c.add(1, dnsEvent(43))
+	require.Empty(t, *records)
+
+	// Outside of the window
+	*now = now.Add(6 * time.Second)
+	c.add(1, dnsEvent(42))
+	require.Empty(t, *records)
+}
+
+func TestCorrelatorPartial(t *testing.T) {
+	c, _, records := newTestCorrelator(t, true)
+
+	// Nothing to join with, even if partial records are allowed
+	c.add(1, dnsEvent(42))
+	require.Empty(t, *records)
+
+	// Three gadgets with the one in the middle missing
+	c.sources = append([]*source{c.sources[0]}, c.sources...)
+	c.sources[1] = &source{
+		name:   "other",
+		key:    c.sources[0].key,
+		values: func(any) []string { return nil },
+		latest: make(map[uint64]sourceEntry),
+	}
+
+	c.add(0, execEvent(42))
+	c.add(2, dnsEvent(42))
+	require.Len(t, *records, 1)
+	require.Equal(t, []string{"exec", "dns"}, (*records)[0].Gadgets)
+}
+
+func TestCorrelatorEventTimestamp(t *testing.T) {
+	c, now, records := newTestCorrelator(t, false)
+
+	// Events are joined on their own timestamp, not on when they're received
+	exec := execEvent(42)
+	exec.Timestamp = eventtypes.Time(now.Add(-20 * time.Second).UnixNano())
+	dns := dnsEvent(42)
+	dns.Timestamp = eventtypes.Time(now.Add(-17 * time.Second).UnixNano())
+	c.add(0, exec)
+	c.add(1, dns)
+
+	require.Len(t, *records, 1)
+	require.Equal(t, dns.Timestamp, (*records)[0].Timestamp)
+
+	// Received within the window, but happened outside of it
+	exec = execEvent(43)
+	exec.Timestamp = eventtypes.Time(now.Add(-10 * time.Second).UnixNano())
+	c.add(0, exec)
+	c.add(1, dnsEvent(43))
+	require.Len(t, *records, 1)
+}
+
+func TestCorrelatorCleanup(t *testing.T) {
+	c, now, _ := newTestCorrelator(t, false)
+
+	c.add(0, execEvent(42))
+	require.Len(t, c.sources[0].latest, 1)
+
+	*now = now.Add(6 * time.Second)
+	c.add(0, execEvent(43))
+	require.Len(t, c.sources[0].latest, 1)
+	require.Contains(t, c.sources[0].latest, uint64(43))
+}
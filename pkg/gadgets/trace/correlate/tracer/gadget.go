@@ -0,0 +1,98 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/correlate/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+)
+
+const (
+	ParamGadgets      = "gadgets"
+	ParamGadgetParams = "gadget-params"
+	ParamWindow       = "window"
+	ParamKey          = "key"
+	ParamPartial      = "partial"
+)
+
+type GadgetDesc struct{}
+
+func (g *GadgetDesc) Name() string {
+	return "correlate"
+}
+
+func (g *GadgetDesc) Category() string {
+	return gadgets.CategoryTrace
+}
+
+func (g *GadgetDesc) Type() gadgets.GadgetType {
+	return gadgets.TypeTrace
+}
+
+func (g *GadgetDesc) Description() string {
+	return "Join the events of several trace gadgets sharing the same process or namespace within a time window"
+}
+
+func (g *GadgetDesc) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{
+		{
+			Key:         ParamGadgets,
+			Title:       "Gadgets",
+			Description: "Comma-separated list of trace gadgets to correlate, e.g. exec,tcpconnect,dns. Events of the last gadget trigger the joined records. Gadgets of other categories can be given as category/name",
+			IsMandatory: true,
+		},
+		{
+			Key:         ParamGadgetParams,
+			Title:       "Gadget parameters",
+			Description: "Comma-separated list of parameters of the correlated gadgets, given as gadget.key=value, e.g. dns.latency=true",
+		},
+		{
+			Key:          ParamWindow,
+			Title:        "Window",
+			Description:  "Maximum time between the events of the first gadgets and the event triggering the joined record",
+			DefaultValue: "5s",
+			TypeHint:     params.TypeDuration,
+		},
+		{
+			Key:            ParamKey,
+			Title:          "Key",
+			Description:    "Column events are correlated on",
+			DefaultValue:   "pid",
+			PossibleValues: []string{"pid", "mntns", "netns"},
+		},
+		{
+			Key:          ParamPartial,
+			Title:        "Partial",
+			Description:  "Emit joined records even if some of the gadgets didn't have any event for the key",
+			DefaultValue: "false",
+			TypeHint:     params.TypeBool,
+		},
+	}
+}
+
+func (g *GadgetDesc) Parser() parser.Parser {
+	return parser.NewParser[types.Event](types.GetColumns())
+}
+
+func (g *GadgetDesc) EventPrototype() any {
+	return &types.Event{}
+}
+
+func init() {
+	gadgetregistry.Register(&GadgetDesc{})
+}
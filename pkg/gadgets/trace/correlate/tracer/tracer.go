@@ -0,0 +1,345 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cilium/ebpf"
+	"golang.org/x/sync/errgroup"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/correlate/types"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+// child is one of the gadgets run by the correlate gadget
+type child struct {
+	desc     gadgets.GadgetDesc
+	instance gadgets.Gadget
+	ctx      *childContext
+}
+
+// childContext is the context of a child gadget. It shares everything with
+// the context of the correlate gadget but the parameters, which are the ones
+// given for the child gadget in --gadget-params or its defaults.
+type childContext struct {
+	gadgets.GadgetContext
+	ctx    context.Context
+	params *params.Params
+}
+
+func (c *childContext) Context() context.Context {
+	return c.ctx
+}
+
+func (c *childContext) GadgetParams() *params.Params {
+	return c.params
+}
+
+type Tracer struct {
+	children      []*child
+	correlator    *correlator
+	eventCallback func(*types.Event)
+	enricher      func(any) error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
+	return &Tracer{}, nil
+}
+
+// parseGadgets returns the category and name of the gadgets given as
+// "name" or "category/name"
+func parseGadgets(list []string) ([][2]string, error) {
+	if len(list) < 2 {
+		return nil, errors.New("at least two gadgets are needed")
+	}
+
+	seen := make(map[string]struct{})
+	ret := make([][2]string, 0, len(list))
+	for _, entry := range list {
+		category, name, found := strings.Cut(entry, "/")
+		if !found {
+			category, name = gadgets.CategoryTrace, entry
+		}
+		if name == "correlate" {
+			return nil, errors.New("correlate can't correlate itself")
+		}
+		if _, ok := seen[name]; ok {
+			return nil, fmt.Errorf("gadget %q given twice", name)
+		}
+		seen[name] = struct{}{}
+		ret = append(ret, [2]string{category, name})
+	}
+	return ret, nil
+}
+
+// parseGadgetParams returns the parameters given as "gadget.key=value" by
+// gadget name
+func parseGadgetParams(list []string) (map[string]map[string]string, error) {
+	ret := make(map[string]map[string]string)
+	for _, entry := range list {
+		keyValue, value, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid gadget parameter %q: expected gadget.key=value", entry)
+		}
+		name, key, found := strings.Cut(keyValue, ".")
+		if !found || name == "" || key == "" {
+			return nil, fmt.Errorf("invalid gadget parameter %q: expected gadget.key=value", entry)
+		}
+		if ret[name] == nil {
+			ret[name] = make(map[string]string)
+		}
+		ret[name][key] = value
+	}
+	return ret, nil
+}
+
+// childParams returns the parameters of a child gadget, set to the given
+// values
+func childParams(desc gadgets.GadgetDesc, values map[string]string) (*params.Params, error) {
+	p := desc.ParamDescs().ToParams()
+	for key, value := range values {
+		if err := p.Set(key, value); err != nil {
+			return nil, fmt.Errorf("setting parameter %q of gadget %q: %w", key, desc.Name(), err)
+		}
+	}
+	return p, nil
+}
+
+func (t *Tracer) Init(gadgetCtx gadgets.GadgetContext) error {
+	gadgetParams := gadgetCtx.GadgetParams()
+
+	list, err := parseGadgets(gadgetParams.Get(ParamGadgets).AsStringSlice())
+	if err != nil {
+		return err
+	}
+
+	gadgetParamValues, err := parseGadgetParams(gadgetParams.Get(ParamGadgetParams).AsStringSlice())
+	if err != nil {
+		return err
+	}
+	names := make(map[string]struct{}, len(list))
+	for _, entry := range list {
+		names[entry[1]] = struct{}{}
+	}
+	for name := range gadgetParamValues {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("parameters given for gadget %q, which isn't correlated", name)
+		}
+	}
+
+	key := gadgetParams.Get(ParamKey).AsString()
+
+	t.ctx, t.cancel = context.WithCancel(gadgetCtx.Context())
+
+	sources := make([]*source, 0, len(list))
+	for i, entry := range list {
+		desc := gadgetregistry.Get(entry[0], entry[1])
+		if desc == nil {
+			t.Close()
+			return fmt.Errorf("gadget %s/%s not found", entry[0], entry[1])
+		}
+		if desc.Type() != gadgets.TypeTrace {
+			t.Close()
+			return fmt.Errorf("gadget %q is not a trace gadget", desc.Name())
+		}
+
+		gi, ok := desc.(gadgets.GadgetInstantiate)
+		if !ok {
+			t.Close()
+			return fmt.Errorf("gadget %q can't be instantiated", desc.Name())
+		}
+		instance, err := gi.NewInstance()
+		if err != nil {
+			t.Close()
+			return fmt.Errorf("instantiating gadget %q: %w", desc.Name(), err)
+		}
+		if _, ok := instance.(gadgets.RunGadget); !ok {
+			t.Close()
+			return fmt.Errorf("gadget %q can't be run", desc.Name())
+		}
+
+		childGadgetParams, err := childParams(desc, gadgetParamValues[entry[1]])
+		if err != nil {
+			t.Close()
+			return err
+		}
+
+		c := &child{
+			desc:     desc,
+			instance: instance,
+			ctx: &childContext{
+				GadgetContext: gadgetCtx,
+				ctx:           t.ctx,
+				params:        childGadgetParams,
+			},
+		}
+
+		if initClose, ok := instance.(gadgets.InitCloseGadget); ok {
+			if err := initClose.Init(c.ctx); err != nil {
+				t.Close()
+				return fmt.Errorf("initializing gadget %q: %w", desc.Name(), err)
+			}
+		}
+		// Add it only once initialized, so Close() doesn't close it if it
+		// wasn't
+		t.children = append(t.children, c)
+
+		p := desc.Parser()
+		if p == nil {
+			t.Close()
+			return fmt.Errorf("gadget %q has no parser", desc.Name())
+		}
+		src, err := newSource(desc.Name(), p, key)
+		if err != nil {
+			t.Close()
+			return err
+		}
+		sources = append(sources, src)
+
+		setter, ok := instance.(gadgets.EventHandlerSetter)
+		if !ok {
+			t.Close()
+			return fmt.Errorf("gadget %q doesn't emit events", desc.Name())
+		}
+		i := i
+		p.SetEventCallback(func(ev any) {
+			t.correlator.add(i, ev)
+		})
+		setter.SetEventHandler(p.EventHandlerFunc(t.enrich))
+	}
+
+	t.correlator = newCorrelator(
+		sources,
+		gadgetParams.Get(ParamWindow).AsDuration(),
+		gadgetParams.Get(ParamPartial).AsBool(),
+		func(ev *types.Event) {
+			t.eventCallback(ev)
+		},
+	)
+
+	return nil
+}
+
+func (t *Tracer) Run(gadgetCtx gadgets.GadgetContext) error {
+	var g errgroup.Group
+	for _, c := range t.children {
+		c := c
+		g.Go(func() error {
+			err := c.instance.(gadgets.RunGadget).Run(c.ctx)
+			if err != nil {
+				// Stop the other gadgets as well
+				t.cancel()
+				return fmt.Errorf("running gadget %q: %w", c.desc.Name(), err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+func (t *Tracer) Close() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	for _, c := range t.children {
+		if initClose, ok := c.instance.(gadgets.InitCloseGadget); ok {
+			initClose.Close()
+		}
+	}
+	t.children = nil
+}
+
+func (t *Tracer) SetEventHandler(handler any) {
+	nh, ok := handler.(func(ev *types.Event))
+	if !ok {
+		panic("event handler invalid")
+	}
+	t.eventCallback = nh
+}
+
+// SetEventEnricher sets the function used to pass the events of the gadgets
+// through the operators, e.g. to add the container information, before
+// correlating them
+func (t *Tracer) SetEventEnricher(enricher func(ev any) error) {
+	t.enricher = enricher
+}
+
+func (t *Tracer) enrich(ev any) error {
+	if t.enricher == nil {
+		return nil
+	}
+	return t.enricher(ev)
+}
+
+// SetMountNsMap forwards the map to the gadgets filtering by mount namespace
+func (t *Tracer) SetMountNsMap(mountnsMap *ebpf.Map) {
+	for _, c := range t.children {
+		if setter, ok := c.instance.(interface{ SetMountNsMap(*ebpf.Map) }); ok {
+			setter.SetMountNsMap(mountnsMap)
+		}
+	}
+}
+
+// SetCgroupIDMap forwards the map to the gadgets filtering by cgroup ID
+func (t *Tracer) SetCgroupIDMap(cgroupMap *ebpf.Map) {
+	for _, c := range t.children {
+		if setter, ok := c.instance.(interface{ SetCgroupIDMap(*ebpf.Map) }); ok {
+			setter.SetCgroupIDMap(cgroupMap)
+		}
+	}
+}
+
+type attacher interface {
+	AttachContainer(container *containercollection.Container) error
+	DetachContainer(*containercollection.Container) error
+}
+
+// AttachContainer attaches the container to the gadgets that need it, e.g.
+// the ones tracing network namespaces
+func (t *Tracer) AttachContainer(container *containercollection.Container) error {
+	for _, c := range t.children {
+		if a, ok := c.instance.(attacher); ok {
+			if err := a.AttachContainer(container); err != nil {
+				return fmt.Errorf("attaching container to gadget %q: %w", c.desc.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *Tracer) DetachContainer(container *containercollection.Container) error {
+	var errs []string
+	for _, c := range t.children {
+		if a, ok := c.instance.(attacher); ok {
+			if err := a.DetachContainer(container); err != nil {
+				errs = append(errs, fmt.Sprintf("gadget %q: %s", c.desc.Name(), err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("detaching container: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
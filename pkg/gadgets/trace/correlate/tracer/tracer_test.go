@@ -0,0 +1,53 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGadgetParams(t *testing.T) {
+	values, err := parseGadgetParams([]string{"dns.latency=true", "signal.pid=42", "signal.failed-only=true", "exec.args="})
+	require.Nil(t, err)
+	require.Equal(t, map[string]map[string]string{
+		"dns":    {"latency": "true"},
+		"signal": {"pid": "42", "failed-only": "true"},
+		"exec":   {"args": ""},
+	}, values)
+
+	for _, entry := range []string{"latency=true", "dns.latency", ".latency=true", "dns.=true"} {
+		_, err := parseGadgetParams([]string{entry})
+		require.Error(t, err, entry)
+	}
+}
+
+func TestChildParams(t *testing.T) {
+	desc := &GadgetDesc{}
+
+	p, err := childParams(desc, map[string]string{ParamWindow: "2s"})
+	require.Nil(t, err)
+	require.Equal(t, 2*time.Second, p.Get(ParamWindow).AsDuration())
+	// Other parameters keep their defaults
+	require.Equal(t, "pid", p.Get(ParamKey).AsString())
+
+	_, err = childParams(desc, map[string]string{"unknown": "1"})
+	require.Error(t, err)
+
+	_, err = childParams(desc, map[string]string{ParamPartial: "maybe"})
+	require.Error(t, err)
+}
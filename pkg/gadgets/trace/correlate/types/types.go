@@ -0,0 +1,60 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"strings"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+// Event is a record joining the events of several gadgets sharing the same
+// correlation key within a time window.
+type Event struct {
+	eventtypes.Event
+	eventtypes.WithMountNsID
+	eventtypes.WithNetNsID
+
+	// Key is the value of the column events were correlated on, e.g. a pid
+	Key uint64 `json:"key" column:"key,minWidth:7,maxWidth:20"`
+
+	// Gadgets are the gadgets whose events were joined, in the order they
+	// were given
+	Gadgets []string `json:"gadgets" column:"gadgets,width:24"`
+
+	// Summary is a short description of each joined event
+	Summary string `json:"summary,omitempty" column:"summary,width:80,ellipsis:end"`
+
+	// Fields holds the columns of the joined events, prefixed with the name
+	// of their gadget, e.g. "dns.name"
+	Fields map[string]string `json:"fields,omitempty" column:"fields,hide"`
+}
+
+func GetColumns() *columns.Columns[Event] {
+	correlateColumns := columns.MustCreateColumns[Event]()
+
+	correlateColumns.MustSetExtractor("gadgets", func(event *Event) string {
+		return strings.Join(event.Gadgets, ",")
+	})
+
+	return correlateColumns
+}
+
+func Base(ev eventtypes.Event) *Event {
+	return &Event{
+		Event: ev,
+	}
+}
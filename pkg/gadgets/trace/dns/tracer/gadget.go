@@ -15,8 +15,10 @@
 package tracer
 
 import (
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
 	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/internal/networktracer"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/dns/types"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
@@ -44,10 +46,21 @@ func (g *GadgetDesc) ParamDescs() params.ParamDescs {
 	return nil
 }
 
+func (g *GadgetDesc) ColorRules() []columns.ColorRule {
+	return []columns.ColorRule{
+		{Filter: "rcode:NXDomain", Column: "rcode", Color: columns.ColorRed},
+		{Filter: "rcode:~^(FormErr|ServFail|NotImp|Refused|UNKNOWN)$", Column: "rcode", Color: columns.ColorYellow},
+	}
+}
+
 func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Event](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{networktracer.NetNsFilterColumn}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Event{}
 }
@@ -6,7 +6,7 @@
 #include <bpf/bpf_tracing.h>
 #endif /* __TARGET_ARCH_arm64 */
 #include "execsnoop.h"
-#include "mntns_filter.h"
+#include "cgroup_filter.h"
 
 const volatile bool ignore_failed = true;
 const volatile uid_t targ_uid = INVALID_UID;
@@ -53,7 +53,7 @@ int ig_execve_e(struct trace_event_raw_sys_enter* ctx)
 	task = (struct task_struct*)bpf_get_current_task();
 	mntns_id = (u64) BPF_CORE_READ(task, nsproxy, mnt_ns, ns.inum);
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		return 0;
 
 	id = bpf_get_current_pid_tgid();
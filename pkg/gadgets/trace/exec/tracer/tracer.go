@@ -36,6 +36,10 @@ import (
 
 type Config struct {
 	MountnsMap *ebpf.Map
+	CgroupMap  *ebpf.Map
+
+	TargetUid   uint32
+	FilterByUid bool
 }
 
 type Tracer struct {
@@ -91,7 +95,12 @@ func (t *Tracer) install() error {
 		return fmt.Errorf("loading ebpf program: %w", err)
 	}
 
-	if err := gadgets.LoadeBPFSpec(t.config.MountnsMap, spec, nil, &t.objs); err != nil {
+	consts := map[string]interface{}{}
+	if t.config.FilterByUid {
+		consts["targ_uid"] = t.config.TargetUid
+	}
+
+	if err := gadgets.LoadeBPFSpecWithCgroupFilter(t.config.MountnsMap, t.config.CgroupMap, spec, consts, &t.objs); err != nil {
 		return fmt.Errorf("loading ebpf spec: %w", err)
 	}
 
@@ -192,6 +201,10 @@ func (t *Tracer) SetMountNsMap(mountnsMap *ebpf.Map) {
 	t.config.MountnsMap = mountnsMap
 }
 
+func (t *Tracer) SetCgroupIDMap(cgroupMap *ebpf.Map) {
+	t.config.CgroupMap = cgroupMap
+}
+
 func (t *Tracer) SetEventHandler(handler any) {
 	nh, ok := handler.(func(ev *types.Event))
 	if !ok {
@@ -200,6 +213,18 @@ func (t *Tracer) SetEventHandler(handler any) {
 	t.eventCallback = nh
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	if value, ok := filters["uid"]; ok {
+		uid, err := gadgets.ParseKernelFilterID("uid", value)
+		if err != nil {
+			return err
+		}
+		t.config.TargetUid = uid
+		t.config.FilterByUid = true
+	}
+	return nil
+}
+
 func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
 	tracer := &Tracer{
 		config: &Config{},
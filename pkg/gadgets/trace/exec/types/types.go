@@ -24,6 +24,7 @@ import (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Pid       uint32   `json:"pid,omitempty" column:"pid,template:pid"`
 	Ppid      uint32   `json:"ppid,omitempty" column:"ppid,template:pid"`
@@ -36,6 +37,10 @@ type Event struct {
 	SessionId uint32   `json:"sessionid" column:"sessionid,minWidth:10,hide"`
 }
 
+func (e *Event) GetPid() uint32 {
+	return e.Pid
+}
+
 func GetColumns() *columns.Columns[Event] {
 	execColumns := columns.MustCreateColumns[Event]()
 
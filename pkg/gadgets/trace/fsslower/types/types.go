@@ -26,6 +26,7 @@ const (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Pid     uint32 `json:"pid,omitempty" column:"pid,template:pid"`
 	Comm    string `json:"comm,omitempty" column:"comm,template:comm"`
@@ -36,6 +37,10 @@ type Event struct {
 	File    string `json:"file,omitempty" column:"file,width:24,maxWidth:32"`
 }
 
+func (e *Event) GetPid() uint32 {
+	return e.Pid
+}
+
 func GetColumns() *columns.Columns[Event] {
 	return columns.MustCreateColumns[Event]()
 }
@@ -4,7 +4,7 @@
 #include <bpf/bpf_helpers.h>
 #include <bpf/bpf_core_read.h>
 #include "mountsnoop.h"
-#include "mntns_filter.h"
+#include "cgroup_filter.h"
 
 #define MAX_ENTRIES 10240
 
@@ -47,7 +47,7 @@ static __always_inline int probe_entry(const char *src, const char *dest, const
 
 	mntns_id = gadget_get_mntns_id();
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		return 0;
 
 	if (target_pid && target_pid != pid)
@@ -15,6 +15,7 @@
 package tracer
 
 import (
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
 	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/mount/types"
@@ -44,10 +45,20 @@ func (g *GadgetDesc) ParamDescs() params.ParamDescs {
 	return nil
 }
 
+func (g *GadgetDesc) ColorRules() []columns.ColorRule {
+	return []columns.ColorRule{
+		{Filter: "ret:!0", Column: "ret", Color: columns.ColorRed},
+	}
+}
+
 func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Event](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{"pid"}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Event{}
 }
@@ -25,13 +25,14 @@ import (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Comm      string   `json:"comm,omitempty" column:"comm,template:comm"`
 	Pid       uint32   `json:"pid,omitempty" column:"pid,template:pid"`
 	Tid       uint32   `json:"tid,omitempty" column:"tid,template:pid"`
 	Operation string   `json:"operation,omitempty" column:"op,minWidth:5,maxWidth:7,hide"`
 	Retval    int      `json:"ret,omitempty" column:"ret,width:3,fixed,hide"`
-	Latency   uint64   `json:"latency,omitempty" column:"latency,minWidth:3,hide"`
+	Latency   uint64   `json:"latency,omitempty" column:"latency,minWidth:3,hide,unit:ns"`
 	Fs        string   `json:"fs,omitempty" column:"fs,minWidth:3,maxWidth:8,hide"`
 	Source    string   `json:"source,omitempty" column:"src,width:16,hide"`
 	Target    string   `json:"target,omitempty" column:"dst,width:16,hide"`
@@ -40,6 +41,10 @@ type Event struct {
 	FlagsRaw  uint64   `json:"flagsRaw,omitempty"`
 }
 
+func (e *Event) GetPid() uint32 {
+	return e.Pid
+}
+
 func GetColumns() *columns.Columns[Event] {
 	cols := columns.MustCreateColumns[Event]()
 
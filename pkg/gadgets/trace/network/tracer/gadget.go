@@ -17,6 +17,7 @@ package tracer
 import (
 	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/internal/networktracer"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/network/types"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
@@ -48,6 +49,10 @@ func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Event](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{networktracer.NetNsFilterColumn}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Event{}
 }
@@ -5,7 +5,7 @@
 #include <bpf/bpf_helpers.h>
 #include <bpf/bpf_core_read.h>
 #include "opensnoop.h"
-#include "mntns_filter.h"
+#include "cgroup_filter.h"
 
 #define TASK_RUNNING	0
 
@@ -55,7 +55,7 @@ bool trace_allowed(u32 tgid, u32 pid)
 
 	mntns_id = gadget_get_mntns_id();
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		return false;
 
 	return true;
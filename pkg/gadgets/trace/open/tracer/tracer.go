@@ -38,6 +38,11 @@ import (
 
 type Config struct {
 	MountnsMap *ebpf.Map
+	CgroupMap  *ebpf.Map
+
+	TargetPid   uint32
+	TargetUid   uint32
+	FilterByUid bool
 }
 
 type Tracer struct {
@@ -97,7 +102,14 @@ func (t *Tracer) install() error {
 		return fmt.Errorf("loading ebpf program: %w", err)
 	}
 
-	if err := gadgets.LoadeBPFSpec(t.config.MountnsMap, spec, nil, &t.objs); err != nil {
+	consts := map[string]interface{}{
+		"targ_tgid": t.config.TargetPid,
+	}
+	if t.config.FilterByUid {
+		consts["targ_uid"] = t.config.TargetUid
+	}
+
+	if err := gadgets.LoadeBPFSpecWithCgroupFilter(t.config.MountnsMap, t.config.CgroupMap, spec, consts, &t.objs); err != nil {
 		return fmt.Errorf("loading ebpf spec: %w", err)
 	}
 
@@ -219,6 +231,10 @@ func (t *Tracer) SetMountNsMap(mountnsMap *ebpf.Map) {
 	t.config.MountnsMap = mountnsMap
 }
 
+func (t *Tracer) SetCgroupIDMap(cgroupMap *ebpf.Map) {
+	t.config.CgroupMap = cgroupMap
+}
+
 func (t *Tracer) SetEventHandler(handler any) {
 	nh, ok := handler.(func(ev *types.Event))
 	if !ok {
@@ -227,6 +243,23 @@ func (t *Tracer) SetEventHandler(handler any) {
 	t.eventCallback = nh
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	for columnName, value := range filters {
+		id, err := gadgets.ParseKernelFilterID(columnName, value)
+		if err != nil {
+			return err
+		}
+		switch columnName {
+		case "pid":
+			t.config.TargetPid = id
+		case "uid":
+			t.config.TargetUid = id
+			t.config.FilterByUid = true
+		}
+	}
+	return nil
+}
+
 func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
 	tracer := &Tracer{
 		config: &Config{},
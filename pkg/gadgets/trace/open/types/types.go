@@ -24,6 +24,7 @@ import (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Pid      uint32      `json:"pid,omitempty" column:"pid,minWidth:7"`
 	Uid      uint32      `json:"uid,omitempty" column:"uid,minWidth:10,hide"`
@@ -39,6 +40,10 @@ type Event struct {
 	Path     string      `json:"path,omitempty" column:"path,minWidth:24,width:32"`
 }
 
+func (e *Event) GetPid() uint32 {
+	return e.Pid
+}
+
 func GetColumns() *columns.Columns[Event] {
 	return columns.MustCreateColumns[Event]()
 }
@@ -4,7 +4,7 @@
 #include <bpf/bpf_core_read.h>
 #include <bpf/bpf_helpers.h>
 #include "sigsnoop.h"
-#include "mntns_filter.h"
+#include "cgroup_filter.h"
 
 #define MAX_ENTRIES	10240
 
@@ -37,7 +37,7 @@ static int probe_entry(pid_t tpid, int sig)
 
 	mntns_id = gadget_get_mntns_id();
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		return 0;
 
 	if (target_signal && sig != target_signal)
@@ -142,7 +142,7 @@ int ig_sig_generate(struct trace_event_raw_signal_generate *ctx)
 
 	mntns_id = gadget_get_mntns_id();
 
-	if (gadget_should_discard_mntns_id(mntns_id))
+	if (gadget_should_discard_current_task(mntns_id))
 		return 0;
 
 	if (failed_only && ret == 0)
@@ -19,6 +19,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
 	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets/trace/signal/types"
@@ -81,10 +82,20 @@ func (g *GadgetDesc) ParamDescs() params.ParamDescs {
 	}
 }
 
+func (g *GadgetDesc) ColorRules() []columns.ColorRule {
+	return []columns.ColorRule{
+		{Filter: "ret:!0", Column: "ret", Color: columns.ColorRed},
+	}
+}
+
 func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Event](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{"pid"}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Event{}
 }
@@ -41,6 +41,7 @@ import (
 
 type Config struct {
 	MountnsMap   *ebpf.Map
+	CgroupMap    *ebpf.Map
 	TargetSignal string
 	TargetPid    int32
 	FailedOnly   bool
@@ -62,6 +63,9 @@ type Tracer struct {
 
 	enricher      gadgets.DataEnricherByMntNs
 	eventCallback func(*types.Event)
+
+	// pid filter pushed down by the runtime; it is used if the pid parameter is not set
+	kernelFilterPid int32
 }
 
 func signalIntToString(signal int) string {
@@ -129,7 +133,7 @@ func (t *Tracer) install() error {
 		"failed_only":   t.config.FailedOnly,
 	}
 
-	if err := gadgets.LoadeBPFSpec(t.config.MountnsMap, spec, consts, &t.objs); err != nil {
+	if err := gadgets.LoadeBPFSpecWithCgroupFilter(t.config.MountnsMap, t.config.CgroupMap, spec, consts, &t.objs); err != nil {
 		return fmt.Errorf("loading ebpf spec: %w", err)
 	}
 
@@ -228,6 +232,9 @@ func (t *Tracer) run() {
 func (t *Tracer) Run(gadgetCtx gadgets.GadgetContext) error {
 	params := gadgetCtx.GadgetParams()
 	t.config.TargetPid = params.Get(ParamPID).AsInt32()
+	if t.config.TargetPid == 0 {
+		t.config.TargetPid = t.kernelFilterPid
+	}
 	t.config.FailedOnly = params.Get(ParamFailedOnly).AsBool()
 	t.config.KillOnly = params.Get(ParamKillOnly).AsBool()
 	t.config.TargetSignal = params.Get(ParamTargetSignal).AsString()
@@ -247,6 +254,10 @@ func (t *Tracer) SetMountNsMap(mountnsMap *ebpf.Map) {
 	t.config.MountnsMap = mountnsMap
 }
 
+func (t *Tracer) SetCgroupIDMap(cgroupMap *ebpf.Map) {
+	t.config.CgroupMap = cgroupMap
+}
+
 func (t *Tracer) SetEventHandler(handler any) {
 	nh, ok := handler.(func(ev *types.Event))
 	if !ok {
@@ -255,6 +266,17 @@ func (t *Tracer) SetEventHandler(handler any) {
 	t.eventCallback = nh
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	if value, ok := filters["pid"]; ok {
+		pid, err := gadgets.ParseKernelFilterID("pid", value)
+		if err != nil {
+			return err
+		}
+		t.kernelFilterPid = int32(pid)
+	}
+	return nil
+}
+
 func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
 	tracer := &Tracer{
 		config: &Config{},
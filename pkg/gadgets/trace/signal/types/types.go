@@ -22,6 +22,7 @@ import (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Pid  uint32 `json:"pid,omitempty" column:"pid,template:pid"`
 	Comm string `json:"comm,omitempty" column:"comm,template:comm"`
@@ -34,6 +35,10 @@ type Event struct {
 	Gid       uint32 `json:"gid" column:"gid,template:gid,hide"`
 }
 
+func (e *Event) GetPid() uint32 {
+	return e.Pid
+}
+
 func GetColumns() *columns.Columns[Event] {
 	return columns.MustCreateColumns[Event]()
 }
@@ -42,6 +42,8 @@ type Tracer struct {
 
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	kernelFilterNetNs uint64
 }
 
 func NewTracer() (*Tracer, error) {
@@ -119,10 +121,20 @@ func (t *Tracer) install() error {
 	if err != nil {
 		return fmt.Errorf("creating network tracer: %w", err)
 	}
+	networkTracer.SetNetNsFilter(t.kernelFilterNetNs)
 	t.Tracer = networkTracer
 	return nil
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	netns, err := networktracer.ParseNetNsFilter(filters)
+	if err != nil {
+		return err
+	}
+	t.kernelFilterNetNs = netns
+	return nil
+}
+
 func (t *Tracer) Run(gadgetCtx gadgets.GadgetContext) error {
 	<-t.ctx.Done()
 	return nil
@@ -48,6 +48,10 @@ func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Event](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{"pid", "uid"}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Event{}
 }
@@ -36,6 +36,10 @@ import (
 
 type Config struct {
 	MountnsMap *ebpf.Map
+
+	TargetPid   uint32
+	TargetUid   uint32
+	FilterByUid bool
 }
 
 type Tracer struct {
@@ -103,7 +107,14 @@ func (t *Tracer) install() error {
 		return fmt.Errorf("loading ebpf program: %w", err)
 	}
 
-	if err := gadgets.LoadeBPFSpec(t.config.MountnsMap, spec, nil, &t.objs); err != nil {
+	consts := map[string]interface{}{
+		"filter_pid": t.config.TargetPid,
+	}
+	if t.config.FilterByUid {
+		consts["filter_uid"] = t.config.TargetUid
+	}
+
+	if err := gadgets.LoadeBPFSpec(t.config.MountnsMap, spec, consts, &t.objs); err != nil {
 		return fmt.Errorf("loading ebpf spec: %w", err)
 	}
 
@@ -244,6 +255,23 @@ func (t *Tracer) SetEventHandler(handler any) {
 	t.eventCallback = nh
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	for columnName, value := range filters {
+		id, err := gadgets.ParseKernelFilterID(columnName, value)
+		if err != nil {
+			return err
+		}
+		switch columnName {
+		case "pid":
+			t.config.TargetPid = id
+		case "uid":
+			t.config.TargetUid = id
+			t.config.FilterByUid = true
+		}
+	}
+	return nil
+}
+
 func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
 	tracer := &Tracer{
 		config: &Config{},
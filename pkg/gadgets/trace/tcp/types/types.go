@@ -22,6 +22,7 @@ import (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Operation string `json:"operation,omitempty" column:"t,width:1,fixed"`
 	Pid       uint32 `json:"pid,omitempty" column:"pid,template:pid"`
@@ -34,6 +35,10 @@ type Event struct {
 	DstEndpoint eventtypes.L4Endpoint `json:"dst,omitempty" column:"dst"`
 }
 
+func (e *Event) GetPid() uint32 {
+	return e.Pid
+}
+
 func (e *Event) GetEndpoints() []*eventtypes.L3Endpoint {
 	return []*eventtypes.L3Endpoint{&e.SrcEndpoint.L3Endpoint, &e.DstEndpoint.L3Endpoint}
 }
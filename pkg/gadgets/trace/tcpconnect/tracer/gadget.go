@@ -68,6 +68,10 @@ func (g *GadgetDesc) Parser() parser.Parser {
 	return parser.NewParser[types.Event](types.GetColumns())
 }
 
+func (g *GadgetDesc) KernelFilterColumns() []string {
+	return []string{"pid", "uid", "dst.port"}
+}
+
 func (g *GadgetDesc) EventPrototype() any {
 	return &types.Event{}
 }
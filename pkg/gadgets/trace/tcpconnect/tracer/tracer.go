@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 	"unsafe"
 
@@ -35,10 +36,18 @@ import (
 
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target $TARGET -cc clang -type event tcpconnect ./bpf/tcpconnect.bpf.c -- -I./bpf/ -I../../../../${TARGET} -I ../../../common/
 
+// maxPorts needs to be kept in sync with MAX_PORTS in bpf/tcpconnect.h
+const maxPorts = 64
+
 type Config struct {
 	MountnsMap       *ebpf.Map
 	CalculateLatency bool
 	MinLatency       time.Duration
+
+	TargetPid   uint32
+	TargetUid   uint32
+	FilterByUid bool
+	TargetPorts []uint16
 }
 
 type Tracer struct {
@@ -102,6 +111,21 @@ func (t *Tracer) install() error {
 	consts := map[string]interface{}{
 		"targ_min_latency_ns": t.config.MinLatency,
 		"calculate_latency":   t.config.CalculateLatency,
+		"filter_pid":          t.config.TargetPid,
+	}
+	if t.config.FilterByUid {
+		consts["filter_uid"] = t.config.TargetUid
+	}
+	if len(t.config.TargetPorts) > 0 {
+		if len(t.config.TargetPorts) > maxPorts {
+			return fmt.Errorf("too many ports to filter: max is %d", maxPorts)
+		}
+		var ports [maxPorts]int32
+		for i, port := range t.config.TargetPorts {
+			ports[i] = int32(gadgets.Htons(port))
+		}
+		consts["filter_ports"] = ports
+		consts["filter_ports_len"] = int32(len(t.config.TargetPorts))
 	}
 
 	if err := gadgets.LoadeBPFSpec(t.config.MountnsMap, spec, consts, &t.objs); err != nil {
@@ -237,6 +261,33 @@ func (t *Tracer) SetEventHandler(handler any) {
 	t.eventCallback = nh
 }
 
+func (t *Tracer) SetKernelFilters(filters map[string]string) error {
+	for columnName, value := range filters {
+		switch columnName {
+		case "pid":
+			pid, err := gadgets.ParseKernelFilterID(columnName, value)
+			if err != nil {
+				return err
+			}
+			t.config.TargetPid = pid
+		case "uid":
+			uid, err := gadgets.ParseKernelFilterID(columnName, value)
+			if err != nil {
+				return err
+			}
+			t.config.TargetUid = uid
+			t.config.FilterByUid = true
+		case "dst.port":
+			port, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return fmt.Errorf("invalid value %q for filter on column %q: %w", value, columnName, err)
+			}
+			t.config.TargetPorts = []uint16{uint16(port)}
+		}
+	}
+	return nil
+}
+
 func (g *GadgetDesc) NewInstance() (gadgets.Gadget, error) {
 	tracer := &Tracer{
 		config: &Config{},
@@ -24,6 +24,7 @@ import (
 type Event struct {
 	eventtypes.Event
 	eventtypes.WithMountNsID
+	eventtypes.WithProcessInfo
 
 	Pid       uint32 `json:"pid,omitempty" column:"pid,template:pid"`
 	Uid       uint32 `json:"uid" column:"uid,template:uid,hide"`
@@ -34,7 +35,11 @@ type Event struct {
 	SrcEndpoint eventtypes.L4Endpoint `json:"src,omitempty" column:"src"`
 	DstEndpoint eventtypes.L4Endpoint `json:"dst,omitempty" column:"dst"`
 
-	Latency time.Duration `json:"latency,omitempty" column:"latency,minWidth:8,align:right,order:4000" columnTags:"param:latency"`
+	Latency time.Duration `json:"latency,omitempty" column:"latency,minWidth:8,align:right,order:4000,unit:ns" columnTags:"param:latency"`
+}
+
+func (e *Event) GetPid() uint32 {
+	return e.Pid
 }
 
 func (e *Event) GetEndpoints() []*eventtypes.L3Endpoint {
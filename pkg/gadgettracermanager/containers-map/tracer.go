@@ -126,14 +126,18 @@ func (cm *ContainersMap) ContainersMapUpdater() containercollection.FuncNotify {
 	return func(event containercollection.PubSubEvent) {
 		switch event.Type {
 		case containercollection.EventTypeAddContainer:
-			// Skip the pause container
-			if event.Container.Name == "" {
+			// Skip the pause container and host cgroups, as the latter
+			// share the mount namespace of the host
+			if event.Container.Name == "" || event.Container.HostCgroup {
 				return
 			}
 
 			cm.addContainerInMap(event.Container)
 
 		case containercollection.EventTypeRemoveContainer:
+			if event.Container.HostCgroup {
+				return
+			}
 			cm.deleteContainerFromMap(event.Container)
 		}
 	}
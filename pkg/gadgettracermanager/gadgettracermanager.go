@@ -141,6 +141,13 @@ func (g *GadgetTracerManager) TracerMountNsMap(tracerID string) (*ebpf.Map, erro
 	return g.tracerCollection.TracerMountNsMap(tracerID)
 }
 
+func (g *GadgetTracerManager) TracerCgroupIDMap(tracerID string) (*ebpf.Map, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.tracerCollection.TracerCgroupIDMap(tracerID)
+}
+
 func (g *GadgetTracerManager) ContainersMap() *ebpf.Map {
 	if g.containersMap == nil {
 		return nil
@@ -257,6 +264,7 @@ func NewServer(conf *Conf) (*GadgetTracerManager, error) {
 		opts = append(opts, containercollection.WithCgroupEnrichment())
 		opts = append(opts, containercollection.WithLinuxNamespaceEnrichment())
 		opts = append(opts, containercollection.WithKubernetesEnrichment(g.nodeName, nil))
+		opts = append(opts, containercollection.WithOwnerReferenceEnrichment(nil))
 		opts = append(opts, containercollection.WithTracerCollection(g.tracerCollection))
 	}
 
@@ -295,6 +303,10 @@ func NewServer(conf *Conf) (*GadgetTracerManager, error) {
 		log.Infof("GadgetTracerManager: hook mode: fanotify+ebpf")
 		opts = append(opts, containercollection.WithContainerFanotifyEbpf())
 		opts = append(opts, containercollection.WithInitialKubernetesContainers(g.nodeName))
+	case "runtime-events":
+		log.Infof("GadgetTracerManager: hook mode: runtime-events")
+		opts = append(opts, containercollection.WithKubernetesRuntimeEvents(g.nodeName))
+		opts = append(opts, containercollection.WithInitialKubernetesContainers(g.nodeName))
 	default:
 		return nil, fmt.Errorf("invalid hook mode: %s", conf.HookMode)
 	}
@@ -304,6 +316,15 @@ func NewServer(conf *Conf) (*GadgetTracerManager, error) {
 		opts = append(opts, containercollection.WithFallbackPodInformer(g.nodeName))
 	}
 
+	if len(conf.EventLabels) > 0 {
+		opts = append(opts, containercollection.WithEventLabels(conf.EventLabels))
+	}
+
+	if conf.HostCgroups && !conf.TestOnly {
+		log.Infof("GadgetTracerManager: enabling host cgroups")
+		opts = append(opts, containercollection.WithHostCgroups())
+	}
+
 	err = g.ContainerCollection.Initialize(opts...)
 	if err != nil {
 		return nil, err
@@ -327,6 +348,8 @@ type Conf struct {
 	NodeName            string
 	HookMode            string
 	FallbackPodInformer bool
+	HostCgroups         bool
+	EventLabels         []string
 	TestOnly            bool
 }
 
@@ -16,12 +16,14 @@ package igmanager
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cilium/ebpf"
 	"github.com/cilium/ebpf/rlimit"
 	log "github.com/sirupsen/logrus"
 
 	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	containerhook "github.com/inspektor-gadget/inspektor-gadget/pkg/container-hook"
 	containerutils "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils"
 	runtimeclient "github.com/inspektor-gadget/inspektor-gadget/pkg/container-utils/runtime-client"
 	containersmap "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgettracermanager/containers-map"
@@ -68,14 +70,68 @@ func (l *IGManager) CreateMountNsMap(id string, containerSelector containercolle
 	return mountnsmap, nil
 }
 
+// CgroupIDMap returns the map with the cgroup IDs of the containers selected
+// by the tracer created with CreateMountNsMap().
+func (l *IGManager) CgroupIDMap(id string) (*ebpf.Map, error) {
+	return l.tracerCollection.TracerCgroupIDMap(id)
+}
+
 func (l *IGManager) RemoveMountNsMap(id string) error {
 	return l.tracerCollection.RemoveTracer(id)
 }
 
-func NewManager(runtimes []*containerutils.RuntimeConfig) (*IGManager, error) {
+// Hook modes define how IGManager detects new containers
+const (
+	// HookModeAuto uses HookModeFanotifyEbpf if it's supported, otherwise
+	// HookModeRuntimeEvents
+	HookModeAuto = "auto"
+	// HookModeFanotifyEbpf uses fanotify and eBPF to detect the containers
+	// created by the OCI runtime, see containercollection.WithContainerFanotifyEbpf()
+	HookModeFanotifyEbpf = "fanotify+ebpf"
+	// HookModeRuntimeEvents uses the event APIs of the container runtimes,
+	// see containercollection.WithRuntimeEvents()
+	HookModeRuntimeEvents = "runtime-events"
+)
+
+// HookModes lists all the supported hook modes
+var HookModes = []string{HookModeAuto, HookModeFanotifyEbpf, HookModeRuntimeEvents}
+
+func hookModeOption(hookMode string, runtimes []*containerutils.RuntimeConfig) (containercollection.ContainerCollectionOption, error) {
+	switch hookMode {
+	case HookModeAuto:
+		if containerhook.Supported() {
+			log.Debugf("IGManager: hook mode: fanotify+ebpf (auto)")
+			return containercollection.WithContainerFanotifyEbpf(), nil
+		}
+		log.Debugf("IGManager: hook mode: runtime-events (auto)")
+		return containercollection.WithRuntimeEvents(runtimes), nil
+	case HookModeFanotifyEbpf:
+		return containercollection.WithContainerFanotifyEbpf(), nil
+	case HookModeRuntimeEvents:
+		return containercollection.WithRuntimeEvents(runtimes), nil
+	default:
+		return nil, fmt.Errorf("invalid hook mode %q (available %s)", hookMode, strings.Join(HookModes, ", "))
+	}
+}
+
+func NewManager(runtimes []*containerutils.RuntimeConfig, additionalOpts ...containercollection.ContainerCollectionOption) (*IGManager, error) {
+	return NewManagerWithHookMode(runtimes, HookModeFanotifyEbpf, additionalOpts...)
+}
+
+// NewManagerWithHookMode is like NewManager but uses hookMode to detect new
+// containers
+func NewManagerWithHookMode(
+	runtimes []*containerutils.RuntimeConfig,
+	hookMode string,
+	additionalOpts ...containercollection.ContainerCollectionOption,
+) (*IGManager, error) {
+	hookOpt, err := hookModeOption(hookMode, runtimes)
+	if err != nil {
+		return nil, err
+	}
+
 	l := &IGManager{}
 
-	var err error
 	l.tracerCollection, err = tracercollection.NewTracerCollection(&l.ContainerCollection)
 	if err != nil {
 		return nil, err
@@ -96,9 +152,10 @@ func NewManager(runtimes []*containerutils.RuntimeConfig) (*IGManager, error) {
 		containercollection.WithCgroupEnrichment(),
 		containercollection.WithLinuxNamespaceEnrichment(),
 		containercollection.WithMultipleContainerRuntimesEnrichment(runtimes),
-		containercollection.WithContainerFanotifyEbpf(),
+		hookOpt,
 		containercollection.WithTracerCollection(l.tracerCollection),
 	}
+	opts = append(opts, additionalOpts...)
 
 	if !log.IsLevelEnabled(log.DebugLevel) && isDefaultContainerRuntimeConfig(runtimes) {
 		warnings := []containercollection.ContainerCollectionOption{containercollection.WithDisableContainerRuntimeWarnings()}
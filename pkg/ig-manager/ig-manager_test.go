@@ -50,6 +50,24 @@ func TestBasic(t *testing.T) {
 	igManager.Close()
 }
 
+func TestHookModes(t *testing.T) {
+	utilstest.RequireRoot(t)
+
+	for _, hookMode := range HookModes {
+		igManager, err := NewManagerWithHookMode(nil, hookMode)
+		if err != nil {
+			t.Fatalf("Failed to start ig manager with hook mode %q: %s", hookMode, err)
+		}
+		igManager.Close()
+	}
+}
+
+func TestInvalidHookMode(t *testing.T) {
+	if _, err := NewManagerWithHookMode(nil, "invalid"); err == nil {
+		t.Fatal("ig manager started with an invalid hook mode")
+	}
+}
+
 func TestContainersMap(t *testing.T) {
 	utilstest.RequireRoot(t)
 
@@ -22,6 +22,7 @@ import (
 	"github.com/cilium/ebpf"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
 
 	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
@@ -37,12 +38,24 @@ const (
 	ParamAllNamespaces = "all-namespaces"
 	ParamPodName       = "podname"
 	ParamNamespace     = "namespace"
+	ParamOwnerKind     = "owner-kind"
+	ParamOwnerName     = "owner-name"
+	ParamCgroupPath    = "cgroup-path"
+	ParamSystemdUnit   = "systemd-unit"
+	ParamWorkload      = "workload"
+	ParamEventLabels   = "event-labels"
 )
 
 type MountNsMapSetter interface {
 	SetMountNsMap(*ebpf.Map)
 }
 
+// CgroupIDMapSetter is implemented by gadgets filtering by cgroup ID instead
+// of mount namespace, see pkg/gadgets/common/cgroup_filter.h.
+type CgroupIDMapSetter interface {
+	SetCgroupIDMap(*ebpf.Map)
+}
+
 type Attacher interface {
 	AttachContainer(container *containercollection.Container) error
 	DetachContainer(*containercollection.Container) error
@@ -74,48 +87,70 @@ func (k *KubeManager) ParamDescs() params.ParamDescs {
 		{
 			Key:         ParamContainerName,
 			Alias:       "c",
-			Description: "Show only data from containers with that name",
+			Description: "Show only data from containers with that name. Supports comma-separated lists, globs, /regular expressions/ and exclusions prefixed with '!'",
 			ValueHint:   gadgets.K8SContainerName,
+			Validator:   containercollection.ValidatePatterns,
 		},
 		{
 			Key:         ParamSelector,
 			Alias:       "l",
-			Description: "Labels selector to filter on. Only '=' is supported (e.g. key1=value1,key2=value2).",
+			Description: "Labels selector to filter on. Supports '=', '==', '!=', 'in', 'notin' and existence (e.g. key1=value1,key2 in (value2,value3),!key3)",
 			ValueHint:   gadgets.K8SLabels,
 			Validator: func(value string) error {
-				if value == "" {
-					return nil
-				}
-
-				pairs := strings.Split(value, ",")
-				for _, pair := range pairs {
-					kv := strings.Split(pair, "=")
-					if len(kv) != 2 {
-						return fmt.Errorf("should be a comma-separated list of key-value pairs (key=value[,key=value,...])")
-					}
-				}
-
-				return nil
+				_, err := labels.Parse(value)
+				return err
 			},
 		},
 		{
 			Key:         ParamPodName,
 			Alias:       "p",
-			Description: "Show only data from pods with that name",
+			Description: "Show only data from pods with that name. Supports comma-separated lists, globs, /regular expressions/ and exclusions prefixed with '!'",
 			ValueHint:   gadgets.K8SPodName,
+			Validator:   containercollection.ValidatePatterns,
 		},
 		{
 			Key:          ParamAllNamespaces,
 			Alias:        "A",
-			Description:  "Show data from pods in all namespaces",
+			Description:  "Show data from pods in all namespaces. Namespaces excluded with --namespace are still ignored",
 			TypeHint:     params.TypeBool,
 			DefaultValue: "false",
 		},
 		{
 			Key:         ParamNamespace,
 			Alias:       "n",
-			Description: "Show only data from pods in a given namespace",
+			Description: "Show only data from pods in a given namespace. Supports comma-separated lists, globs, /regular expressions/ and exclusions prefixed with '!'",
 			ValueHint:   gadgets.K8SNamespace,
+			Validator:   containercollection.ValidatePatterns,
+		},
+		{
+			Key:         ParamOwnerKind,
+			Description: "Show only data from pods owned by a workload of that kind (e.g. Deployment, DaemonSet, Job). Supports the same patterns as --namespace",
+			Validator:   containercollection.ValidatePatterns,
+		},
+		{
+			Key:         ParamOwnerName,
+			Description: "Show only data from pods owned by a workload with that name. Supports the same patterns as --namespace",
+			Validator:   containercollection.ValidatePatterns,
+		},
+		{
+			Key:         ParamCgroupPath,
+			Description: "Show only data from containers and host services in that cgroup v2 path (e.g. /system.slice/*). Supports the same patterns as --namespace",
+			Validator:   containercollection.ValidatePatterns,
+		},
+		{
+			Key:         ParamSystemdUnit,
+			Description: "Show only data from containers and host services in that systemd unit (e.g. kubelet.service). Supports the same patterns as --namespace",
+			Validator:   containercollection.ValidatePatterns,
+		},
+		{
+			Key:          ParamWorkload,
+			Description:  "Add the kind and name of the workload owning the pods (e.g. Deployment, StatefulSet) to the events, in the workloadkind and workloadname columns",
+			TypeHint:     params.TypeBool,
+			DefaultValue: "false",
+		},
+		{
+			Key:         ParamEventLabels,
+			Description: "Comma-separated list of container or image labels to add to the events in the containerlabels column",
 		},
 	}
 }
@@ -143,15 +178,17 @@ func (k *KubeManager) CanOperateOn(gadget gadgets.GadgetDesc) bool {
 		return false
 	}
 	_, isMountNsMapSetter := instance.(MountNsMapSetter)
+	_, isCgroupIDMapSetter := instance.(CgroupIDMapSetter)
 	_, isAttacher := instance.(Attacher)
 
 	log.Debugf("> canEnrichEvent: %v", canEnrichEvent)
 	log.Debugf(" > canEnrichEventFromMountNs: %v", canEnrichEventFromMountNs)
 	log.Debugf(" > canEnrichEventFromNetNs: %v", canEnrichEventFromNetNs)
 	log.Debugf("> isMountNsMapSetter: %v", isMountNsMapSetter)
+	log.Debugf("> isCgroupIDMapSetter: %v", isCgroupIDMapSetter)
 	log.Debugf("> isAttacher: %v", isAttacher)
 
-	return isMountNsMapSetter || canEnrichEvent || isAttacher
+	return isMountNsMapSetter || isCgroupIDMapSetter || canEnrichEvent || isAttacher
 }
 
 func (k *KubeManager) Init(params *params.Params) error {
@@ -167,10 +204,20 @@ func (k *KubeManager) Instantiate(gadgetContext operators.GadgetContext, gadgetI
 	_, canEnrichEventFromNetNs := gadgetContext.GadgetDesc().EventPrototype().(operators.ContainerInfoFromNetNSID)
 	canEnrichEvent := canEnrichEventFromMountNs || canEnrichEventFromNetNs
 
+	_, canEnrichWorkload := gadgetContext.GadgetDesc().EventPrototype().(operators.ContainerWorkloadSetter)
+	_, canEnrichLabels := gadgetContext.GadgetDesc().EventPrototype().(operators.ContainerImageSetter)
+
+	var eventLabels []string
+	if canEnrichEvent && canEnrichLabels {
+		eventLabels = params.Get(ParamEventLabels).AsStringSlice()
+	}
+
 	traceInstance := &KubeManagerInstance{
 		id:             uuid.New().String(),
 		manager:        k,
 		enrichEvents:   canEnrichEvent,
+		enrichWorkload: canEnrichEvent && canEnrichWorkload && params.Get(ParamWorkload).AsBool(),
+		eventLabels:    eventLabels,
 		params:         params,
 		gadgetInstance: gadgetInstance,
 		gadgetCtx:      gadgetContext,
@@ -180,11 +227,14 @@ func (k *KubeManager) Instantiate(gadgetContext operators.GadgetContext, gadgetI
 }
 
 type KubeManagerInstance struct {
-	id           string
-	manager      *KubeManager
-	enrichEvents bool
-	mountnsmap   *ebpf.Map
-	subscribed   bool
+	id             string
+	manager        *KubeManager
+	enrichEvents   bool
+	enrichWorkload bool
+	eventLabels    []string
+	mountnsmap     *ebpf.Map
+	cgroupidmap    *ebpf.Map
+	subscribed     bool
 
 	attachedContainers map[string]*containercollection.Container
 	attacher           Attacher
@@ -200,41 +250,62 @@ func (m *KubeManagerInstance) Name() string {
 func (m *KubeManagerInstance) PreGadgetRun() error {
 	log := m.gadgetCtx.Logger()
 
-	labels := make(map[string]string)
-	selectorSlice := m.params.Get(ParamSelector).AsStringSlice()
-	for _, pair := range selectorSlice {
-		kv := strings.Split(pair, "=")
-		labels[kv[0]] = kv[1]
+	containerSelector := containercollection.ContainerSelector{
+		Namespace:   m.params.Get(ParamNamespace).AsString(),
+		Podname:     m.params.Get(ParamPodName).AsString(),
+		Name:        m.params.Get(ParamContainerName).AsString(),
+		OwnerKind:   m.params.Get(ParamOwnerKind).AsString(),
+		OwnerName:   m.params.Get(ParamOwnerName).AsString(),
+		CgroupPath:  m.params.Get(ParamCgroupPath).AsString(),
+		SystemdUnit: m.params.Get(ParamSystemdUnit).AsString(),
 	}
 
-	containerSelector := containercollection.ContainerSelector{
-		Namespace: m.params.Get(ParamNamespace).AsString(),
-		Podname:   m.params.Get(ParamPodName).AsString(),
-		Name:      m.params.Get(ParamContainerName).AsString(),
-		Labels:    labels,
+	if selector := m.params.Get(ParamSelector).AsString(); selector != "" {
+		labelSelector, err := labels.Parse(selector)
+		if err != nil {
+			return fmt.Errorf("parsing label selector %q: %w", selector, err)
+		}
+		containerSelector.LabelSelector = labelSelector
 	}
 
 	if m.params.Get(ParamAllNamespaces).AsBool() {
-		containerSelector.Namespace = ""
+		containerSelector.Namespace = namespaceExclusions(containerSelector.Namespace)
 	}
 
-	if setter, ok := m.gadgetInstance.(MountNsMapSetter); ok {
+	mntNsSetter, isMountNsMapSetter := m.gadgetInstance.(MountNsMapSetter)
+	cgroupIDSetter, isCgroupIDMapSetter := m.gadgetInstance.(CgroupIDMapSetter)
+	if isMountNsMapSetter || isCgroupIDMapSetter {
 		err := m.manager.gadgetTracerManager.AddTracer(m.id, containerSelector)
 		if err != nil {
 			return fmt.Errorf("adding tracer: %w", err)
 		}
 
-		// Create mount namespace map to filter by containers
-		mountnsmap, err := m.manager.gadgetTracerManager.TracerMountNsMap(m.id)
-		if err != nil {
-			m.manager.gadgetTracerManager.RemoveTracer(m.id)
-			return fmt.Errorf("creating mountns map: %w", err)
+		if isMountNsMapSetter {
+			// Create mount namespace map to filter by containers
+			mountnsmap, err := m.manager.gadgetTracerManager.TracerMountNsMap(m.id)
+			if err != nil {
+				m.manager.gadgetTracerManager.RemoveTracer(m.id)
+				return fmt.Errorf("creating mountns map: %w", err)
+			}
+
+			log.Debugf("set mountnsmap for gadget")
+			mntNsSetter.SetMountNsMap(mountnsmap)
+
+			m.mountnsmap = mountnsmap
 		}
 
-		log.Debugf("set mountnsmap for gadget")
-		setter.SetMountNsMap(mountnsmap)
+		if isCgroupIDMapSetter {
+			cgroupidmap, err := m.manager.gadgetTracerManager.TracerCgroupIDMap(m.id)
+			if err != nil {
+				m.manager.gadgetTracerManager.RemoveTracer(m.id)
+				return fmt.Errorf("creating cgroup id map: %w", err)
+			}
+
+			log.Debugf("set cgroupidmap for gadget")
+			cgroupIDSetter.SetCgroupIDMap(cgroupidmap)
 
-		m.mountnsmap = mountnsmap
+			m.cgroupidmap = cgroupidmap
+		}
 	}
 
 	if attacher, ok := m.gadgetInstance.(Attacher); ok {
@@ -298,8 +369,20 @@ func (m *KubeManagerInstance) PreGadgetRun() error {
 	return nil
 }
 
+// namespaceExclusions returns only the exclusions of a comma-separated list of
+// namespace patterns, so that they're still applied with --all-namespaces.
+func namespaceExclusions(namespaces string) string {
+	var exclusions []string
+	for _, ns := range containercollection.SplitPatterns(namespaces) {
+		if strings.HasPrefix(ns, "!") {
+			exclusions = append(exclusions, ns)
+		}
+	}
+	return strings.Join(exclusions, ",")
+}
+
 func (m *KubeManagerInstance) PostGadgetRun() error {
-	if m.mountnsmap != nil {
+	if m.mountnsmap != nil || m.cgroupidmap != nil {
 		m.gadgetCtx.Logger().Debugf("calling RemoveTracer()")
 		m.manager.gadgetTracerManager.RemoveTracer(m.id)
 	}
@@ -322,6 +405,12 @@ func (m *KubeManagerInstance) enrich(ev any) {
 	if event, canEnrichEventFromNetNs := ev.(operators.ContainerInfoFromNetNSID); canEnrichEventFromNetNs {
 		m.manager.gadgetTracerManager.ContainerCollection.EnrichEventByNetNs(event)
 	}
+	if event, canEnrichWorkload := ev.(operators.ContainerWorkloadSetter); canEnrichWorkload && m.enrichWorkload {
+		m.manager.gadgetTracerManager.ContainerCollection.EnrichEventWithWorkload(event)
+	}
+	if event, canEnrichLabels := ev.(operators.ContainerImageSetter); canEnrichLabels && len(m.eventLabels) > 0 {
+		m.manager.gadgetTracerManager.ContainerCollection.EnrichEventLabels(event, m.eventLabels)
+	}
 }
 
 func (m *KubeManagerInstance) EnrichEvent(ev any) error {
@@ -43,12 +43,20 @@ const (
 	ContainerdSocketPath = "containerd-socketpath"
 	CrioSocketPath       = "crio-socketpath"
 	PodmanSocketPath     = "podman-socketpath"
+	EventLabels          = "event-labels"
+	HookMode             = "hook-mode"
 )
 
 type MountNsMapSetter interface {
 	SetMountNsMap(*ebpf.Map)
 }
 
+// CgroupIDMapSetter is implemented by gadgets filtering by cgroup ID instead
+// of mount namespace, see pkg/gadgets/common/cgroup_filter.h.
+type CgroupIDMapSetter interface {
+	SetCgroupIDMap(*ebpf.Map)
+}
+
 type Attacher interface {
 	AttachContainer(container *containercollection.Container) error
 	DetachContainer(*containercollection.Container) error
@@ -101,6 +109,17 @@ func (l *LocalManager) GlobalParamDescs() params.ParamDescs {
 			DefaultValue: runtimeclient.PodmanDefaultSocketPath,
 			Description:  "Podman Unix socket path",
 		},
+		{
+			Key:         EventLabels,
+			Description: "Comma-separated list of container or image labels to add to the events in the containerlabels column",
+		},
+		{
+			Key:          HookMode,
+			DefaultValue: igmanager.HookModeAuto,
+			Description: "How to detect new containers: fanotify+ebpf watches the OCI runtimes, runtime-events uses the event " +
+				"APIs of the container runtimes and auto uses fanotify+ebpf if supported, otherwise runtime-events",
+			PossibleValues: igmanager.HookModes,
+		},
 	}
 }
 
@@ -140,15 +159,17 @@ func (l *LocalManager) CanOperateOn(gadget gadgets.GadgetDesc) bool {
 		return false
 	}
 	_, isMountNsMapSetter := instance.(MountNsMapSetter)
+	_, isCgroupIDMapSetter := instance.(CgroupIDMapSetter)
 	_, isAttacher := instance.(Attacher)
 
 	log.Debugf("> canEnrichEvent: %v", canEnrichEvent)
 	log.Debugf("\t> canEnrichEventFromMountNs: %v", canEnrichEventFromMountNs)
 	log.Debugf("\t> canEnrichEventFromNetNs: %v", canEnrichEventFromNetNs)
 	log.Debugf("> isMountNsMapSetter: %v", isMountNsMapSetter)
+	log.Debugf("> isCgroupIDMapSetter: %v", isCgroupIDMapSetter)
 	log.Debugf("> isAttacher: %v", isAttacher)
 
-	return isMountNsMapSetter || canEnrichEvent || isAttacher
+	return isMountNsMapSetter || isCgroupIDMapSetter || canEnrichEvent || isAttacher
 }
 
 func (l *LocalManager) Init(operatorParams *params.Params) error {
@@ -190,7 +211,12 @@ partsLoop:
 
 	l.rc = rc
 
-	igManager, err := igmanager.NewManager(l.rc)
+	var opts []containercollection.ContainerCollectionOption
+	if eventLabels := operatorParams.Get(EventLabels).AsStringSlice(); len(eventLabels) > 0 {
+		opts = append(opts, containercollection.WithEventLabels(eventLabels))
+	}
+
+	igManager, err := igmanager.NewManagerWithHookMode(l.rc, operatorParams.Get(HookMode).AsString(), opts...)
 	if err != nil {
 		return commonutils.WrapInErrManagerInit(err)
 	}
@@ -251,19 +277,33 @@ func (l *localManagerTrace) PreGadgetRun() error {
 		Name: l.params.Get(ContainerName).AsString(),
 	}
 
-	// If --host is set, we do not want to create the below map because we do not
+	// If --host is set, we do not want to create the below maps because we do not
 	// want any filtering.
-	if setter, ok := l.gadgetInstance.(MountNsMapSetter); ok && !host {
+	mntNsSetter, isMountNsMapSetter := l.gadgetInstance.(MountNsMapSetter)
+	cgroupIDSetter, isCgroupIDMapSetter := l.gadgetInstance.(CgroupIDMapSetter)
+	if (isMountNsMapSetter || isCgroupIDMapSetter) && !host {
 		// Create mount namespace map to filter by containers
 		mountnsmap, err := l.manager.igManager.CreateMountNsMap(l.subscriptionKey, containerSelector)
 		if err != nil {
 			return commonutils.WrapInErrManagerCreateMountNsMap(err)
 		}
+		l.mountnsmap = mountnsmap
 
-		log.Debugf("set mountnsmap for gadget")
-		setter.SetMountNsMap(mountnsmap)
+		if isMountNsMapSetter {
+			log.Debugf("set mountnsmap for gadget")
+			mntNsSetter.SetMountNsMap(mountnsmap)
+		}
 
-		l.mountnsmap = mountnsmap
+		if isCgroupIDMapSetter {
+			cgroupidmap, err := l.manager.igManager.CgroupIDMap(l.subscriptionKey)
+			if err != nil {
+				l.manager.igManager.RemoveMountNsMap(l.subscriptionKey)
+				return fmt.Errorf("getting cgroup id map: %w", err)
+			}
+
+			log.Debugf("set cgroupidmap for gadget")
+			cgroupIDSetter.SetCgroupIDMap(cgroupidmap)
+		}
 	}
 
 	if attacher, ok := l.gadgetInstance.(Attacher); ok {
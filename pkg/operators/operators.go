@@ -16,6 +16,7 @@ package operators
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -24,6 +25,7 @@ import (
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
 )
 
 type GadgetContext interface {
@@ -84,6 +86,35 @@ type OperatorInstance interface {
 	EnrichEvent(ev any) error
 }
 
+// FilteringOperator can be implemented by operators that drop events by returning parser.ErrDropEvent from
+// EnrichFilteredEvent (see FilteredEventEnricher). Their instances will be called after the ones of all other
+// operators, so they can make decisions based on fully enriched events.
+type FilteringOperator interface {
+	FiltersEvents() bool
+}
+
+// ExportingOperator can be implemented by operators that ship events to external systems from EnrichFilteredEvent
+// (see FilteredEventEnricher). Their instances will be called after the ones of all other operators, including
+// filtering operators, so they only see events that weren't dropped.
+type ExportingOperator interface {
+	ExportsEvents() bool
+}
+
+// FilteredEventEnricher can be implemented by operator instances that need to see events once they were enriched by
+// all operators and filtered by the parser (e.g. with --filter), like the instances of filtering and exporting
+// operators. EnrichFilteredEvent can return parser.ErrDropEvent to drop the event.
+type FilteredEventEnricher interface {
+	EnrichFilteredEvent(ev any) error
+}
+
+// EventEmitterSetter can be implemented by operator instances that want to emit events on their own, like
+// summaries. The emit function accepts events of the same type as the gadget's EventPrototype(); emitted events are
+// not enriched by the operators again, but they're filtered and exported like the other events (see
+// FilteredEventEnricher).
+type EventEmitterSetter interface {
+	SetEventEmitter(emit func(ev any))
+}
+
 type Operators []Operator
 
 // ContainerInfoFromMountNSID is a typical kubernetes operator interface that adds node, pod, namespace and container
@@ -103,6 +134,19 @@ type ContainerInfoSetters interface {
 	SetContainerInfo(pod, namespace, container string)
 }
 
+// ContainerImageSetter is optionally implemented by events carrying the image
+// and the labels of the container they come from
+type ContainerImageSetter interface {
+	SetContainerImage(name, tag, digest string)
+	SetContainerLabels(labels map[string]string)
+}
+
+// ContainerWorkloadSetter is optionally implemented by events carrying the
+// workload owning the pod they come from
+type ContainerWorkloadSetter interface {
+	SetWorkload(kind, name string)
+}
+
 type NodeSetter interface {
 	SetNode(string)
 }
@@ -257,14 +301,47 @@ func (oi OperatorInstances) PostGadgetRun() error {
 	return nil
 }
 
+// SetEventEmitter hands over the given emit function to all members of the collection that implement
+// EventEmitterSetter
+func (oi OperatorInstances) SetEventEmitter(emit func(ev any)) {
+	for _, instance := range oi {
+		if setter, ok := instance.(EventEmitterSetter); ok {
+			setter.SetEventEmitter(emit)
+		}
+	}
+}
+
 // Enrich an event using all members of the operator collection
 func (oi OperatorInstances) Enrich(ev any) error {
-	var err error
+	var dropErr error
 	for _, operator := range oi {
-		if err = operator.EnrichEvent(ev); err != nil {
+		if err := operator.EnrichEvent(ev); err != nil {
+			if errors.Is(err, parser.ErrDropEvent) {
+				// Keep enriching the event, dropped events can still be recorded
+				dropErr = err
+				continue
+			}
 			return fmt.Errorf("operator %q failed to enrich event %+v", operator.Name(), ev)
 		}
 	}
+	return dropErr
+}
+
+// EnrichFiltered calls EnrichFilteredEvent on the instances implementing FilteredEventEnricher, in the order of
+// their operators, until one of them drops the event.
+func (oi OperatorInstances) EnrichFiltered(ev any) error {
+	for _, operator := range oi {
+		enricher, ok := operator.(FilteredEventEnricher)
+		if !ok {
+			continue
+		}
+		if err := enricher.EnrichFilteredEvent(ev); err != nil {
+			if errors.Is(err, parser.ErrDropEvent) {
+				return err
+			}
+			return fmt.Errorf("operator %q failed to enrich filtered event %+v", operator.Name(), ev)
+		}
+	}
 	return nil
 }
 
@@ -348,5 +425,38 @@ outerLoop:
 		}
 	}
 
-	return result, nil
+	// Move filtering operators to the end, so they see events enriched by all other operators, followed by
+	// exporting operators, so they only see events that weren't dropped
+	sorted := make(Operators, 0, len(result))
+	var filtering, exporting Operators
+	for _, e := range result {
+		switch {
+		case exportsEvents(e):
+			exporting = append(exporting, e)
+		case filtersEvents(e):
+			filtering = append(filtering, e)
+		default:
+			sorted = append(sorted, e)
+		}
+	}
+
+	sorted = append(sorted, filtering...)
+	return append(sorted, exporting...), nil
+}
+
+func unwrap(operator Operator) Operator {
+	if wrapper, ok := operator.(*operatorWrapper); ok {
+		return wrapper.Operator
+	}
+	return operator
+}
+
+func filtersEvents(operator Operator) bool {
+	f, ok := unwrap(operator).(FilteringOperator)
+	return ok && f.FiltersEvents()
+}
+
+func exportsEvents(operator Operator) bool {
+	e, ok := unwrap(operator).(ExportingOperator)
+	return ok && e.ExportsEvents()
 }
@@ -193,3 +193,35 @@ func Test_SortOperatorsLargeCyclicDep(t *testing.T) {
 	_, err := SortOperators(ops)
 	assert.ErrorContains(t, err, "dependency cycle detected")
 }
+
+type testFilteringOp struct {
+	testOp
+}
+
+func (op testFilteringOp) FiltersEvents() bool {
+	return true
+}
+
+type testExportingOp struct {
+	testOp
+}
+
+func (op testExportingOp) ExportsEvents() bool {
+	return true
+}
+
+func Test_SortOperatorsFilteringLast(t *testing.T) {
+	ops := Operators{
+		testExportingOp{createOp("export", []string{})},
+		testFilteringOp{createOp("filter", []string{})},
+		createOp("b", []string{"a"}),
+		createOp("a", []string{}),
+	}
+
+	sortedOps, err := SortOperators(ops)
+	if assert.NoError(t, err) {
+		checkDependencies(t, ops, sortedOps)
+		assert.Equal(t, "filter", sortedOps[len(sortedOps)-2].Name())
+		assert.Equal(t, "export", sortedOps[len(sortedOps)-1].Name())
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+
+	exportLogsPath = "/v1/logs"
+)
+
+// client sends ExportLogsServiceRequest messages to a collector
+type client interface {
+	export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error
+	close() error
+}
+
+// retryableError marks errors after which the export should be retried
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+type grpcClient struct {
+	conn    *grpc.ClientConn
+	client  collogspb.LogsServiceClient
+	headers metadata.MD
+}
+
+func newGRPCClient(endpoint string, useInsecure bool, headers map[string]string) (*grpcClient, error) {
+	creds := credentials.NewTLS(&tls.Config{})
+	if useInsecure {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("dialing %q: %w", endpoint, err)
+	}
+	return &grpcClient{
+		conn:    conn,
+		client:  collogspb.NewLogsServiceClient(conn),
+		headers: metadata.New(headers),
+	}, nil
+}
+
+func (c *grpcClient) export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	ctx = metadata.NewOutgoingContext(ctx, c.headers)
+	_, err := c.client.Export(ctx, request)
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted, codes.OutOfRange,
+		codes.Unavailable, codes.DataLoss:
+		return &retryableError{err}
+	}
+	return err
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
+
+type httpClient struct {
+	client  *http.Client
+	url     string
+	headers map[string]string
+}
+
+func newHTTPClient(endpoint string, useInsecure bool, headers map[string]string) *httpClient {
+	url := endpoint
+	if !strings.Contains(url, "://") {
+		if useInsecure {
+			url = "http://" + url
+		} else {
+			url = "https://" + url
+		}
+	}
+	// Like the OTel SDKs, append the signal specific path if only the host was given
+	if !strings.Contains(strings.SplitN(url, "://", 2)[1], "/") {
+		url += exportLogsPath
+	}
+	return &httpClient{
+		client:  &http.Client{},
+		url:     url,
+		headers: headers,
+	}
+}
+
+func (c *httpClient) export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &retryableError{err}
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		return &retryableError{fmt.Errorf("exporting logs: %s", resp.Status)}
+	}
+	return fmt.Errorf("exporting logs: %s", resp.Status)
+}
+
+func (c *httpClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+type exporterConfig struct {
+	scopeName    string
+	batchSize    int
+	batchTimeout time.Duration
+	queueSize    int
+	// timeout of a single export request, there's none if it isn't positive
+	timeout      time.Duration
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// exporter batches records and exports them using the given client; records are dropped if the queue is full or
+// the export failed after all retries
+type exporter struct {
+	client client
+	config exporterConfig
+	logger logger.Logger
+
+	mu      sync.RWMutex
+	closed  bool
+	queue   chan *resource
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	dropped uint64
+}
+
+// resource is used to queue a record together with the attributes of its resource
+type resource struct {
+	key        string
+	attributes []keyValue
+	record     *logRecord
+}
+
+func newExporter(client client, config exporterConfig, logger logger.Logger) *exporter {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &exporter{
+		client: client,
+		config: config,
+		logger: logger,
+		queue:  make(chan *resource, config.queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (e *exporter) start() {
+	e.wg.Add(1)
+	go e.run()
+}
+
+// enqueue adds a record to the queue without blocking
+func (e *exporter) enqueue(r *resource) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+
+	select {
+	case e.queue <- r:
+	default:
+		dropped := atomic.AddUint64(&e.dropped, 1)
+		if dropped == 1 || dropped%1000 == 0 {
+			e.logger.Warnf("export queue is full, dropped %d log records so far", dropped)
+		}
+	}
+}
+
+// stop exports the remaining records and waits for it to finish; exports still running after the given timeout
+// are aborted
+func (e *exporter) stop(timeout time.Duration) {
+	e.mu.Lock()
+	e.closed = true
+	close(e.queue)
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		e.cancel()
+		<-done
+	}
+	e.cancel()
+	if err := e.client.close(); err != nil {
+		e.logger.Debugf("closing client: %v", err)
+	}
+}
+
+func (e *exporter) run() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.config.batchTimeout)
+	defer ticker.Stop()
+
+	var batch []*resource
+	for {
+		select {
+		case r, ok := <-e.queue:
+			if !ok {
+				e.exportBatch(batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) >= e.config.batchSize {
+				e.exportBatch(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			e.exportBatch(batch)
+			batch = nil
+		}
+	}
+}
+
+// exportBatch groups the records of the batch by resource and exports them, retrying with exponential backoff
+func (e *exporter) exportBatch(batch []*resource) {
+	if len(batch) == 0 {
+		return
+	}
+
+	var logs []*resourceLogs
+	byKey := make(map[string]*resourceLogs)
+	for _, r := range batch {
+		rl, ok := byKey[r.key]
+		if !ok {
+			rl = &resourceLogs{attributes: r.attributes}
+			byKey[r.key] = rl
+			logs = append(logs, rl)
+		}
+		rl.records = append(rl.records, r.record)
+	}
+	request := newExportLogsRequest(e.config.scopeName, logs)
+
+	backoff := e.config.retryBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithCancel(e.ctx)
+		if e.config.timeout > 0 {
+			ctx, cancel = context.WithTimeout(e.ctx, e.config.timeout)
+		}
+		err := e.client.export(ctx, request)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt >= e.config.maxRetries || e.ctx.Err() != nil {
+			e.logger.Warnf("exporting %d log records: %v", len(batch), err)
+			return
+		}
+
+		e.logger.Debugf("exporting %d log records failed, retrying in %s: %v", len(batch), backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-e.ctx.Done():
+			e.logger.Warnf("exporting %d log records: %v", len(batch), err)
+			return
+		}
+		backoff *= 2
+	}
+}
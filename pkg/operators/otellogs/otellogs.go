@@ -0,0 +1,367 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otellogs provides an operator that exports the events of a gadget as OpenTelemetry log records using
+// OTLP/gRPC or OTLP/HTTP.
+package otellogs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+const (
+	OperatorName         = "OtelLogs"
+	OperatorInstanceName = "OtelLogsInstance"
+	ParamEndpoint        = "otel-logs-endpoint"
+	ParamProtocol        = "otel-logs-protocol"
+	ParamInsecure        = "otel-logs-insecure"
+	ParamHeaders         = "otel-logs-headers"
+	ParamResourceAttrs   = "otel-logs-resource-attributes"
+	ParamBatchSize       = "otel-logs-batch-size"
+	ParamBatchTimeout    = "otel-logs-batch-timeout"
+	ParamQueueSize       = "otel-logs-queue-size"
+	ParamTimeout         = "otel-logs-timeout"
+	ParamMaxRetries      = "otel-logs-max-retries"
+
+	scopeName    = "github.com/inspektor-gadget/inspektor-gadget"
+	serviceName  = "inspektor-gadget"
+	retryBackoff = time.Second
+	stopTimeout  = 10 * time.Second
+)
+
+type OtelLogs struct{}
+
+func (o *OtelLogs) Name() string {
+	return OperatorName
+}
+
+func (o *OtelLogs) Description() string {
+	return "Exports events as OpenTelemetry log records using OTLP"
+}
+
+func (o *OtelLogs) GlobalParamDescs() params.ParamDescs {
+	return nil
+}
+
+func (o *OtelLogs) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{
+		{
+			Key:         ParamEndpoint,
+			Description: "OTLP endpoint to export events to as log records, e.g. localhost:4317 (gRPC) or http://localhost:4318 (HTTP); empty disables the export",
+		},
+		{
+			Key:            ParamProtocol,
+			DefaultValue:   ProtocolGRPC,
+			Description:    "OTLP protocol to use",
+			PossibleValues: []string{ProtocolGRPC, ProtocolHTTP},
+		},
+		{
+			Key:          ParamInsecure,
+			DefaultValue: "false",
+			Description:  "Don't use TLS to connect to the OTLP endpoint",
+			TypeHint:     params.TypeBool,
+		},
+		{
+			Key:         ParamHeaders,
+			Description: "Comma-separated list of key=value headers to send with each export request",
+			Validator:   validateKeyValues,
+		},
+		{
+			Key:         ParamResourceAttrs,
+			Description: "Comma-separated list of key=value attributes to add to the resource of each log record",
+			Validator:   validateKeyValues,
+		},
+		{
+			Key:          ParamBatchSize,
+			DefaultValue: "512",
+			Description:  "Maximum number of log records to export at once",
+			TypeHint:     params.TypeUint,
+		},
+		{
+			Key:          ParamBatchTimeout,
+			DefaultValue: "5s",
+			Description:  "Maximum time to wait before exporting log records that don't fill a batch",
+			TypeHint:     params.TypeDuration,
+		},
+		{
+			Key:          ParamQueueSize,
+			DefaultValue: "2048",
+			Description:  "Maximum number of log records waiting to be exported; further events are dropped",
+			TypeHint:     params.TypeUint,
+		},
+		{
+			Key:          ParamTimeout,
+			DefaultValue: "10s",
+			Description:  "Timeout for a single export request, 0 to disable it",
+			TypeHint:     params.TypeDuration,
+		},
+		{
+			Key:          ParamMaxRetries,
+			DefaultValue: "5",
+			Description:  "Maximum number of retries of failed export requests",
+			TypeHint:     params.TypeUint,
+		},
+	}
+}
+
+func (o *OtelLogs) Dependencies() []string {
+	return nil
+}
+
+// ExportsEvents makes sure the operator only sees events that weren't dropped by other operators
+func (o *OtelLogs) ExportsEvents() bool {
+	return true
+}
+
+func (o *OtelLogs) CanOperateOn(gadget gadgets.GadgetDesc) bool {
+	return gadget.Type() == gadgets.TypeTrace && gadget.EventPrototype() != nil
+}
+
+func (o *OtelLogs) Init(params *params.Params) error {
+	return nil
+}
+
+func (o *OtelLogs) Close() error {
+	return nil
+}
+
+func (o *OtelLogs) Instantiate(gadgetContext operators.GadgetContext, gadgetInstance any, params *params.Params) (operators.OperatorInstance, error) {
+	instance := &otelLogsInstance{
+		gadgetCtx: gadgetContext,
+	}
+
+	endpoint := params.Get(ParamEndpoint).AsString()
+	if endpoint == "" {
+		return instance, nil
+	}
+
+	useInsecure := params.Get(ParamInsecure).AsBool()
+	headers := parseKeyValues(params.Get(ParamHeaders).AsString())
+
+	var c client
+	switch params.Get(ParamProtocol).AsString() {
+	case ProtocolHTTP:
+		c = newHTTPClient(endpoint, useInsecure, headers)
+	default:
+		grpcClient, err := newGRPCClient(endpoint, useInsecure, headers)
+		if err != nil {
+			return nil, err
+		}
+		c = grpcClient
+	}
+
+	batchSize := int(params.Get(ParamBatchSize).AsUint())
+	if batchSize == 0 {
+		batchSize = 1
+	}
+	queueSize := int(params.Get(ParamQueueSize).AsUint())
+	if queueSize < batchSize {
+		queueSize = batchSize
+	}
+	batchTimeout := params.Get(ParamBatchTimeout).AsDuration()
+	if batchTimeout <= 0 {
+		batchTimeout = time.Second
+	}
+
+	instance.exporter = newExporter(c, exporterConfig{
+		scopeName:    scopeName,
+		batchSize:    batchSize,
+		batchTimeout: batchTimeout,
+		queueSize:    queueSize,
+		timeout:      params.Get(ParamTimeout).AsDuration(),
+		maxRetries:   int(params.Get(ParamMaxRetries).AsUint()),
+		retryBackoff: retryBackoff,
+	}, gadgetContext.Logger())
+
+	desc := gadgetContext.GadgetDesc()
+	instance.attributes = []keyValue{
+		{key: "gadget.category", value: desc.Category()},
+		{key: "gadget.name", value: desc.Name()},
+	}
+	instance.resourceAttributes = append([]keyValue{{key: "service.name", value: serviceName}},
+		sortedKeyValues(toAnyMap(parseKeyValues(params.Get(ParamResourceAttrs).AsString())))...)
+
+	return instance, nil
+}
+
+type otelLogsInstance struct {
+	gadgetCtx          operators.GadgetContext
+	exporter           *exporter
+	attributes         []keyValue
+	resourceAttributes []keyValue
+}
+
+func (o *otelLogsInstance) Name() string {
+	return OperatorInstanceName
+}
+
+func (o *otelLogsInstance) PreGadgetRun() error {
+	if o.exporter != nil {
+		o.exporter.start()
+	}
+	return nil
+}
+
+func (o *otelLogsInstance) PostGadgetRun() error {
+	if o.exporter != nil {
+		o.exporter.stop(stopTimeout)
+	}
+	return nil
+}
+
+func (o *otelLogsInstance) EnrichEvent(ev any) error {
+	return nil
+}
+
+// EnrichFilteredEvent exports the events that matched the filters of the parser and weren't dropped by other
+// operators
+func (o *otelLogsInstance) EnrichFilteredEvent(ev any) error {
+	if o.exporter == nil {
+		return nil
+	}
+	r, err := o.toResource(ev, time.Now())
+	if err != nil {
+		o.gadgetCtx.Logger().Debugf("converting event to log record: %v", err)
+		return nil
+	}
+	o.exporter.enqueue(r)
+	return nil
+}
+
+// resourceFields are the fields of eventtypes.CommonData and the OpenTelemetry resource attributes they're mapped to
+var resourceFields = []struct {
+	field     string
+	attribute string
+}{
+	{field: "node", attribute: "k8s.node.name"},
+	{field: "namespace", attribute: "k8s.namespace.name"},
+	{field: "pod", attribute: "k8s.pod.name"},
+	{field: "container", attribute: "k8s.container.name"},
+}
+
+// toResource converts the given event to a log record; fields of eventtypes.CommonData are used as attributes of
+// the resource, while all other fields make up the body of the record
+func (o *otelLogsInstance) toResource(ev any, now time.Time) (*resource, error) {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	record := &logRecord{
+		observedTimeUnixNano: uint64(now.UnixNano()),
+		attributes:           o.attributes,
+	}
+
+	if ts, ok := fields["timestamp"].(json.Number); ok {
+		if n, err := ts.Int64(); err == nil && n > 0 {
+			record.timeUnixNano = uint64(n)
+		}
+	}
+	delete(fields, "timestamp")
+
+	eventType, _ := fields["type"].(string)
+	record.severityNumber, record.severityText = toSeverity(eventtypes.EventType(eventType))
+	message, _ := fields["message"].(string)
+	delete(fields, "message")
+
+	r := &resource{
+		attributes: make([]keyValue, 0, len(o.resourceAttributes)+len(resourceFields)),
+	}
+	r.attributes = append(r.attributes, o.resourceAttributes...)
+	keys := make([]string, 0, len(resourceFields))
+	for _, f := range resourceFields {
+		value, _ := fields[f.field].(string)
+		delete(fields, f.field)
+		keys = append(keys, value)
+		if value != "" {
+			r.attributes = append(r.attributes, keyValue{key: f.attribute, value: value})
+		}
+	}
+	r.key = strings.Join(keys, "/")
+
+	if message != "" && eventtypes.EventType(eventType) != eventtypes.NORMAL {
+		record.body = message
+	} else {
+		record.body = fields
+	}
+	r.record = record
+
+	return r, nil
+}
+
+func toSeverity(eventType eventtypes.EventType) (logspb.SeverityNumber, string) {
+	switch eventType {
+	case eventtypes.ERR:
+		return severityError, "ERROR"
+	case eventtypes.WARN:
+		return severityWarn, "WARN"
+	case eventtypes.DEBUG:
+		return severityDebug, "DEBUG"
+	}
+	return severityInfo, "INFO"
+}
+
+func validateKeyValues(value string) error {
+	if value == "" {
+		return nil
+	}
+	for _, kv := range strings.Split(value, ",") {
+		if k, _, ok := strings.Cut(kv, "="); !ok || strings.TrimSpace(k) == "" {
+			return fmt.Errorf("%q is not in the form key=value", kv)
+		}
+	}
+	return nil
+}
+
+func parseKeyValues(value string) map[string]string {
+	res := make(map[string]string)
+	if value == "" {
+		return res
+	}
+	for _, kv := range strings.Split(value, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		res[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return res
+}
+
+func toAnyMap(m map[string]string) map[string]any {
+	res := make(map[string]any, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+func init() {
+	operators.Register(&OtelLogs{})
+}
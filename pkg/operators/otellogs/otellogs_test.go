@@ -0,0 +1,209 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+type testEvent struct {
+	eventtypes.Event
+	Pid  uint32 `json:"pid"`
+	Comm string `json:"comm"`
+}
+
+func TestToResource(t *testing.T) {
+	instance := &otelLogsInstance{
+		attributes:         []keyValue{{key: "gadget.name", value: "exec"}},
+		resourceAttributes: []keyValue{{key: "service.name", value: serviceName}},
+	}
+
+	ev := &testEvent{
+		Event: eventtypes.Event{
+			CommonData: eventtypes.CommonData{
+				Node:      "node1",
+				Namespace: "default",
+				Pod:       "nginx",
+				Container: "nginx",
+			},
+			Timestamp: 1234,
+			Type:      eventtypes.NORMAL,
+		},
+		Pid:  42,
+		Comm: "cat",
+	}
+
+	r, err := instance.toResource(ev, time.Unix(0, 5678))
+	require.NoError(t, err)
+
+	assert.Equal(t, "node1/default/nginx/nginx", r.key)
+	assert.Equal(t, []keyValue{
+		{key: "service.name", value: serviceName},
+		{key: "k8s.node.name", value: "node1"},
+		{key: "k8s.namespace.name", value: "default"},
+		{key: "k8s.pod.name", value: "nginx"},
+		{key: "k8s.container.name", value: "nginx"},
+	}, r.attributes)
+	assert.Equal(t, uint64(1234), r.record.timeUnixNano)
+	assert.Equal(t, uint64(5678), r.record.observedTimeUnixNano)
+	assert.Equal(t, severityInfo, r.record.severityNumber)
+	assert.Equal(t, map[string]any{
+		"type": "normal",
+		"pid":  json.Number("42"),
+		"comm": "cat",
+	}, r.record.body)
+
+	warn := &testEvent{Event: eventtypes.Warn("something happened")}
+	r, err = instance.toResource(warn, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, severityWarn, r.record.severityNumber)
+	assert.Equal(t, "something happened", r.record.body)
+}
+
+func TestNewExportLogsRequest(t *testing.T) {
+	request := newExportLogsRequest(scopeName, []*resourceLogs{
+		{
+			attributes: []keyValue{{key: "k8s.pod.name", value: "nginx"}},
+			records: []*logRecord{
+				{severityNumber: severityInfo, body: map[string]any{"comm": "cat", "pid": json.Number("42")}},
+				{severityNumber: severityWarn, body: "warning"},
+			},
+		},
+	})
+
+	require.Len(t, request.ResourceLogs, 1)
+	rl := request.ResourceLogs[0]
+	require.Len(t, rl.Resource.Attributes, 1)
+	assert.Equal(t, "k8s.pod.name", rl.Resource.Attributes[0].Key)
+	assert.Equal(t, "nginx", rl.Resource.Attributes[0].Value.GetStringValue())
+
+	require.Len(t, rl.ScopeLogs, 1)
+	assert.Equal(t, scopeName, rl.ScopeLogs[0].Scope.Name)
+	records := rl.ScopeLogs[0].LogRecords
+	require.Len(t, records, 2)
+
+	body := records[0].Body.GetKvlistValue().GetValues()
+	require.Len(t, body, 2)
+	assert.Equal(t, "comm", body[0].Key)
+	assert.Equal(t, "cat", body[0].Value.GetStringValue())
+	assert.Equal(t, "pid", body[1].Key)
+	assert.Equal(t, int64(42), body[1].Value.GetIntValue())
+
+	assert.Equal(t, severityWarn, records[1].SeverityNumber)
+	assert.Equal(t, "warning", records[1].Body.GetStringValue())
+}
+
+func TestHTTPExport(t *testing.T) {
+	var mu sync.Mutex
+	var requests [][]byte
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, exportLogsPath, r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		requests = append(requests, b)
+	}))
+	defer server.Close()
+
+	c := newHTTPClient(server.URL, true, map[string]string{"Authorization": "secret"})
+	e := newExporter(c, exporterConfig{
+		scopeName:    scopeName,
+		batchSize:    2,
+		batchTimeout: time.Hour,
+		queueSize:    10,
+		timeout:      time.Second,
+		maxRetries:   1,
+		retryBackoff: time.Millisecond,
+	}, logger.DefaultLogger())
+	e.start()
+
+	for i := 0; i < 3; i++ {
+		e.enqueue(&resource{key: "a", record: &logRecord{body: "event"}})
+	}
+	e.stop(time.Second)
+
+	// The first batch is retried, the last event is exported when stopping
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 3, attempts)
+	require.Len(t, requests, 2)
+	request := &collogspb.ExportLogsServiceRequest{}
+	require.NoError(t, proto.Unmarshal(requests[0], request))
+	require.Len(t, request.ResourceLogs, 1)
+	assert.Len(t, request.ResourceLogs[0].ScopeLogs[0].LogRecords, 2)
+}
+
+type deadlineClient struct {
+	mu          sync.Mutex
+	hasDeadline []bool
+}
+
+func (c *deadlineClient) export(ctx context.Context, request *collogspb.ExportLogsServiceRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := ctx.Deadline()
+	c.hasDeadline = append(c.hasDeadline, ok)
+	return nil
+}
+
+func (c *deadlineClient) close() error {
+	return nil
+}
+
+func TestExportTimeout(t *testing.T) {
+	for _, timeout := range []time.Duration{time.Second, 0, -time.Second} {
+		c := &deadlineClient{}
+		e := newExporter(c, exporterConfig{
+			scopeName:    scopeName,
+			batchSize:    1,
+			batchTimeout: time.Hour,
+			queueSize:    10,
+			timeout:      timeout,
+		}, logger.DefaultLogger())
+		e.start()
+		e.enqueue(&resource{key: "a", record: &logRecord{body: "event"}})
+		e.stop(time.Second)
+
+		// Requests without a positive timeout don't expire
+		c.mu.Lock()
+		assert.Equal(t, []bool{timeout > 0}, c.hasDeadline, "timeout %s", timeout)
+		c.mu.Unlock()
+	}
+}
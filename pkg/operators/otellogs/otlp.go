@@ -0,0 +1,152 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otellogs
+
+// This file converts the records collected by the operator into OTLP ExportLogsServiceRequest messages
+
+import (
+	"encoding/json"
+	"sort"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// Severity numbers as defined by the OpenTelemetry logs data model
+const (
+	severityDebug = logspb.SeverityNumber_SEVERITY_NUMBER_DEBUG
+	severityInfo  = logspb.SeverityNumber_SEVERITY_NUMBER_INFO
+	severityWarn  = logspb.SeverityNumber_SEVERITY_NUMBER_WARN
+	severityError = logspb.SeverityNumber_SEVERITY_NUMBER_ERROR
+)
+
+type keyValue struct {
+	key   string
+	value any
+}
+
+type logRecord struct {
+	timeUnixNano         uint64
+	observedTimeUnixNano uint64
+	severityNumber       logspb.SeverityNumber
+	severityText         string
+	body                 any
+	attributes           []keyValue
+}
+
+type resourceLogs struct {
+	attributes []keyValue
+	records    []*logRecord
+}
+
+// newExportLogsRequest returns an ExportLogsServiceRequest message containing the given resource logs
+func newExportLogsRequest(scopeName string, logs []*resourceLogs) *collogspb.ExportLogsServiceRequest {
+	request := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: make([]*logspb.ResourceLogs, 0, len(logs)),
+	}
+	for _, rl := range logs {
+		request.ResourceLogs = append(request.ResourceLogs, toResourceLogs(scopeName, rl))
+	}
+	return request
+}
+
+func toResourceLogs(scopeName string, rl *resourceLogs) *logspb.ResourceLogs {
+	records := make([]*logspb.LogRecord, 0, len(rl.records))
+	for _, record := range rl.records {
+		records = append(records, toLogRecord(record))
+	}
+	return &logspb.ResourceLogs{
+		Resource: &resourcepb.Resource{
+			Attributes: toKeyValues(rl.attributes),
+		},
+		ScopeLogs: []*logspb.ScopeLogs{
+			{
+				Scope:      &commonpb.InstrumentationScope{Name: scopeName},
+				LogRecords: records,
+			},
+		},
+	}
+}
+
+func toLogRecord(record *logRecord) *logspb.LogRecord {
+	res := &logspb.LogRecord{
+		TimeUnixNano:         record.timeUnixNano,
+		ObservedTimeUnixNano: record.observedTimeUnixNano,
+		SeverityNumber:       record.severityNumber,
+		SeverityText:         record.severityText,
+		Attributes:           toKeyValues(record.attributes),
+	}
+	if record.body != nil {
+		res.Body = toAnyValue(record.body)
+	}
+	return res
+}
+
+func toKeyValues(kvs []keyValue) []*commonpb.KeyValue {
+	res := make([]*commonpb.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		res = append(res, &commonpb.KeyValue{Key: kv.key, Value: toAnyValue(kv.value)})
+	}
+	return res
+}
+
+// toAnyValue converts the given value to an AnyValue message; it supports the types returned when decoding JSON
+// using json.Decoder.UseNumber() and a few additional ones
+func toAnyValue(value any) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+	case float64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return toAnyValue(i)
+		}
+		if f, err := v.Float64(); err == nil {
+			return toAnyValue(f)
+		}
+		return toAnyValue(v.String())
+	case []any:
+		values := make([]*commonpb.AnyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, toAnyValue(item))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{
+			ArrayValue: &commonpb.ArrayValue{Values: values},
+		}}
+	case map[string]any:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{
+			KvlistValue: &commonpb.KeyValueList{Values: toKeyValues(sortedKeyValues(v))},
+		}}
+	}
+	return &commonpb.AnyValue{}
+}
+
+func sortedKeyValues(m map[string]any) []keyValue {
+	kvs := make([]keyValue, 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, keyValue{key: k, value: v})
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].key < kvs[j].key
+	})
+	return kvs
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package processtree provides an operator that enriches the events of
+// gadgets with the ancestry, executable and working directory of the process
+// that generated them, using a process tree cache shared by all gadgets.
+package processtree
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/processtree"
+)
+
+const (
+	OperatorName         = "ProcessTree"
+	OperatorInstanceName = "ProcessTreeInstance"
+	ParamProcessTree     = "process-tree"
+)
+
+type processInfoEvent interface {
+	GetPid() uint32
+	SetProcessInfo(ancestors []string, entrypoint, exePath, cwd string)
+}
+
+type processCache interface {
+	Get(pid uint32) *processtree.Process
+	Ancestors(pid uint32) []processtree.Process
+}
+
+type ProcessTree struct {
+	mu   sync.Mutex
+	tree *processtree.Tree
+}
+
+func (p *ProcessTree) Name() string {
+	return OperatorName
+}
+
+func (p *ProcessTree) Description() string {
+	return "Adds the ancestors, executable and working directory of the process to the events"
+}
+
+func (p *ProcessTree) GlobalParamDescs() params.ParamDescs {
+	return nil
+}
+
+func (p *ProcessTree) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{
+		{
+			Key:          ParamProcessTree,
+			DefaultValue: "false",
+			Description:  "Fill the ancestors, entrypoint, exepath and cwd columns of the events",
+			TypeHint:     params.TypeBool,
+		},
+	}
+}
+
+func (p *ProcessTree) Dependencies() []string {
+	return nil
+}
+
+func (p *ProcessTree) CanOperateOn(gadget gadgets.GadgetDesc) bool {
+	_, ok := gadget.EventPrototype().(processInfoEvent)
+	return ok
+}
+
+func (p *ProcessTree) Init(params *params.Params) error {
+	return nil
+}
+
+func (p *ProcessTree) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tree != nil {
+		p.tree.Close()
+		p.tree = nil
+	}
+	return nil
+}
+
+// getTree returns the process tree, creating it the first time it's needed as
+// walking /proc is expensive.
+func (p *ProcessTree) getTree() *processtree.Tree {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.tree == nil {
+		p.tree = processtree.New()
+	}
+	return p.tree
+}
+
+func (p *ProcessTree) Instantiate(gadgetContext operators.GadgetContext, gadgetInstance any, params *params.Params) (operators.OperatorInstance, error) {
+	instance := &processTreeInstance{}
+	if params.Get(ParamProcessTree).AsBool() {
+		instance.cache = p.getTree()
+	}
+	return instance, nil
+}
+
+type processTreeInstance struct {
+	// cache is nil if the operator is disabled
+	cache processCache
+}
+
+func (i *processTreeInstance) Name() string {
+	return OperatorInstanceName
+}
+
+func (i *processTreeInstance) PreGadgetRun() error {
+	return nil
+}
+
+func (i *processTreeInstance) PostGadgetRun() error {
+	return nil
+}
+
+func (i *processTreeInstance) EnrichEvent(ev any) error {
+	if i.cache == nil {
+		return nil
+	}
+	event, ok := ev.(processInfoEvent)
+	if !ok {
+		return nil
+	}
+
+	pid := event.GetPid()
+	process := i.cache.Get(pid)
+	if process == nil {
+		return nil
+	}
+	ancestors := i.cache.Ancestors(pid)
+
+	names := make([]string, len(ancestors))
+	for j, ancestor := range ancestors {
+		names[len(ancestors)-1-j] = fmt.Sprintf("%s(%d)", ancestor.Comm, ancestor.Pid)
+	}
+
+	// The entrypoint is the oldest ancestor in the mount namespace of the
+	// process
+	entrypoint := process.ExePath
+	if process.MntNsID != 0 {
+		for _, ancestor := range ancestors {
+			if ancestor.MntNsID != process.MntNsID {
+				break
+			}
+			entrypoint = ancestor.ExePath
+		}
+	}
+
+	event.SetProcessInfo(names, entrypoint, process.ExePath, process.Cwd)
+	return nil
+}
+
+func init() {
+	operators.Register(&ProcessTree{})
+}
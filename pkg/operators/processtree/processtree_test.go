@@ -0,0 +1,82 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processtree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/processtree"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+type fakeCache map[uint32]processtree.Process
+
+func (c fakeCache) Get(pid uint32) *processtree.Process {
+	p, ok := c[pid]
+	if !ok {
+		return nil
+	}
+	return &p
+}
+
+func (c fakeCache) Ancestors(pid uint32) []processtree.Process {
+	var ancestors []processtree.Process
+	for p, ok := c[pid]; ok; p, ok = c[p.Ppid] {
+		if p.Pid != pid {
+			ancestors = append(ancestors, p)
+		}
+	}
+	return ancestors
+}
+
+type testEvent struct {
+	eventtypes.WithProcessInfo
+	Pid uint32
+}
+
+func (e *testEvent) GetPid() uint32 {
+	return e.Pid
+}
+
+func TestEnrichEvent(t *testing.T) {
+	cache := fakeCache{
+		1:   {Pid: 1, Comm: "systemd", ExePath: "/usr/lib/systemd/systemd", MntNsID: 1},
+		100: {Pid: 100, Ppid: 1, Comm: "containerd-shim", ExePath: "/usr/bin/containerd-shim", MntNsID: 1},
+		200: {Pid: 200, Ppid: 100, Comm: "nginx", ExePath: "/usr/sbin/nginx", MntNsID: 2},
+		300: {Pid: 300, Ppid: 200, Comm: "sh", ExePath: "/bin/sh", MntNsID: 2},
+		400: {Pid: 400, Ppid: 300, Comm: "curl", ExePath: "/usr/bin/curl", Cwd: "/tmp", MntNsID: 2},
+	}
+
+	instance := &processTreeInstance{cache: cache}
+
+	ev := &testEvent{Pid: 400}
+	require.NoError(t, instance.EnrichEvent(ev))
+	require.Equal(t, []string{"systemd(1)", "containerd-shim(100)", "nginx(200)", "sh(300)"}, ev.Ancestors)
+	require.Equal(t, "/usr/sbin/nginx", ev.Entrypoint)
+	require.Equal(t, "/usr/bin/curl", ev.ExePath)
+	require.Equal(t, "/tmp", ev.Cwd)
+
+	// Unknown processes are not enriched
+	ev = &testEvent{Pid: 500}
+	require.NoError(t, instance.EnrichEvent(ev))
+	require.Empty(t, ev.Ancestors)
+
+	// Disabled operator
+	ev = &testEvent{Pid: 400}
+	require.NoError(t, (&processTreeInstance{}).EnrichEvent(ev))
+	require.Empty(t, ev.Ancestors)
+}
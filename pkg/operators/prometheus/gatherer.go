@@ -0,0 +1,133 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// gatherer exports the metrics of all meter providers handed out by newMeterProvider. Every meter provider uses its
+// own exporter and registry, so it can be removed again without affecting the others.
+type gatherer struct {
+	mu         sync.Mutex
+	registries map[*prometheus.Registry]struct{}
+}
+
+func newGatherer() *gatherer {
+	return &gatherer{
+		registries: make(map[*prometheus.Registry]struct{}),
+	}
+}
+
+// newMeterProvider implements igprometheus.MeterProviderFactory
+func (g *gatherer) newMeterProvider(views ...sdkmetric.View) (metric.MeterProvider, func(), error) {
+	registry := prometheus.NewRegistry()
+	exporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, fmt.Errorf("initialize prometheus exporter: %w", err)
+	}
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(exporter),
+		sdkmetric.WithView(views...),
+	)
+
+	g.mu.Lock()
+	g.registries[registry] = struct{}{}
+	g.mu.Unlock()
+
+	release := func() {
+		g.mu.Lock()
+		delete(g.registries, registry)
+		g.mu.Unlock()
+
+		meterProvider.Shutdown(context.Background())
+	}
+	return meterProvider, release, nil
+}
+
+// Gather implements prometheus.Gatherer. Metrics with the same name and labels are only returned once: all exporters
+// report the target_info metric and meter providers sharing a scope its otel_scope_info metric.
+func (g *gatherer) Gather() ([]*dto.MetricFamily, error) {
+	g.mu.Lock()
+	registries := make([]*prometheus.Registry, 0, len(g.registries))
+	for registry := range g.registries {
+		registries = append(registries, registry)
+	}
+	g.mu.Unlock()
+
+	families := make(map[string]*dto.MetricFamily)
+	seen := make(map[string]struct{})
+	var errs prometheus.MultiError
+	for _, registry := range registries {
+		mfs, err := registry.Gather()
+		if err != nil {
+			errs.Append(err)
+		}
+		for _, mf := range mfs {
+			family, ok := families[mf.GetName()]
+			if !ok {
+				family = &dto.MetricFamily{
+					Name: mf.Name,
+					Help: mf.Help,
+					Type: mf.Type,
+				}
+				families[mf.GetName()] = family
+			}
+			if family.GetType() != mf.GetType() {
+				errs.Append(fmt.Errorf("metric %q is exported with different types", mf.GetName()))
+				continue
+			}
+			for _, m := range mf.Metric {
+				key := metricKey(mf.GetName(), m)
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+				family.Metric = append(family.Metric, m)
+			}
+		}
+	}
+
+	res := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		res = append(res, family)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].GetName() < res[j].GetName()
+	})
+	return res, errs.MaybeUnwrap()
+}
+
+func metricKey(name string, m *dto.Metric) string {
+	var sb strings.Builder
+	sb.WriteString(name)
+	for _, label := range m.Label {
+		sb.WriteString("\x00")
+		sb.WriteString(label.GetName())
+		sb.WriteString("=")
+		sb.WriteString(label.GetValue())
+	}
+	return sb.String()
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatherer(t *testing.T) {
+	g := newGatherer()
+	ctx := context.Background()
+
+	counter := func(name string) func() {
+		meterProvider, release, err := g.newMeterProvider()
+		require.NoError(t, err)
+		c, err := meterProvider.Meter("gadgets.inspektor-gadget.io/test").Int64Counter(name)
+		require.NoError(t, err)
+		c.Add(ctx, 1)
+		return release
+	}
+
+	releaseFirst := counter("first")
+	releaseSecond := counter("second")
+	t.Cleanup(releaseSecond)
+
+	families := func() map[string]int {
+		mfs, err := g.Gather()
+		require.NoError(t, err)
+		res := map[string]int{}
+		for _, mf := range mfs {
+			res[mf.GetName()] = len(mf.Metric)
+		}
+		return res
+	}
+
+	// Info metrics reported by both exporters are only returned once
+	require.Equal(t, map[string]int{
+		"first_total":     1,
+		"second_total":    1,
+		"otel_scope_info": 1,
+		"target_info":     1,
+	}, families())
+
+	// Released meter providers aren't exported anymore
+	releaseFirst()
+	require.Equal(t, map[string]int{
+		"second_total":    1,
+		"otel_scope_info": 1,
+		"target_info":     1,
+	}, families())
+}
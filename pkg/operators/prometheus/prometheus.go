@@ -15,22 +15,22 @@
 package prometheus
 
 import (
-	"fmt"
 	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
-	"go.opentelemetry.io/otel/exporters/prometheus"
-	"go.opentelemetry.io/otel/metric"
-	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	igprometheus "github.com/inspektor-gadget/inspektor-gadget/pkg/prometheus"
 )
 
-type SetMetricsProvider interface {
-	SetMetricsProvider(metric.MeterProvider)
+// SetMeterProviderFactory is implemented by gadgets creating metrics. Every meter provider handed out by the factory
+// is exported on its own, so metrics can be removed or changed while others keep running.
+type SetMeterProviderFactory interface {
+	SetMeterProviderFactory(igprometheus.MeterProviderFactory)
 }
 
 const (
@@ -43,8 +43,7 @@ const (
 )
 
 type Prometheus struct {
-	exporter      *prometheus.Exporter
-	meterProvider metric.MeterProvider
+	gatherer *gatherer
 }
 
 func (l *Prometheus) EnrichEvent(a any) error {
@@ -98,19 +97,19 @@ func (l *Prometheus) Init(globalParams *params.Params) error {
 	//	return nil
 	//}
 
-	exporter, err := prometheus.New()
-	if err != nil {
-		return fmt.Errorf("initialize prometheus exporter: %w", err)
-	}
-	l.exporter = exporter
-	l.meterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter))
+	l.gatherer = newGatherer()
 
 	listenAddress := globalParams.Get(ParamListenAddress).AsString()
 	metricsPath := globalParams.Get(ParamMetricsPath).AsString()
 
+	handler := promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.Gatherers{prometheus.DefaultGatherer, l.gatherer}, promhttp.HandlerOpts{}),
+	)
+
 	go func() {
 		mux := http.NewServeMux()
-		mux.Handle(metricsPath, promhttp.Handler())
+		mux.Handle(metricsPath, handler)
 		err := http.ListenAndServe(listenAddress, mux)
 		if err != nil {
 			log.Errorf("serving http: %s", err)
@@ -129,7 +128,7 @@ func (l *Prometheus) CanOperateOn(gadget gadgets.GadgetDesc) bool {
 	if err != nil {
 		return false
 	}
-	if _, ok := tempInstance.(SetMetricsProvider); !ok {
+	if _, ok := tempInstance.(SetMeterProviderFactory); !ok {
 		return false
 	}
 	return true
@@ -140,8 +139,8 @@ func (l *Prometheus) Close() error {
 }
 
 func (l *Prometheus) Instantiate(gadgetCtx operators.GadgetContext, gadgetInstance any, params *params.Params) (operators.OperatorInstance, error) {
-	if setter, ok := gadgetInstance.(SetMetricsProvider); ok {
-		setter.SetMetricsProvider(l.meterProvider)
+	if setter, ok := gadgetInstance.(SetMeterProviderFactory); ok {
+		setter.SetMeterProviderFactory(l.gatherer.newMeterProvider)
 	}
 	return l, nil
 }
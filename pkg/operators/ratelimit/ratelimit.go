@@ -0,0 +1,465 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit provides an operator that reduces the amount of events emitted by trace gadgets by applying
+// token-bucket rate limits, 1-in-N sampling and deduplication of identical events. Suppressed events are counted
+// and reported periodically using summary events.
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/operators"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+const (
+	OperatorName         = "RateLimit"
+	OperatorInstanceName = "RateLimitInstance"
+	ParamRateLimit       = "rate-limit"
+	ParamRateLimitBurst  = "rate-limit-burst"
+	ParamRateLimitBy     = "rate-limit-by"
+	ParamSample          = "sample"
+	ParamDedupWindow     = "dedup-window"
+	ParamSummaryInterval = "suppression-summary-interval"
+)
+
+// keyTimeout is the time after which the state of a key that didn't see any events is removed
+const keyTimeout = time.Minute
+
+type reason int
+
+const (
+	reasonRateLimit reason = iota
+	reasonSampling
+	reasonDuplicate
+	reasonCount
+)
+
+type eventTypeGetter interface {
+	GetType() eventtypes.EventType
+}
+
+type RateLimit struct{}
+
+func (r *RateLimit) Name() string {
+	return OperatorName
+}
+
+func (r *RateLimit) Description() string {
+	return "Limits the rate of events using token buckets, sampling and deduplication"
+}
+
+func (r *RateLimit) GlobalParamDescs() params.ParamDescs {
+	return nil
+}
+
+func (r *RateLimit) ParamDescs() params.ParamDescs {
+	return params.ParamDescs{
+		{
+			Key:          ParamRateLimit,
+			DefaultValue: "0",
+			Description:  "Maximum number of events per second for each container (or key, see --rate-limit-by); 0 disables rate limiting",
+			TypeHint:     params.TypeUint,
+		},
+		{
+			Key:          ParamRateLimitBurst,
+			DefaultValue: "0",
+			Description:  "Number of events that can exceed the rate limit in bursts; 0 uses the value of --rate-limit",
+			TypeHint:     params.TypeUint,
+		},
+		{
+			Key:         ParamRateLimitBy,
+			Description: "Comma-separated list of columns to use as key for rate limiting and sampling instead of the container",
+		},
+		{
+			Key:          ParamSample,
+			DefaultValue: "0",
+			Description:  "Only emit one out of the given number of events for each container (or key, see --rate-limit-by); 0 or 1 disable sampling",
+			TypeHint:     params.TypeUint,
+		},
+		{
+			Key:          ParamDedupWindow,
+			DefaultValue: "0s",
+			Description:  "Suppress events that are identical (except for the timestamp) to an event seen within the given duration; 0 disables deduplication",
+			TypeHint:     params.TypeDuration,
+		},
+		{
+			Key:          ParamSummaryInterval,
+			DefaultValue: "10s",
+			Description:  "Interval to emit summaries with the number of suppressed events; 0 disables summaries",
+			TypeHint:     params.TypeDuration,
+		},
+	}
+}
+
+func (r *RateLimit) Dependencies() []string {
+	return nil
+}
+
+// FiltersEvents makes sure the operator sees events after they were enriched by all other operators
+func (r *RateLimit) FiltersEvents() bool {
+	return true
+}
+
+func (r *RateLimit) CanOperateOn(gadget gadgets.GadgetDesc) bool {
+	return gadget.Type() == gadgets.TypeTrace && gadget.EventPrototype() != nil
+}
+
+func (r *RateLimit) Init(params *params.Params) error {
+	return nil
+}
+
+func (r *RateLimit) Close() error {
+	return nil
+}
+
+func (r *RateLimit) Instantiate(gadgetContext operators.GadgetContext, gadgetInstance any, params *params.Params) (operators.OperatorInstance, error) {
+	instance := &rateLimitInstance{
+		gadgetCtx:       gadgetContext,
+		rate:            float64(params.Get(ParamRateLimit).AsUint()),
+		burst:           float64(params.Get(ParamRateLimitBurst).AsUint()),
+		sample:          params.Get(ParamSample).AsUint64(),
+		dedupWindow:     params.Get(ParamDedupWindow).AsDuration(),
+		summaryInterval: params.Get(ParamSummaryInterval).AsDuration(),
+		keys:            make(map[string]*keyState),
+		seen:            make(map[uint64]time.Time),
+		now:             time.Now,
+	}
+	if instance.burst == 0 {
+		instance.burst = instance.rate
+	}
+
+	instance.enabled = instance.rate > 0 || instance.sample > 1 || instance.dedupWindow > 0
+	if !instance.enabled {
+		return instance, nil
+	}
+
+	instance.keyFunc = containerKey
+	if keyColumns := params.Get(ParamRateLimitBy).AsStringSlice(); len(keyColumns) > 0 {
+		keyFunc, err := columnsKeyFunc(gadgetContext.GadgetDesc().Parser(), keyColumns)
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %w", ParamRateLimitBy, err)
+		}
+		instance.keyFunc = keyFunc
+	}
+
+	if prototype := gadgetContext.GadgetDesc().EventPrototype(); reflect.TypeOf(prototype).Kind() == reflect.Pointer {
+		instance.eventType = reflect.TypeOf(prototype).Elem()
+	}
+
+	return instance, nil
+}
+
+// containerKey returns a key for the container the event belongs to
+func containerKey(ev any) string {
+	if c, ok := ev.(operators.ContainerInfoGetters); ok && c.GetContainer() != "" {
+		if c.GetPod() == "" {
+			return c.GetContainer()
+		}
+		return c.GetNamespace() + "/" + c.GetPod() + "/" + c.GetContainer()
+	}
+	if m, ok := ev.(interface{ GetMountNSID() uint64 }); ok {
+		return fmt.Sprintf("mntns %d", m.GetMountNSID())
+	}
+	return ""
+}
+
+// columnsKeyFunc returns a function that creates a key from the values of the given columns
+func columnsKeyFunc(p parser.Parser, columnNames []string) (func(any) string, error) {
+	if p == nil {
+		return nil, fmt.Errorf("gadget doesn't support columns")
+	}
+	attrsGetter, err := p.AttrsGetter(columnNames)
+	if err != nil {
+		return nil, err
+	}
+	return func(ev any) string {
+		attrs := attrsGetter(ev)
+		parts := make([]string, 0, len(attrs))
+		for _, attr := range attrs {
+			parts = append(parts, fmt.Sprintf("%s=%s", attr.Key, attr.Value.Emit()))
+		}
+		return strings.Join(parts, ",")
+	}, nil
+}
+
+type keyState struct {
+	// token bucket
+	tokens float64
+	last   time.Time
+
+	count      uint64
+	lastSeen   time.Time
+	suppressed [reasonCount]uint64
+	commonData eventtypes.CommonData
+}
+
+type rateLimitInstance struct {
+	gadgetCtx operators.GadgetContext
+	enabled   bool
+	eventType reflect.Type
+	keyFunc   func(any) string
+	emit      func(ev any)
+	now       func() time.Time
+
+	rate            float64
+	burst           float64
+	sample          uint64
+	dedupWindow     time.Duration
+	summaryInterval time.Duration
+
+	mu   sync.Mutex
+	keys map[string]*keyState
+	seen map[uint64]time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (r *rateLimitInstance) Name() string {
+	return OperatorInstanceName
+}
+
+func (r *rateLimitInstance) SetEventEmitter(emit func(ev any)) {
+	r.emit = emit
+}
+
+func (r *rateLimitInstance) PreGadgetRun() error {
+	if !r.enabled {
+		return nil
+	}
+
+	interval := r.summaryInterval
+	if interval <= 0 {
+		interval = keyTimeout
+	}
+
+	r.done = make(chan struct{})
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+				r.flush(interval)
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *rateLimitInstance) PostGadgetRun() error {
+	if r.done == nil {
+		return nil
+	}
+	close(r.done)
+	r.wg.Wait()
+	r.flush(0)
+	return nil
+}
+
+func (r *rateLimitInstance) EnrichEvent(ev any) error {
+	return nil
+}
+
+// EnrichFilteredEvent applies the limits to the events that matched the filters of the parser, so the events dropped
+// by --filter don't count
+func (r *rateLimitInstance) EnrichFilteredEvent(ev any) error {
+	if !r.enabled {
+		return nil
+	}
+	if e, ok := ev.(eventTypeGetter); ok && e.GetType() != eventtypes.NORMAL {
+		return nil
+	}
+
+	key := r.keyFunc(ev)
+
+	var hash uint64
+	if r.dedupWindow > 0 {
+		hash = r.hash(ev)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+
+	state, ok := r.keys[key]
+	if !ok {
+		state = &keyState{tokens: r.burst, last: now}
+		if c, ok := ev.(operators.ContainerInfoGetters); ok {
+			state.commonData = eventtypes.CommonData{
+				Node:      c.GetNode(),
+				Namespace: c.GetNamespace(),
+				Pod:       c.GetPod(),
+				Container: c.GetContainer(),
+			}
+		}
+		r.keys[key] = state
+	}
+	state.lastSeen = now
+
+	if r.dedupWindow > 0 {
+		if first, ok := r.seen[hash]; ok && now.Sub(first) < r.dedupWindow {
+			state.suppressed[reasonDuplicate]++
+			return parser.ErrDropEvent
+		}
+		r.seen[hash] = now
+	}
+
+	if r.sample > 1 {
+		state.count++
+		if (state.count-1)%r.sample != 0 {
+			state.suppressed[reasonSampling]++
+			return parser.ErrDropEvent
+		}
+	}
+
+	if r.rate > 0 {
+		state.tokens += now.Sub(state.last).Seconds() * r.rate
+		if state.tokens > r.burst {
+			state.tokens = r.burst
+		}
+		state.last = now
+		if state.tokens < 1 {
+			state.suppressed[reasonRateLimit]++
+			return parser.ErrDropEvent
+		}
+		state.tokens--
+	}
+
+	return nil
+}
+
+// hash returns a hash of the event, ignoring its timestamp
+func (r *rateLimitInstance) hash(ev any) uint64 {
+	v := reflect.ValueOf(ev)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		evCopy := reflect.New(v.Elem().Type())
+		evCopy.Elem().Set(v.Elem())
+		if evCopy.Elem().Kind() == reflect.Struct {
+			if ts := evCopy.Elem().FieldByName("Timestamp"); ts.IsValid() && ts.CanSet() {
+				ts.Set(reflect.Zero(ts.Type()))
+			}
+		}
+		ev = evCopy.Interface()
+	}
+
+	h := fnv.New64a()
+	if err := json.NewEncoder(h).Encode(ev); err != nil {
+		fmt.Fprintf(h, "%+v", ev)
+	}
+	return h.Sum64()
+}
+
+// flush emits summaries for all keys that had suppressed events and removes stale state; interval is used for the
+// summary message only
+func (r *rateLimitInstance) flush(interval time.Duration) {
+	r.mu.Lock()
+	now := r.now()
+
+	type summary struct {
+		key   string
+		state keyState
+	}
+	var summaries []summary
+	for key, state := range r.keys {
+		var total uint64
+		for _, n := range state.suppressed {
+			total += n
+		}
+		if total > 0 {
+			summaries = append(summaries, summary{key: key, state: *state})
+			state.suppressed = [reasonCount]uint64{}
+		}
+		if now.Sub(state.lastSeen) > keyTimeout {
+			delete(r.keys, key)
+		}
+	}
+	for hash, first := range r.seen {
+		if now.Sub(first) >= r.dedupWindow {
+			delete(r.seen, hash)
+		}
+	}
+	r.mu.Unlock()
+
+	if r.summaryInterval <= 0 {
+		return
+	}
+	for _, s := range summaries {
+		r.emitSummary(s.key, &s.state, interval)
+	}
+}
+
+func (r *rateLimitInstance) emitSummary(key string, state *keyState, interval time.Duration) {
+	suppressed := state.suppressed
+	total := suppressed[reasonRateLimit] + suppressed[reasonSampling] + suppressed[reasonDuplicate]
+
+	msg := fmt.Sprintf("suppressed %d events", total)
+	if key != "" {
+		msg += fmt.Sprintf(" for %q", key)
+	}
+	if interval > 0 {
+		msg += fmt.Sprintf(" in the last %s", interval)
+	}
+	msg += fmt.Sprintf(" (rate limit: %d, sampling: %d, duplicates: %d)",
+		suppressed[reasonRateLimit], suppressed[reasonSampling], suppressed[reasonDuplicate])
+
+	if ev := r.newSummaryEvent(msg, state.commonData); ev != nil && r.emit != nil {
+		r.emit(ev)
+		return
+	}
+	r.gadgetCtx.Logger().Infof("%s", msg)
+}
+
+// newSummaryEvent creates an info event of the type emitted by the gadget; it returns nil if the type doesn't embed
+// eventtypes.Event
+func (r *rateLimitInstance) newSummaryEvent(msg string, commonData eventtypes.CommonData) any {
+	if r.eventType == nil || r.eventType.Kind() != reflect.Struct {
+		return nil
+	}
+	ev := reflect.New(r.eventType)
+	field := ev.Elem().FieldByName("Event")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(eventtypes.Event{}) {
+		return nil
+	}
+
+	baseEvent := eventtypes.Info(msg)
+	baseEvent.Timestamp = eventtypes.Time(r.now().UnixNano())
+	if commonData.Node == "" {
+		commonData.Node = baseEvent.Node
+	}
+	baseEvent.CommonData = commonData
+	field.Set(reflect.ValueOf(baseEvent))
+
+	return ev.Interface()
+}
+
+func init() {
+	operators.Register(&RateLimit{})
+}
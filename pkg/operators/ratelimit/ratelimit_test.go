@@ -0,0 +1,188 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+	eventtypes "github.com/inspektor-gadget/inspektor-gadget/pkg/types"
+)
+
+type testEvent struct {
+	eventtypes.Event
+	Comm string `json:"comm"`
+}
+
+func newEvent(container, comm string, ts int64) *testEvent {
+	return &testEvent{
+		Event: eventtypes.Event{
+			CommonData: eventtypes.CommonData{Container: container},
+			Timestamp:  eventtypes.Time(ts),
+			Type:       eventtypes.NORMAL,
+		},
+		Comm: comm,
+	}
+}
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time {
+	return c.t
+}
+
+func newTestInstance(clock *testClock) *rateLimitInstance {
+	return &rateLimitInstance{
+		enabled:         true,
+		eventType:       reflect.TypeOf(testEvent{}),
+		keyFunc:         containerKey,
+		summaryInterval: 10 * time.Second,
+		keys:            make(map[string]*keyState),
+		seen:            make(map[uint64]time.Time),
+		now:             clock.now,
+	}
+}
+
+func countPassed(r *rateLimitInstance, events ...*testEvent) int {
+	passed := 0
+	for _, ev := range events {
+		if r.EnrichFilteredEvent(ev) == nil {
+			passed++
+		}
+	}
+	return passed
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	r := newTestInstance(clock)
+	r.rate = 2
+	r.burst = 2
+
+	for i := 0; i < 5; i++ {
+		err := r.EnrichFilteredEvent(newEvent("a", "cat", int64(i)))
+		if i < 2 {
+			assert.NoError(t, err)
+		} else {
+			assert.ErrorIs(t, err, parser.ErrDropEvent)
+		}
+	}
+
+	// Other containers have their own bucket
+	assert.NoError(t, r.EnrichFilteredEvent(newEvent("b", "cat", 0)))
+
+	// Tokens are refilled over time
+	clock.t = clock.t.Add(500 * time.Millisecond)
+	assert.Equal(t, 1, countPassed(r, newEvent("a", "cat", 0), newEvent("a", "cat", 0)))
+
+	// Non-normal events are never suppressed
+	info := newEvent("a", "cat", 0)
+	info.Type = eventtypes.INFO
+	assert.NoError(t, r.EnrichFilteredEvent(info))
+
+	assert.Equal(t, uint64(4), r.keys["a"].suppressed[reasonRateLimit])
+}
+
+func TestSampling(t *testing.T) {
+	r := newTestInstance(&testClock{t: time.Unix(1000, 0)})
+	r.sample = 3
+
+	events := make([]*testEvent, 0, 9)
+	for i := 0; i < 9; i++ {
+		events = append(events, newEvent("a", "cat", int64(i)))
+	}
+	assert.Equal(t, 3, countPassed(r, events...))
+	assert.Equal(t, uint64(6), r.keys["a"].suppressed[reasonSampling])
+}
+
+func TestDedup(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	r := newTestInstance(clock)
+	r.dedupWindow = time.Second
+
+	// The timestamp is ignored when comparing events
+	assert.Equal(t, 2, countPassed(r,
+		newEvent("a", "cat", 1),
+		newEvent("a", "cat", 2),
+		newEvent("a", "ls", 3),
+	))
+
+	clock.t = clock.t.Add(time.Second)
+	assert.Equal(t, 1, countPassed(r, newEvent("a", "cat", 4)))
+	assert.Equal(t, uint64(1), r.keys["a"].suppressed[reasonDuplicate])
+}
+
+func TestSummary(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	r := newTestInstance(clock)
+	r.sample = 2
+
+	var emitted []*testEvent
+	r.SetEventEmitter(func(ev any) {
+		emitted = append(emitted, ev.(*testEvent))
+	})
+
+	countPassed(r, newEvent("a", "cat", 0), newEvent("a", "cat", 0), newEvent("b", "cat", 0))
+	r.flush(10 * time.Second)
+
+	require.Len(t, emitted, 1)
+	assert.Equal(t, eventtypes.INFO, emitted[0].Type)
+	assert.Equal(t, "a", emitted[0].Container)
+	assert.Equal(t, `suppressed 1 events for "a" in the last 10s (rate limit: 0, sampling: 1, duplicates: 0)`, emitted[0].Message)
+
+	// Counters are reset after a summary and stale keys are removed
+	emitted = nil
+	clock.t = clock.t.Add(2 * keyTimeout)
+	r.flush(10 * time.Second)
+	assert.Empty(t, emitted)
+	assert.Empty(t, r.keys)
+}
+
+func TestRecordingWithRateLimit(t *testing.T) {
+	r := newTestInstance(&testClock{t: time.Unix(1000, 0)})
+	r.rate = 1
+	r.burst = 1
+
+	p := parser.NewParser[testEvent](columns.MustCreateColumns[testEvent]())
+
+	var recorded, passed []*testEvent
+	p.SetRecordCallback(func(key string, ev any) {
+		recorded = append(recorded, ev.(*testEvent))
+	})
+	p.SetEventCallback(func(ev *testEvent) {
+		passed = append(passed, ev)
+	})
+	p.SetFilteredEnricher(r.EnrichFilteredEvent)
+	handler := p.EventHandlerFunc(r.EnrichEvent).(func(*testEvent))
+
+	for i := 0; i < 3; i++ {
+		handler(newEvent("a", "cat", int64(i)))
+	}
+
+	// Events dropped by the rate limit are part of the recording
+	assert.Len(t, passed, 1)
+	require.Len(t, recorded, 3)
+	for i, ev := range recorded {
+		assert.Equal(t, eventtypes.Time(i), ev.Timestamp)
+	}
+}
@@ -17,7 +17,6 @@ package parser
 import (
 	"encoding/json"
 
-	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/types"
 )
@@ -42,16 +41,23 @@ type ErrorGetter interface {
 	GetMessage() string
 }
 
+// entryFormatter is implemented by the formatters in pkg/columns/formatter
+type entryFormatter[T any] interface {
+	FormatHeader() string
+	FormatEntry(*T) string
+	SetShowColumns([]string) error
+}
+
 // outputHelpers hides all information about underlying types from the application
 type outputHelper[T any] struct {
 	parser *parser[T]
-	*textcolumns.TextColumnsFormatter[T]
+	entryFormatter[T]
 	eventCallback    func(string)
 	enableExtraLines bool
 }
 
 func (oh *outputHelper[T]) forwardEvent(ev *T) {
-	oh.eventCallback(oh.TextColumnsFormatter.FormatEntry(ev))
+	oh.eventCallback(oh.entryFormatter.FormatEntry(ev))
 	if !oh.enableExtraLines {
 		return
 	}
@@ -122,7 +128,7 @@ func (oh *outputHelper[T]) TransformEvent(line string) (string, error) {
 }
 
 func (oh *outputHelper[T]) SetShowColumns(cols []string) error {
-	return oh.TextColumnsFormatter.SetShowColumns(cols)
+	return oh.entryFormatter.SetShowColumns(cols)
 }
 
 func (oh *outputHelper[T]) SetEnableExtraLines(newVal bool) {
@@ -23,16 +23,22 @@ package parser
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/aggregate"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/filter"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/tablecolumns"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/jsonschema"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/sort"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/snapshotcombiner"
@@ -40,6 +46,10 @@ import (
 
 type LogCallback func(severity logger.Level, fmt string, params ...any)
 
+// RecordCallback receives every event (*T) or array of events ([]*T) after enrichment and before filtering. key
+// holds the source of the events (e.g. the node) if known.
+type RecordCallback func(key string, ev any)
+
 type GaugeVal struct {
 	Attrs      []attribute.KeyValue
 	Int64Val   int64
@@ -51,9 +61,22 @@ type Parser interface {
 	// GetTextColumnsFormatter returns the default formatter for this columns instance
 	GetTextColumnsFormatter(options ...textcolumns.Option) TextColumnsFormatter
 
+	// GetTableColumnsFormatter returns a formatter for this columns instance that outputs CSV, TSV or Markdown
+	// tables, depending on the given options
+	GetTableColumnsFormatter(options ...tablecolumns.Option) TextColumnsFormatter
+
+	// NewView returns a View that keeps the latest batch of entries and renders it according to settings that can
+	// be changed at runtime; used by interactive frontends
+	NewView(options ...textcolumns.Option) View
+
 	// GetColumnAttributes returns a map of column names to their respective attributes
 	GetColumnAttributes() []columns.Attributes
 
+	// ValuesFunc returns a function that accepts an instance of type *T and returns the values of the given columns
+	// as strings. Columns that have a unit set are returned as raw numbers, so that frontends can format and sort
+	// them on their own.
+	ValuesFunc(columnNames []string) (func(any) []string, error)
+
 	// GetDefaultColumns returns a list of columns that are visible by default; optionally, hiddenTags will
 	// hide columns that contain any of the given tags
 	GetDefaultColumns(hiddenTags ...string) []string
@@ -61,6 +84,10 @@ type Parser interface {
 	// GetColumns returns the underlying columns definition (mainly used for serialization)
 	GetColumns() any
 
+	// JSONSchema returns a JSON Schema describing events as they are marshaled to JSON, annotated with the
+	// information of the columns
+	JSONSchema(title string) *jsonschema.Schema
+
 	// VerifyColumnNames takes a list of column names and returns two lists, one containing the
 	// valid column names and another containing the invalid column names. Prefixes like "-" for
 	// descending sorting will be ignored.
@@ -77,6 +104,12 @@ type Parser interface {
 	// SetFilters sets which filter to apply before emitting events downstream
 	SetFilters([]string) error
 
+	// EqualityFilters returns the values of all filters set using SetFilters that require a column to have an exact
+	// value ("columnName:value"), limited to the given columns. This is used to push filters down to gadgets that
+	// can apply them in eBPF already. While recording (see SetRecordCallback) no filters are returned, as the
+	// recording needs to contain the events those filters would drop.
+	EqualityFilters(columnNames ...string) map[string]string
+
 	// EventHandlerFunc returns a function that accepts an instance of type *T and pushes it downstream after applying
 	// enrichers and filters
 	EventHandlerFunc(enrichers ...func(any) error) any
@@ -93,6 +126,15 @@ type Parser interface {
 	// SetLogCallback sets the function to use to send log messages
 	SetLogCallback(logCallback LogCallback)
 
+	// SetRecordCallback sets a function that will receive all events before filters are applied; this is used to
+	// record the raw event stream of a gadget run
+	SetRecordCallback(recordCallback RecordCallback)
+
+	// SetFilteredEnricher sets a function that receives the events matching the filters set using SetFilters, before
+	// they're pushed downstream. It can drop events by returning ErrDropEvent. This is used by operators that need to
+	// see the events the user asked for, like rate limiting or exporting operators.
+	SetFilteredEnricher(filteredEnricher func(any) error)
+
 	// EnableSnapshots initializes the snapshot combiner, which is able to aggregate snapshots from several sources
 	// and can return (optionally cached) results on demand; used for top gadgets
 	EnableSnapshots(ctx context.Context, t time.Duration, ttl int)
@@ -101,6 +143,11 @@ type Parser interface {
 	// Events are released by calling Flush().
 	EnableCombiner()
 
+	// EnableAggregation makes the parser aggregate events according to spec (see package aggregate) instead of
+	// emitting them one by one. Every interval, the resulting rows are handed over to callback and the aggregation
+	// starts over. It has to be called before requesting event handlers.
+	EnableAggregation(ctx context.Context, interval time.Duration, spec *aggregate.Spec, callback func([]*aggregate.Row)) error
+
 	// Flush sends the events downstream that were collected after EnableCombiner() was called.
 	Flush()
 
@@ -125,6 +172,9 @@ type Parser interface {
 	ColFloatGetter(colName string) (func(any) float64, error)
 }
 
+// ErrDropEvent can be returned by enrichers to signal that an event should be dropped
+var ErrDropEvent = errors.New("drop event")
+
 type parser[T any] struct {
 	columns            *columns.Columns[T]
 	sortBy             []string
@@ -134,9 +184,17 @@ type parser[T any] struct {
 	eventCallback      func(*T)
 	eventCallbackArray func([]*T)
 	logCallback        LogCallback
+	recordCallback     RecordCallback
+	filteredEnricher   func(any) error
 	snapshotCombiner   *snapshotcombiner.SnapshotCombiner[T]
 	columnFilters      []columns.ColumnFilter
 
+	// aggregation related fields
+	aggregator          *aggregate.Aggregator[T]
+	aggregationCallback func([]*aggregate.Row)
+	aggregationStop     chan struct{}
+	aggregationWg       sync.WaitGroup
+
 	// event combiner related fields
 	eventCombinerEnabled bool
 	combinedEvents       []*T
@@ -179,6 +237,41 @@ func (p *parser[T]) flushSnapshotCombiner() {
 	p.eventCallbackArray(out)
 }
 
+func (p *parser[T]) EnableAggregation(
+	ctx context.Context,
+	interval time.Duration,
+	spec *aggregate.Spec,
+	callback func([]*aggregate.Row),
+) error {
+	if interval <= 0 {
+		return fmt.Errorf("aggregation interval must be greater than zero")
+	}
+	aggregator, err := aggregate.NewAggregator(p.columns.GetColumnMap(p.columnFilters...), spec)
+	if err != nil {
+		return err
+	}
+	p.aggregator = aggregator
+	p.aggregationCallback = callback
+	p.aggregationStop = make(chan struct{})
+	p.aggregationWg.Add(1)
+	go func() {
+		defer p.aggregationWg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.aggregationCallback(p.aggregator.Flush())
+			case <-p.aggregationStop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
 func (p *parser[T]) EnableCombiner() {
 	if p.eventCallbackArray == nil {
 		panic("eventCallbackArray has to be set before using EnableCombiner()")
@@ -189,6 +282,20 @@ func (p *parser[T]) EnableCombiner() {
 }
 
 func (p *parser[T]) Flush() {
+	if p.aggregator != nil {
+		// Stop the periodic flushing first, so it can't run concurrently with the final one
+		if p.aggregationStop != nil {
+			close(p.aggregationStop)
+			p.aggregationStop = nil
+		}
+		p.aggregationWg.Wait()
+
+		// Only emit the last (incomplete) interval if there is something to show
+		if rows := p.aggregator.Flush(); len(rows) > 0 {
+			p.aggregationCallback(rows)
+		}
+		return
+	}
 	if p.snapshotCombiner != nil {
 		p.flushSnapshotCombiner()
 		return
@@ -207,6 +314,14 @@ func (p *parser[T]) SetLogCallback(logCallback LogCallback) {
 	p.logCallback = logCallback
 }
 
+func (p *parser[T]) SetRecordCallback(recordCallback RecordCallback) {
+	p.recordCallback = recordCallback
+}
+
+func (p *parser[T]) SetFilteredEnricher(filteredEnricher func(any) error) {
+	p.filteredEnricher = filteredEnricher
+}
+
 func (p *parser[T]) SetEventCallback(eventCallback any) {
 	switch cb := eventCallback.(type) {
 	case func(*T):
@@ -233,26 +348,55 @@ func (p *parser[T]) eventHandler(cb func(*T), enrichers ...func(any) error) func
 		panic("cb can't be nil in eventHandler from parser")
 	}
 	return func(ev *T) {
+		drop := false
 		for _, enricher := range enrichers {
-			enricher(ev)
+			// Keep running the other enrichers, so dropped events are recorded complete
+			if err := enricher(ev); errors.Is(err, ErrDropEvent) {
+				drop = true
+			}
+		}
+		// Dropped events are recorded as well
+		if p.recordCallback != nil {
+			p.recordCallback("", ev)
+		}
+		if drop {
+			return
 		}
 		if p.filterSpecs != nil && !p.filterSpecs.MatchAll(ev) {
 			return
 		}
+		if p.filteredEnricher != nil && errors.Is(p.filteredEnricher(ev), ErrDropEvent) {
+			return
+		}
 		cb(ev)
 	}
 }
 
-func (p *parser[T]) eventHandlerArray(cb func([]*T), enrichers ...func(any) error) func([]*T) {
+func (p *parser[T]) eventHandlerArray(key string, cb func([]*T), enrichers ...func(any) error) func([]*T) {
 	if cb == nil {
 		panic("cb can't be nil in eventHandlerArray from parser")
 	}
 	return func(events []*T) {
-		for _, enricher := range enrichers {
+		keptEvents := events
+		if len(enrichers) > 0 {
+			keptEvents = make([]*T, 0, len(events))
 			for _, ev := range events {
-				enricher(ev)
+				drop := false
+				for _, enricher := range enrichers {
+					if err := enricher(ev); errors.Is(err, ErrDropEvent) {
+						drop = true
+					}
+				}
+				if !drop {
+					keptEvents = append(keptEvents, ev)
+				}
 			}
 		}
+		// Like in eventHandler, the recording contains dropped events as well
+		if p.recordCallback != nil {
+			p.recordCallback(key, events)
+		}
+		events = keptEvents
 		if p.filterSpecs != nil {
 			filteredEvents := make([]*T, 0, len(events))
 			for _, event := range events {
@@ -263,6 +407,16 @@ func (p *parser[T]) eventHandlerArray(cb func([]*T), enrichers ...func(any) erro
 			}
 			events = filteredEvents
 		}
+		if p.filteredEnricher != nil {
+			filteredEvents := make([]*T, 0, len(events))
+			for _, event := range events {
+				if errors.Is(p.filteredEnricher(event), ErrDropEvent) {
+					continue
+				}
+				filteredEvents = append(filteredEvents, event)
+			}
+			events = filteredEvents
+		}
 		if p.sortSpec != nil {
 			p.sortSpec.Sort(events)
 		}
@@ -295,6 +449,8 @@ func (p *parser[T]) JSONHandlerFunc(enrichers ...func(any) error) func([]byte) {
 	cb := p.eventCallback
 	if p.eventCombinerEnabled {
 		cb = p.combineEventsCallback
+	} else if p.aggregator != nil {
+		cb = p.aggregator.Add
 	}
 
 	handler := p.eventHandler(cb, enrichers...)
@@ -319,7 +475,7 @@ func (p *parser[T]) JSONHandlerFuncArray(key string, enrichers ...func(any) erro
 		}
 	}
 
-	handler := p.eventHandlerArray(cb, enrichers...)
+	handler := p.eventHandlerArray(key, cb, enrichers...)
 
 	return func(event []byte) {
 		var ev []*T
@@ -333,17 +489,28 @@ func (p *parser[T]) JSONHandlerFuncArray(key string, enrichers ...func(any) erro
 }
 
 func (p *parser[T]) EventHandlerFunc(enrichers ...func(any) error) any {
-	return p.eventHandler(p.eventCallback, enrichers...)
+	cb := p.eventCallback
+	if p.aggregator != nil {
+		cb = p.aggregator.Add
+	}
+	return p.eventHandler(cb, enrichers...)
 }
 
 func (p *parser[T]) EventHandlerFuncArray(enrichers ...func(any) error) any {
-	return p.eventHandlerArray(p.eventCallbackArray, enrichers...)
+	return p.eventHandlerArray("", p.eventCallbackArray, enrichers...)
 }
 
 func (p *parser[T]) GetTextColumnsFormatter(options ...textcolumns.Option) TextColumnsFormatter {
 	return &outputHelper[T]{
-		parser:               p,
-		TextColumnsFormatter: textcolumns.NewFormatter(p.columns.GetColumnMap(p.columnFilters...), options...),
+		parser:         p,
+		entryFormatter: textcolumns.NewFormatter(p.columns.GetColumnMap(p.columnFilters...), options...),
+	}
+}
+
+func (p *parser[T]) GetTableColumnsFormatter(options ...tablecolumns.Option) TextColumnsFormatter {
+	return &outputHelper[T]{
+		parser:         p,
+		entryFormatter: tablecolumns.NewFormatter(p.columns.GetColumnMap(p.columnFilters...), options...),
 	}
 }
 
@@ -355,10 +522,53 @@ func (p *parser[T]) GetColumnAttributes() []columns.Attributes {
 	return out
 }
 
+func (p *parser[T]) ValuesFunc(columnNames []string) (func(any) []string, error) {
+	columnMap := p.columns.GetColumnMap(p.columnFilters...)
+	valueFuncs := make([]func(*T) string, 0, len(columnNames))
+	for _, columnName := range columnNames {
+		column, ok := columnMap.GetColumn(columnName)
+		if !ok {
+			return nil, fmt.Errorf("column %q not found", columnName)
+		}
+		valueFuncs = append(valueFuncs, rawValueFunc(column))
+	}
+	return func(ev any) []string {
+		entry, ok := ev.(*T)
+		if !ok || entry == nil {
+			return nil
+		}
+		values := make([]string, 0, len(valueFuncs))
+		for _, valueFunc := range valueFuncs {
+			values = append(values, valueFunc(entry))
+		}
+		return values
+	}, nil
+}
+
+func rawValueFunc[T any](column *columns.Column[T]) func(*T) string {
+	if column.Unit == columns.UnitNone || column.IsVirtual() {
+		return columns.GetFieldAsStringExt[T](column, 'f', column.Precision)
+	}
+	if column.Unit == columns.UnitTimestamp {
+		ff := columns.GetFieldAsNumberFuncExt[int64, T](column, true)
+		return func(entry *T) string {
+			return strconv.FormatInt(ff(entry), 10)
+		}
+	}
+	ff := columns.GetFieldAsNumberFuncExt[float64, T](column, true)
+	return func(entry *T) string {
+		return strconv.FormatFloat(ff(entry), 'f', -1, 64)
+	}
+}
+
 func (p *parser[T]) GetColumns() any {
 	return p.columns.GetColumnMap(p.columnFilters...)
 }
 
+func (p *parser[T]) JSONSchema(title string) *jsonschema.Schema {
+	return jsonschema.ForColumns(p.columns.ColumnMap, title)
+}
+
 func (p *parser[T]) GetDefaultColumns(hiddenTags ...string) []string {
 	cols := make([]string, 0)
 columnLoop:
@@ -405,6 +615,26 @@ func (p *parser[T]) SetFilters(filters []string) error {
 	return nil
 }
 
+func (p *parser[T]) EqualityFilters(columnNames ...string) map[string]string {
+	res := make(map[string]string)
+	if p.filterSpecs == nil || p.recordCallback != nil {
+		return res
+	}
+	for _, filterSpec := range *p.filterSpecs {
+		columnName, value, ok := filterSpec.Equality()
+		if !ok {
+			continue
+		}
+		for _, name := range columnNames {
+			if strings.ToLower(name) == columnName {
+				res[name] = value
+				break
+			}
+		}
+	}
+	return res
+}
+
 // Prometheus related stuff
 
 func (p *parser[T]) AttrsGetter(colNames []string) (func(any) []attribute.KeyValue, error) {
@@ -0,0 +1,218 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/filter"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/formatter/textcolumns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns/sort"
+)
+
+// View keeps the latest batch of entries of a gadget emitting arrays of events (like top gadgets) and renders it as
+// text table. Sorting, filters and the shown columns can be changed at any time; the current batch is then
+// rendered again without having to wait for the next one. It's used by interactive frontends.
+type View interface {
+	// EventCallback returns the callback that has to be passed to Parser.SetEventCallback to feed the view
+	EventCallback() any
+
+	// SetUpdateCallback sets a function that is called whenever a new batch has been received
+	SetUpdateCallback(func())
+
+	// SetPaused makes the view ignore new batches while paused is true
+	SetPaused(paused bool)
+
+	// ColumnNames returns the names of all columns, in their default order
+	ColumnNames() []string
+
+	// ShownColumns returns the names of the columns that are currently shown
+	ShownColumns() []string
+
+	// SetShowColumns sets the columns to show
+	SetShowColumns(columns []string) error
+
+	// SortBy returns the current sort order
+	SortBy() []string
+
+	// SetSortBy sets the sort order; column names can be prefixed with "-" for descending order
+	SetSortBy(sortBy []string) error
+
+	// SetFilters sets the filters to apply to the batch, in addition to the ones set on the parser
+	SetFilters(filters []string) error
+
+	// Render applies filters and sorting to the current batch and returns the formatted header and rows
+	Render() (header string, rows []string)
+
+	// Value returns the value of the given column for the nth row of the last call to Render
+	Value(row int, column string) (string, bool)
+}
+
+type view[T any] struct {
+	mu        sync.Mutex
+	columns   *columns.Columns[T]
+	columnMap columns.ColumnMap[T]
+	formatter *textcolumns.TextColumnsFormatter[T]
+
+	shownColumns []string
+	sortBy       []string
+	sortSpec     *sort.ColumnSorterCollection[T]
+	filterSpecs  *filter.FilterSpecs[T]
+
+	entries  []*T
+	rendered []*T
+	paused   bool
+	onUpdate func()
+}
+
+// NewView returns a View for the entries emitted by the parser; options are used for the underlying
+// textcolumns formatter
+func (p *parser[T]) NewView(options ...textcolumns.Option) View {
+	columnMap := p.columns.GetColumnMap(p.columnFilters...)
+	v := &view[T]{
+		columns:   p.columns,
+		columnMap: columnMap,
+		formatter: textcolumns.NewFormatter(columnMap, options...),
+	}
+	v.SetSortBy(p.sortBy)
+	return v
+}
+
+func (v *view[T]) EventCallback() any {
+	return func(entries []*T) {
+		v.mu.Lock()
+		if v.paused {
+			v.mu.Unlock()
+			return
+		}
+		v.entries = entries
+		onUpdate := v.onUpdate
+		v.mu.Unlock()
+
+		if onUpdate != nil {
+			onUpdate()
+		}
+	}
+}
+
+func (v *view[T]) SetUpdateCallback(onUpdate func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.onUpdate = onUpdate
+}
+
+func (v *view[T]) SetPaused(paused bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.paused = paused
+}
+
+func (v *view[T]) ColumnNames() []string {
+	names := make([]string, 0, len(v.columnMap))
+	for _, column := range v.columns.GetOrderedColumns() {
+		if _, ok := v.columnMap[strings.ToLower(column.Name)]; ok {
+			names = append(names, column.Name)
+		}
+	}
+	return names
+}
+
+func (v *view[T]) ShownColumns() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]string{}, v.shownColumns...)
+}
+
+func (v *view[T]) SetShowColumns(columns []string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.formatter.SetShowColumns(columns); err != nil {
+		return err
+	}
+	v.shownColumns = append([]string{}, columns...)
+	return nil
+}
+
+func (v *view[T]) SortBy() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]string{}, v.sortBy...)
+}
+
+func (v *view[T]) SetSortBy(sortBy []string) error {
+	_, invalid := v.columns.VerifyColumnNames(sortBy)
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid columns to sort by: %v", invalid)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.sortBy = append([]string{}, sortBy...)
+	v.sortSpec = sort.Prepare(v.columnMap, sortBy)
+	return nil
+}
+
+func (v *view[T]) SetFilters(filters []string) error {
+	var filterSpecs *filter.FilterSpecs[T]
+	if len(filters) > 0 {
+		var err error
+		filterSpecs, err = filter.GetFiltersFromStrings(v.columnMap, filters)
+		if err != nil {
+			return err
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.filterSpecs = filterSpecs
+	return nil
+}
+
+func (v *view[T]) Render() (string, []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entries := make([]*T, 0, len(v.entries))
+	for _, entry := range v.entries {
+		if entry == nil || (v.filterSpecs != nil && !v.filterSpecs.MatchAll(entry)) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if v.sortSpec != nil {
+		v.sortSpec.Sort(entries)
+	}
+	v.rendered = entries
+
+	rows := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		rows = append(rows, v.formatter.FormatEntry(entry))
+	}
+	return v.formatter.FormatHeader(), rows
+}
+
+func (v *view[T]) Value(row int, column string) (string, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	col, ok := v.columnMap.GetColumn(column)
+	if !ok || row < 0 || row >= len(v.rendered) {
+		return "", false
+	}
+	return columns.GetFieldAsString[T](col)(v.rendered[row]), true
+}
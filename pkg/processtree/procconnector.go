@@ -0,0 +1,167 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processtree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// Constants from include/uapi/linux/connector.h and cn_proc.h
+const (
+	cnIdxProc = 1
+	cnValProc = 1
+
+	procCnMcastListen = 1
+
+	procEventFork = 0x00000001
+	procEventExec = 0x00000002
+	procEventExit = 0x80000000
+
+	// sizes of struct cn_msg and of the header of struct proc_event
+	cnMsgSize       = 20
+	procEventHdrLen = 16
+)
+
+var nativeEndian binary.ByteOrder = func() binary.ByteOrder {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) == 1 {
+		return binary.LittleEndian
+	}
+	return binary.BigEndian
+}()
+
+type procEventHandler interface {
+	handleFork(parentTgid, childPid, childTgid uint32, timestamp uint64)
+	handleExec(tgid uint32)
+	handleExit(pid, tgid uint32)
+}
+
+// procConnector receives the process events of the kernel proc connector.
+type procConnector struct {
+	fd int
+}
+
+func newProcConnector() (*procConnector, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.NETLINK_CONNECTOR)
+	if err != nil {
+		return nil, fmt.Errorf("creating netlink socket: %w", err)
+	}
+
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK, Groups: cnIdxProc}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("binding netlink socket: %w", err)
+	}
+
+	// Wake up regularly to check if we need to stop listening
+	tv := unix.Timeval{Sec: 1}
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("setting receive timeout: %w", err)
+	}
+
+	// struct nlmsghdr + struct cn_msg + enum proc_cn_mcast_op
+	msg := make([]byte, unix.NLMSG_HDRLEN+cnMsgSize+4)
+	nativeEndian.PutUint32(msg[0:], uint32(len(msg)))
+	nativeEndian.PutUint16(msg[4:], unix.NLMSG_DONE)
+	nativeEndian.PutUint32(msg[12:], uint32(unix.Getpid()))
+	cn := msg[unix.NLMSG_HDRLEN:]
+	nativeEndian.PutUint32(cn[0:], cnIdxProc)
+	nativeEndian.PutUint32(cn[4:], cnValProc)
+	nativeEndian.PutUint16(cn[16:], 4)
+	nativeEndian.PutUint32(cn[cnMsgSize:], procCnMcastListen)
+
+	if err := unix.Sendto(fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("subscribing to process events: %w", err)
+	}
+
+	return &procConnector{fd: fd}, nil
+}
+
+// listen dispatches the process events to handler until done is closed.
+// resync is called when events were lost.
+func (c *procConnector) listen(done <-chan struct{}, handler procEventHandler, resync func()) {
+	buf := make([]byte, unix.Getpagesize())
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		n, _, err := unix.Recvfrom(c.fd, buf, 0)
+		if err != nil {
+			switch {
+			case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EINTR):
+			case errors.Is(err, unix.ENOBUFS):
+				log.Debugf("process tree: lost process events, reading /proc again")
+				resync()
+			default:
+				log.Warnf("process tree: receiving process events: %s", err)
+				return
+			}
+			continue
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			log.Debugf("process tree: parsing netlink message: %s", err)
+			continue
+		}
+		for _, msg := range msgs {
+			dispatchProcEvent(msg.Data, handler)
+		}
+	}
+}
+
+func dispatchProcEvent(data []byte, handler procEventHandler) {
+	if len(data) < cnMsgSize+procEventHdrLen {
+		return
+	}
+	ev := data[cnMsgSize:]
+	what := nativeEndian.Uint32(ev[0:])
+	timestamp := nativeEndian.Uint64(ev[8:])
+	ev = ev[procEventHdrLen:]
+
+	switch what {
+	case procEventFork:
+		if len(ev) < 16 {
+			return
+		}
+		handler.handleFork(nativeEndian.Uint32(ev[4:]), nativeEndian.Uint32(ev[8:]),
+			nativeEndian.Uint32(ev[12:]), timestamp)
+	case procEventExec:
+		if len(ev) < 8 {
+			return
+		}
+		handler.handleExec(nativeEndian.Uint32(ev[4:]))
+	case procEventExit:
+		if len(ev) < 8 {
+			return
+		}
+		handler.handleExit(nativeEndian.Uint32(ev[0:]), nativeEndian.Uint32(ev[4:]))
+	}
+}
+
+func (c *procConnector) close() {
+	unix.Close(c.fd)
+}
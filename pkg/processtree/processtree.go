@@ -0,0 +1,436 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package processtree maintains a cache of the processes running on the host
+// and their parent relationships. The cache is seeded by walking /proc and
+// kept up to date with the fork, exec and exit events reported by the kernel
+// proc connector. When the proc connector isn't available (e.g. when not
+// running in the host pid and network namespaces), entries are read lazily
+// from /proc and revalidated periodically.
+//
+// The proc connector is used instead of the sched_process_fork,
+// sched_process_exec and sched_process_exit tracepoints because it reports the
+// same events without an eBPF program of its own, and the kernel reports when
+// events were lost, so the tree can be read again from /proc.
+package processtree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/utils/host"
+)
+
+const (
+	// exitedRetention is the time exited processes are kept in the cache, so
+	// that events emitted right before the exit can still be enriched.
+	exitedRetention = 30 * time.Second
+
+	// revalidateInterval is the time after which entries are read again from
+	// /proc when the proc connector isn't available.
+	revalidateInterval = 5 * time.Second
+
+	// staleTimeout is the time after which entries that weren't accessed are
+	// removed when the proc connector isn't available.
+	staleTimeout = 5 * time.Minute
+
+	cleanupInterval = 10 * time.Second
+
+	// maxAncestors limits the depth of the ancestry returned by Ancestors().
+	maxAncestors = 64
+
+	// userHZ is the unit of the process start time in /proc/<pid>/stat.
+	userHZ = 100
+)
+
+// Process contains the information of a process in the cache.
+type Process struct {
+	Pid     uint32
+	Ppid    uint32
+	Comm    string
+	ExePath string
+	Cwd     string
+	MntNsID uint64
+
+	// StartTime is the time the process started, in nanoseconds since boot
+	// (CLOCK_BOOTTIME) with the precision of a clock tick, as reported by
+	// /proc/<pid>/stat. It's zero if unknown.
+	StartTime uint64
+
+	exited     bool
+	exitedAt   time.Time
+	updatedAt  time.Time
+	accessedAt time.Time
+}
+
+// Tree is a cache of the processes running on the host. It's safe for
+// concurrent use.
+type Tree struct {
+	procFs string
+	now    func() time.Time
+
+	// bootTimeOffset returns the difference between CLOCK_BOOTTIME and
+	// CLOCK_MONOTONIC, which is used by the timestamps of the proc connector.
+	bootTimeOffset func() uint64
+
+	mu        sync.Mutex
+	processes map[uint32]*Process
+
+	// live tells if the cache is kept up to date by the proc connector.
+	live      bool
+	connector *procConnector
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a process tree for the processes of the host.
+func New() *Tree {
+	t := newTree(host.HostProcFs)
+
+	if host.IsHostPidNs && host.IsHostNetNs {
+		connector, err := newProcConnector()
+		if err != nil {
+			log.Warnf("process tree: proc connector not available, falling back to reading /proc: %s", err)
+		} else {
+			t.connector = connector
+			t.live = true
+		}
+	} else {
+		log.Debugf("process tree: not running in the host pid and network namespaces, reading /proc only")
+	}
+
+	// Seed the cache after subscribing to the events, so no process is
+	// missed.
+	t.seed()
+
+	t.wg.Add(1)
+	go t.cleanupLoop()
+
+	if t.connector != nil {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.connector.listen(t.done, t, t.seed)
+		}()
+	}
+
+	return t
+}
+
+func newTree(procFs string) *Tree {
+	return &Tree{
+		procFs:         procFs,
+		now:            time.Now,
+		bootTimeOffset: bootTimeOffset,
+		processes:      make(map[uint32]*Process),
+		done:           make(chan struct{}),
+	}
+}
+
+// bootTimeOffset returns the time the system has been suspended, i.e. the
+// difference between CLOCK_BOOTTIME and CLOCK_MONOTONIC, in nanoseconds.
+func bootTimeOffset() uint64 {
+	var boottime, monotonic unix.Timespec
+	if unix.ClockGettime(unix.CLOCK_BOOTTIME, &boottime) != nil ||
+		unix.ClockGettime(unix.CLOCK_MONOTONIC, &monotonic) != nil {
+		return 0
+	}
+	if offset := boottime.Nano() - monotonic.Nano(); offset > 0 {
+		return uint64(offset)
+	}
+	return 0
+}
+
+// clockTicks truncates a time in nanoseconds to the clock ticks used by the
+// start time in /proc/<pid>/stat.
+func clockTicks(ns uint64) uint64 {
+	tick := uint64(time.Second) / userHZ
+	return ns / tick * tick
+}
+
+// connectorStartTime converts the timestamp of a proc connector event
+// (CLOCK_MONOTONIC) to the clock and precision of the start time read from
+// /proc, so that both can be compared to detect reused pids.
+func (t *Tree) connectorStartTime(timestamp uint64) uint64 {
+	if timestamp == 0 {
+		return 0
+	}
+	return clockTicks(timestamp + t.bootTimeOffset())
+}
+
+// Close stops updating the cache.
+func (t *Tree) Close() {
+	close(t.done)
+	t.wg.Wait()
+	if t.connector != nil {
+		t.connector.close()
+	}
+}
+
+// Get returns the information of a process, or nil if it's unknown.
+func (t *Tree) Get(pid uint32) *Process {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := t.get(pid)
+	if p == nil {
+		return nil
+	}
+	ret := *p
+	return &ret
+}
+
+// Ancestors returns the ancestors of a process, starting with its parent.
+func (t *Tree) Ancestors(pid uint32) []Process {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p := t.get(pid)
+	if p == nil {
+		return nil
+	}
+
+	var ancestors []Process
+	seen := map[uint32]struct{}{pid: {}}
+	for len(ancestors) < maxAncestors && p.Ppid != 0 {
+		if _, ok := seen[p.Ppid]; ok {
+			break
+		}
+		seen[p.Ppid] = struct{}{}
+
+		parent := t.get(p.Ppid)
+		if parent == nil {
+			break
+		}
+		// The pid of the parent was reused by a process started later
+		if parent.StartTime != 0 && p.StartTime != 0 && parent.StartTime > p.StartTime {
+			break
+		}
+
+		ancestors = append(ancestors, *parent)
+		p = parent
+	}
+	return ancestors
+}
+
+// get returns the process from the cache, reading it from /proc if needed.
+// t.mu must be held.
+func (t *Tree) get(pid uint32) *Process {
+	now := t.now()
+
+	p := t.processes[pid]
+	if p != nil && (t.live || p.exited || now.Sub(p.updatedAt) < revalidateInterval) {
+		p.accessedAt = now
+		return p
+	}
+
+	fresh, err := t.readProcess(pid)
+	if err != nil {
+		if p != nil && !p.exited {
+			// Keep it around for the events emitted before the exit
+			p.exited = true
+			p.exitedAt = now
+		}
+		return p
+	}
+	fresh.accessedAt = now
+	t.processes[pid] = fresh
+	return fresh
+}
+
+// readProcess reads the information of a process from /proc.
+func (t *Tree) readProcess(pid uint32) (*Process, error) {
+	procPath := filepath.Join(t.procFs, strconv.FormatUint(uint64(pid), 10))
+
+	stat, err := os.ReadFile(filepath.Join(procPath, "stat"))
+	if err != nil {
+		return nil, err
+	}
+	p, err := parseStat(stat)
+	if err != nil {
+		return nil, fmt.Errorf("parsing stat of process %d: %w", pid, err)
+	}
+
+	// Kernel threads don't have an executable, so errors are ignored
+	p.ExePath, _ = os.Readlink(filepath.Join(procPath, "exe"))
+	p.Cwd, _ = os.Readlink(filepath.Join(procPath, "cwd"))
+	if info, err := os.Stat(filepath.Join(procPath, "ns", "mnt")); err == nil {
+		if st, ok := info.Sys().(*syscall.Stat_t); ok {
+			p.MntNsID = st.Ino
+		}
+	}
+	p.updatedAt = t.now()
+
+	return p, nil
+}
+
+// parseStat parses the content of /proc/<pid>/stat.
+func parseStat(stat []byte) (*Process, error) {
+	// The command is between parentheses and can contain spaces and
+	// parentheses itself.
+	s := string(stat)
+	start := strings.IndexByte(s, '(')
+	end := strings.LastIndexByte(s, ')')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid format")
+	}
+
+	pid, err := strconv.ParseUint(strings.TrimSpace(s[:start]), 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("parsing pid: %w", err)
+	}
+
+	// Fields after the command, starting with the state (field 3)
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 20 {
+		return nil, fmt.Errorf("too few fields")
+	}
+	ppid, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("parsing ppid: %w", err)
+	}
+	startTime, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parsing start time: %w", err)
+	}
+
+	return &Process{
+		Pid:       uint32(pid),
+		Ppid:      uint32(ppid),
+		Comm:      s[start+1 : end],
+		StartTime: startTime * uint64(time.Second) / userHZ,
+	}, nil
+}
+
+// seed walks /proc and adds all the processes to the cache.
+func (t *Tree) seed() {
+	entries, err := os.ReadDir(t.procFs)
+	if err != nil {
+		log.Warnf("process tree: reading %s: %s", t.procFs, err)
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		p, err := t.readProcess(uint32(pid))
+		if err != nil {
+			continue
+		}
+
+		t.mu.Lock()
+		t.processes[p.Pid] = p
+		t.mu.Unlock()
+	}
+}
+
+func (t *Tree) handleFork(parentTgid, childPid, childTgid uint32, timestamp uint64) {
+	// Threads share the information of their process
+	if childPid != childTgid {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	child := &Process{
+		Pid:       childTgid,
+		Ppid:      parentTgid,
+		StartTime: t.connectorStartTime(timestamp),
+		updatedAt: t.now(),
+	}
+	if parent := t.processes[parentTgid]; parent != nil {
+		child.Comm = parent.Comm
+		child.ExePath = parent.ExePath
+		child.Cwd = parent.Cwd
+		child.MntNsID = parent.MntNsID
+	}
+	t.processes[childTgid] = child
+}
+
+func (t *Tree) handleExec(tgid uint32) {
+	p, err := t.readProcess(tgid)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err != nil {
+		// The process is already gone, at least keep its ancestry
+		return
+	}
+	t.processes[tgid] = p
+}
+
+func (t *Tree) handleExit(pid, tgid uint32) {
+	if pid != tgid {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p := t.processes[tgid]; p != nil {
+		p.exited = true
+		p.exitedAt = t.now()
+	}
+}
+
+func (t *Tree) cleanupLoop() {
+	defer t.wg.Done()
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			t.cleanup()
+		}
+	}
+}
+
+// cleanup removes exited processes and, if the cache isn't kept up to date by
+// the proc connector, entries that weren't used for a while.
+func (t *Tree) cleanup() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for pid, p := range t.processes {
+		if p.exited && now.Sub(p.exitedAt) > exitedRetention {
+			delete(t.processes, pid)
+			continue
+		}
+		if !t.live && now.Sub(p.accessedAt) > staleTimeout && now.Sub(p.updatedAt) > staleTimeout {
+			delete(t.processes, pid)
+		}
+	}
+}
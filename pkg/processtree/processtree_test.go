@@ -0,0 +1,140 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processtree
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func addFakeProcess(t *testing.T, procFs string, pid, ppid uint32, comm string, startTime uint64) {
+	t.Helper()
+
+	dir := filepath.Join(procFs, fmt.Sprint(pid))
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	stat := fmt.Sprintf("%d (%s) S %d 1 1 0 -1 4194560 100 0 0 0 0 0 0 0 20 0 1 0 %d 1000 100\n",
+		pid, comm, ppid, startTime)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644))
+	require.NoError(t, os.Symlink("/usr/bin/"+comm, filepath.Join(dir, "exe")))
+	require.NoError(t, os.Symlink("/home/"+comm, filepath.Join(dir, "cwd")))
+}
+
+func TestParseStat(t *testing.T) {
+	p, err := parseStat([]byte("42 (my (weird) comm) R 7 42 42 0 -1 4194560 100 0 0 0 0 0 0 0 20 0 1 0 250 1000 100\n"))
+	require.NoError(t, err)
+	require.Equal(t, uint32(42), p.Pid)
+	require.Equal(t, uint32(7), p.Ppid)
+	require.Equal(t, "my (weird) comm", p.Comm)
+	require.Equal(t, uint64(2500*time.Millisecond), p.StartTime)
+
+	_, err = parseStat([]byte("42 comm R 7"))
+	require.Error(t, err)
+}
+
+func TestAncestors(t *testing.T) {
+	procFs := t.TempDir()
+	addFakeProcess(t, procFs, 1, 0, "systemd", 1)
+	addFakeProcess(t, procFs, 100, 1, "bash", 10)
+	addFakeProcess(t, procFs, 200, 100, "sh", 20)
+
+	tree := newTree(procFs)
+	tree.seed()
+
+	p := tree.Get(200)
+	require.NotNil(t, p)
+	require.Equal(t, "sh", p.Comm)
+	require.Equal(t, "/usr/bin/sh", p.ExePath)
+	require.Equal(t, "/home/sh", p.Cwd)
+
+	ancestors := tree.Ancestors(200)
+	require.Len(t, ancestors, 2)
+	require.Equal(t, "bash", ancestors[0].Comm)
+	require.Equal(t, "systemd", ancestors[1].Comm)
+
+	// curl is forked from sh, execs and exits before its events are enriched
+	tree.handleFork(200, 300, 300, uint64(30*time.Second/userHZ))
+	addFakeProcess(t, procFs, 300, 200, "curl", 30)
+	tree.handleExec(300)
+	require.NoError(t, os.RemoveAll(filepath.Join(procFs, "300")))
+	tree.handleExit(300, 300)
+
+	p = tree.Get(300)
+	require.NotNil(t, p)
+	require.Equal(t, "curl", p.Comm)
+	require.Equal(t, []string{"sh", "bash", "systemd"}, comms(tree.Ancestors(300)))
+
+	// Exited processes are removed after some time
+	now := time.Now()
+	tree.now = func() time.Time { return now.Add(2 * exitedRetention) }
+	tree.cleanup()
+	require.Nil(t, tree.Get(300))
+}
+
+func TestAncestorsReusedPid(t *testing.T) {
+	procFs := t.TempDir()
+	addFakeProcess(t, procFs, 1, 0, "systemd", 1)
+	// The parent of 200 exited and its pid was reused by a newer process
+	addFakeProcess(t, procFs, 100, 1, "newer", 50)
+	addFakeProcess(t, procFs, 200, 100, "orphan", 20)
+
+	tree := newTree(procFs)
+	require.Empty(t, tree.Ancestors(200))
+}
+
+func TestForkStartTime(t *testing.T) {
+	procFs := t.TempDir()
+	addFakeProcess(t, procFs, 1, 0, "systemd", 1)
+
+	tree := newTree(procFs)
+	tree.bootTimeOffset = func() uint64 { return uint64(5 * time.Second) }
+	tree.seed()
+
+	// The proc connector uses CLOCK_MONOTONIC with nanosecond precision:
+	// the start time is converted to CLOCK_BOOTTIME in clock ticks.
+	tree.handleFork(1, 100, 100, uint64(10*time.Second+3*time.Millisecond))
+	p := tree.Get(100)
+	require.NotNil(t, p)
+	require.Equal(t, uint64(15*time.Second), p.StartTime)
+
+	// A child started in the same clock tick, read from /proc, isn't
+	// mistaken for the child of a reused pid
+	addFakeProcess(t, procFs, 200, 100, "sh", 15*userHZ)
+	require.Equal(t, []string{"systemd", "systemd"}, comms(tree.Ancestors(200)))
+
+	// A child forked after the system was suspended from a parent read from
+	// /proc: without the conversion, the child would seem older.
+	addFakeProcess(t, procFs, 300, 1, "bash", 12*userHZ)
+	tree.handleFork(300, 400, 400, uint64(8*time.Second))
+	require.Equal(t, []string{"bash", "systemd"}, comms(tree.Ancestors(400)))
+}
+
+func TestForkOfThread(t *testing.T) {
+	tree := newTree(t.TempDir())
+	tree.handleFork(1, 10, 1, 0)
+	require.Nil(t, tree.Get(10))
+}
+
+func comms(processes []Process) []string {
+	ret := make([]string, 0, len(processes))
+	for _, p := range processes {
+		ret = append(ret, p.Comm)
+	}
+	return ret
+}
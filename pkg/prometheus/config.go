@@ -29,6 +29,24 @@ type Metric struct {
 	Field    string   `yaml:"field,omitempty"`
 	Labels   []string `yaml:"labels,omitempty"`
 	Selector []string `yaml:"selector,omitempty"`
+	// Buckets are the upper bounds of the buckets of a histogram; if empty, default buckets are used
+	Buckets []float64 `yaml:"buckets,omitempty"`
+	// MaxSeries limits the number of series of the metric; events of further series are recorded in a single
+	// overflow series. 0 means no limit.
+	MaxSeries int `yaml:"max_series,omitempty"`
+	// Relabel are rules applied to the labels of each event, in order
+	Relabel []RelabelConfig `yaml:"relabel,omitempty"`
+}
+
+// RelabelConfig is a rule to modify the labels of an event, similar to the relabel_configs of Prometheus
+type RelabelConfig struct {
+	SourceLabels []string `yaml:"source_labels,omitempty"`
+	Separator    string   `yaml:"separator,omitempty"`
+	Regex        string   `yaml:"regex,omitempty"`
+	TargetLabel  string   `yaml:"target_label,omitempty"`
+	Replacement  string   `yaml:"replacement,omitempty"`
+	Modulus      uint64   `yaml:"modulus,omitempty"`
+	Action       string   `yaml:"action,omitempty"`
 }
 
 type Config struct {
@@ -50,11 +68,17 @@ func ParseConfig(configBytes []byte) (*Config, error) {
 		return nil, errors.New("metrics section is missing")
 	}
 
+	names := make(map[string]struct{}, len(config.Metrics))
 	for _, metric := range config.Metrics {
 		if metric.Name == "" {
 			return nil, errors.New("metric name is missing")
 		}
 
+		if _, ok := names[metric.Name]; ok {
+			return nil, fmt.Errorf("metric %q is defined more than once", metric.Name)
+		}
+		names[metric.Name] = struct{}{}
+
 		if metric.Category == "" {
 			return nil, fmt.Errorf("metric category is missing in %q", metric.Name)
 		}
@@ -66,6 +90,30 @@ func ParseConfig(configBytes []byte) (*Config, error) {
 		if metric.Type == "" {
 			return nil, fmt.Errorf("metric type is missing in %q", metric.Name)
 		}
+
+		if metric.Type == "histogram" && metric.Field == "" {
+			return nil, fmt.Errorf("metric field is missing in histogram %q", metric.Name)
+		}
+
+		if len(metric.Buckets) > 0 && metric.Type != "histogram" {
+			return nil, fmt.Errorf("buckets are only supported by histograms in %q", metric.Name)
+		}
+
+		for i := 1; i < len(metric.Buckets); i++ {
+			if metric.Buckets[i] <= metric.Buckets[i-1] {
+				return nil, fmt.Errorf("buckets must be in increasing order in %q", metric.Name)
+			}
+		}
+
+		if metric.MaxSeries < 0 {
+			return nil, fmt.Errorf("max_series must not be negative in %q", metric.Name)
+		}
+
+		for i := range metric.Relabel {
+			if err := metric.Relabel[i].validate(); err != nil {
+				return nil, fmt.Errorf("relabel rule %d of %q: %w", i, metric.Name, err)
+			}
+		}
 	}
 
 	return config, nil
@@ -111,6 +111,201 @@ func TestParseConfig(t *testing.T) {
 			},
 			expectedErr: true,
 		},
+		{
+			name: "duplicated_metric_name",
+			input: &Config{
+				MetricsName: "duplicated_metric_name",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "type",
+					},
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "type",
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "histogram_all_good",
+			input: &Config{
+				MetricsName: "histogram_all_good",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "histogram",
+						Field:    "latency",
+						Buckets:  []float64{1, 10, 100},
+					},
+				},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "histogram_missing_field",
+			input: &Config{
+				MetricsName: "histogram_missing_field",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "histogram",
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "histogram_unordered_buckets",
+			input: &Config{
+				MetricsName: "histogram_unordered_buckets",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "histogram",
+						Field:    "latency",
+						Buckets:  []float64{1, 100, 10},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "buckets_in_counter",
+			input: &Config{
+				MetricsName: "buckets_in_counter",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "counter",
+						Buckets:  []float64{1, 10, 100},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "relabel_all_good",
+			input: &Config{
+				MetricsName: "relabel_all_good",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "counter",
+						Relabel: []RelabelConfig{
+							{SourceLabels: []string{"comm"}, Regex: "c(.*)", TargetLabel: "comm", Replacement: "$1"},
+							{SourceLabels: []string{"comm"}, Regex: "ls", Action: "drop"},
+							{SourceLabels: []string{"pid"}, TargetLabel: "shard", Modulus: 4, Action: "hashmod"},
+						},
+						MaxSeries: 100,
+					},
+				},
+			},
+			expectedErr: false,
+		},
+		{
+			name: "relabel_invalid_regex",
+			input: &Config{
+				MetricsName: "relabel_invalid_regex",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "counter",
+						Relabel: []RelabelConfig{
+							{SourceLabels: []string{"comm"}, Regex: "(", TargetLabel: "comm"},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "relabel_unknown_action",
+			input: &Config{
+				MetricsName: "relabel_unknown_action",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "counter",
+						Relabel: []RelabelConfig{
+							{SourceLabels: []string{"comm"}, Action: "labelmap"},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "relabel_replace_without_target",
+			input: &Config{
+				MetricsName: "relabel_replace_without_target",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "counter",
+						Relabel: []RelabelConfig{
+							{SourceLabels: []string{"comm"}},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "relabel_hashmod_without_modulus",
+			input: &Config{
+				MetricsName: "relabel_hashmod_without_modulus",
+				Metrics: []Metric{
+					{
+						Name:     "name",
+						Category: "category",
+						Gadget:   "gadget",
+						Type:     "counter",
+						Relabel: []RelabelConfig{
+							{SourceLabels: []string{"comm"}, TargetLabel: "shard", Action: "hashmod"},
+						},
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "negative_max_series",
+			input: &Config{
+				MetricsName: "negative_max_series",
+				Metrics: []Metric{
+					{
+						Name:      "name",
+						Category:  "category",
+						Gadget:    "gadget",
+						Type:      "counter",
+						MaxSeries: -1,
+					},
+				},
+			},
+			expectedErr: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,292 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+// Relabel actions, they behave like the ones of Prometheus
+const (
+	RelabelReplace = "replace"
+	RelabelKeep    = "keep"
+	RelabelDrop    = "drop"
+	RelabelHashMod = "hashmod"
+)
+
+const (
+	defaultRelabelSeparator   = ";"
+	defaultRelabelRegex       = "(.*)"
+	defaultRelabelReplacement = "$1"
+
+	// OverflowAttribute is the only attribute of the series that records the events exceeding the maximum number of
+	// series of a metric, as proposed by the OpenTelemetry specification
+	OverflowAttribute = "otel.metric.overflow"
+
+	// Names of the metrics reporting about the labels of the other metrics
+	DroppedEventsMetric  = "ig_metrics_dropped_events"
+	OverflowEventsMetric = "ig_metrics_overflow_events"
+)
+
+var overflowAttrs = []attribute.KeyValue{attribute.Bool(OverflowAttribute, true)}
+
+// validate checks the rule and sets the default values of the fields that weren't set
+func (r *RelabelConfig) validate() error {
+	if r.Action == "" {
+		r.Action = RelabelReplace
+	}
+	if r.Separator == "" {
+		r.Separator = defaultRelabelSeparator
+	}
+	if r.Regex == "" {
+		r.Regex = defaultRelabelRegex
+	}
+	if r.Replacement == "" && r.Action == RelabelReplace {
+		r.Replacement = defaultRelabelReplacement
+	}
+
+	if _, err := compileRelabelRegex(r.Regex); err != nil {
+		return fmt.Errorf("invalid regex %q: %w", r.Regex, err)
+	}
+
+	switch r.Action {
+	case RelabelReplace:
+		if r.TargetLabel == "" {
+			return fmt.Errorf("target_label is missing in %s rule", r.Action)
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(r.SourceLabels) == 0 {
+			return fmt.Errorf("source_labels are missing in %s rule", r.Action)
+		}
+	case RelabelHashMod:
+		if r.TargetLabel == "" {
+			return fmt.Errorf("target_label is missing in %s rule", r.Action)
+		}
+		if r.Modulus == 0 {
+			return fmt.Errorf("modulus is missing in %s rule", r.Action)
+		}
+	default:
+		return fmt.Errorf("relabel action %q not supported", r.Action)
+	}
+
+	return nil
+}
+
+func compileRelabelRegex(regex string) (*regexp.Regexp, error) {
+	// Like Prometheus, the regex needs to match the whole value
+	return regexp.Compile("^(?:" + regex + ")$")
+}
+
+type relabelRule struct {
+	*RelabelConfig
+	regex *regexp.Regexp
+}
+
+// apply applies the rule to the given attributes; it returns false if the event needs to be dropped
+func (r *relabelRule) apply(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	values := make([]string, 0, len(r.SourceLabels))
+	for _, label := range r.SourceLabels {
+		values = append(values, labelValue(attrs, label))
+	}
+	value := strings.Join(values, r.Separator)
+
+	switch r.Action {
+	case RelabelKeep:
+		return attrs, r.regex.MatchString(value)
+	case RelabelDrop:
+		return attrs, !r.regex.MatchString(value)
+	case RelabelHashMod:
+		sum := md5.Sum([]byte(value))
+		mod := binary.BigEndian.Uint64(sum[8:]) % r.Modulus
+		return setLabel(attrs, r.TargetLabel, strconv.FormatUint(mod, 10)), true
+	}
+
+	indexes := r.regex.FindStringSubmatchIndex(value)
+	if indexes == nil {
+		return attrs, true
+	}
+	res := r.regex.ExpandString(nil, r.Replacement, value, indexes)
+	return setLabel(attrs, r.TargetLabel, string(res)), true
+}
+
+func labelValue(attrs []attribute.KeyValue, label string) string {
+	for _, attr := range attrs {
+		if string(attr.Key) == label {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+// setLabel returns a copy of attrs with label set to value; an empty value removes the label
+func setLabel(attrs []attribute.KeyValue, label, value string) []attribute.KeyValue {
+	res := make([]attribute.KeyValue, 0, len(attrs)+1)
+	for _, attr := range attrs {
+		if string(attr.Key) != label {
+			res = append(res, attr)
+		}
+	}
+	if value != "" {
+		res = append(res, attribute.String(label, value))
+	}
+	return res
+}
+
+// seriesLimiter keeps track of the series of a metric and moves all series exceeding maxSeries to the overflow series
+type seriesLimiter struct {
+	maxSeries int
+
+	mu     sync.Mutex
+	series map[attribute.Distinct]struct{}
+}
+
+func newSeriesLimiter(maxSeries int) *seriesLimiter {
+	return &seriesLimiter{
+		maxSeries: maxSeries,
+		series:    make(map[attribute.Distinct]struct{}),
+	}
+}
+
+// limit returns the attributes to use for a series; it returns true if they were replaced by the overflow series
+func (s *seriesLimiter) limit(attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	set := attribute.NewSet(attrs...)
+	key := set.Equivalent()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.series[key]; ok {
+		return attrs, false
+	}
+	if len(s.series) < s.maxSeries {
+		s.series[key] = struct{}{}
+		return attrs, false
+	}
+	return overflowAttrs, true
+}
+
+func (s *seriesLimiter) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.series = make(map[attribute.Distinct]struct{})
+}
+
+// labelsProcessor applies the relabel rules and the maximum number of series of a metric to its labels and reports
+// about dropped and overflowed events
+type labelsProcessor struct {
+	rules   []*relabelRule
+	limiter *seriesLimiter
+
+	metricAttr     otelmetric.MeasurementOption
+	droppedEvents  otelmetric.Int64Counter
+	overflowEvents otelmetric.Int64Counter
+}
+
+// newLabelsProcessor returns nil if the metric neither has relabel rules nor a maximum number of series
+func newLabelsProcessor(metric *Metric, meter otelmetric.Meter) (*labelsProcessor, error) {
+	if len(metric.Relabel) == 0 && metric.MaxSeries == 0 {
+		return nil, nil
+	}
+
+	p := &labelsProcessor{
+		metricAttr: otelmetric.WithAttributes(attribute.String("metric", metric.Name)),
+	}
+
+	for i := range metric.Relabel {
+		// The config is validated by ParseConfig already, but it could have been created without it
+		rule := metric.Relabel[i]
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("metric %s: %w", metric.Name, err)
+		}
+		regex, err := compileRelabelRegex(rule.Regex)
+		if err != nil {
+			return nil, err
+		}
+		p.rules = append(p.rules, &relabelRule{RelabelConfig: &rule, regex: regex})
+	}
+
+	var err error
+	if len(p.rules) > 0 {
+		p.droppedEvents, err = meter.Int64Counter(DroppedEventsMetric,
+			otelmetric.WithDescription("Number of events dropped by relabel rules"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if metric.MaxSeries > 0 {
+		p.limiter = newSeriesLimiter(metric.MaxSeries)
+		p.overflowEvents, err = meter.Int64Counter(OverflowEventsMetric,
+			otelmetric.WithDescription("Number of events recorded in the overflow series because of too many series"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// process returns the attributes to record an event with; it returns false if the event needs to be dropped
+func (p *labelsProcessor) process(ctx context.Context, attrs []attribute.KeyValue) ([]attribute.KeyValue, bool) {
+	if p == nil {
+		return attrs, true
+	}
+
+	for _, rule := range p.rules {
+		var keep bool
+		attrs, keep = rule.apply(attrs)
+		if !keep {
+			p.droppedEvents.Add(ctx, 1, p.metricAttr)
+			return nil, false
+		}
+	}
+
+	if p.limiter != nil {
+		var overflowed bool
+		attrs, overflowed = p.limiter.limit(attrs)
+		if overflowed {
+			p.overflowEvents.Add(ctx, 1, p.metricAttr)
+		}
+	}
+
+	return attrs, true
+}
+
+// reset forgets about all known series, it's used by gauges, whose series are reported again on each collection
+func (p *labelsProcessor) reset() {
+	if p != nil && p.limiter != nil {
+		p.limiter.reset()
+	}
+}
+
+// wrapAttrsGetter applies the processor to the attributes returned by attrsGetter
+func (p *labelsProcessor) wrapAttrsGetter(
+	ctx context.Context,
+	attrsGetter func(any) []attribute.KeyValue,
+) func(any) ([]attribute.KeyValue, bool) {
+	return func(ev any) ([]attribute.KeyValue, bool) {
+		return p.process(ctx, attrsGetter(ev))
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestRelabel(t *testing.T) {
+	attrs := []attribute.KeyValue{
+		attribute.String("comm", "cat"),
+		attribute.Int64("uid", 1000),
+	}
+
+	type testDefinition struct {
+		name          string
+		rule          RelabelConfig
+		expectedAttrs []attribute.KeyValue
+		expectedKeep  bool
+	}
+
+	tests := []testDefinition{
+		{
+			name:          "replace_no_match",
+			rule:          RelabelConfig{SourceLabels: []string{"comm"}, Regex: "ls", TargetLabel: "comm", Replacement: "x"},
+			expectedAttrs: attrs,
+			expectedKeep:  true,
+		},
+		{
+			name: "replace_multiple_sources",
+			rule: RelabelConfig{SourceLabels: []string{"comm", "uid"}, Regex: "(.*);(.*)", TargetLabel: "id", Replacement: "$2/$1"},
+			expectedAttrs: []attribute.KeyValue{
+				attribute.String("comm", "cat"),
+				attribute.Int64("uid", 1000),
+				attribute.String("id", "1000/cat"),
+			},
+			expectedKeep: true,
+		},
+		{
+			name:          "replace_empty_removes_label",
+			rule:          RelabelConfig{SourceLabels: []string{"uid"}, Regex: "1000(.*)", TargetLabel: "uid"},
+			expectedAttrs: []attribute.KeyValue{attribute.String("comm", "cat")},
+			expectedKeep:  true,
+		},
+		{
+			name:          "regex_is_anchored",
+			rule:          RelabelConfig{SourceLabels: []string{"comm"}, Regex: "ca", Action: RelabelKeep},
+			expectedAttrs: attrs,
+			expectedKeep:  false,
+		},
+		{
+			name:          "keep",
+			rule:          RelabelConfig{SourceLabels: []string{"comm"}, Regex: "cat|ls", Action: RelabelKeep},
+			expectedAttrs: attrs,
+			expectedKeep:  true,
+		},
+		{
+			name:          "drop",
+			rule:          RelabelConfig{SourceLabels: []string{"uid"}, Regex: "1000", Action: RelabelDrop},
+			expectedAttrs: attrs,
+			expectedKeep:  false,
+		},
+		{
+			name: "hashmod",
+			rule: RelabelConfig{SourceLabels: []string{"comm"}, TargetLabel: "comm", Modulus: 1, Action: RelabelHashMod},
+			expectedAttrs: []attribute.KeyValue{
+				attribute.Int64("uid", 1000),
+				attribute.String("comm", "0"),
+			},
+			expectedKeep: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			rule := test.rule
+			require.Nil(t, rule.validate())
+			regex, err := compileRelabelRegex(rule.Regex)
+			require.Nil(t, err)
+
+			res, keep := (&relabelRule{RelabelConfig: &rule, regex: regex}).apply(attrs)
+			require.Equal(t, test.expectedKeep, keep)
+			if keep {
+				require.Equal(t, test.expectedAttrs, res)
+			}
+		})
+	}
+}
+
+func TestSeriesLimiter(t *testing.T) {
+	limiter := newSeriesLimiter(2)
+
+	cat := []attribute.KeyValue{attribute.String("comm", "cat")}
+	ls := []attribute.KeyValue{attribute.String("comm", "ls")}
+	ping := []attribute.KeyValue{attribute.String("comm", "ping")}
+
+	for _, attrs := range [][]attribute.KeyValue{cat, ls, cat} {
+		res, overflowed := limiter.limit(attrs)
+		require.False(t, overflowed)
+		require.Equal(t, attrs, res)
+	}
+
+	res, overflowed := limiter.limit(ping)
+	require.True(t, overflowed)
+	require.Equal(t, overflowAttrs, res)
+
+	limiter.reset()
+	res, overflowed = limiter.limit(ping)
+	require.False(t, overflowed)
+	require.Equal(t, ping, res)
+}
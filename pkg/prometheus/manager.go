@@ -0,0 +1,159 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/runtime/local"
+)
+
+// Manager runs the metrics of a configuration and allows to replace the configuration while running. Only the
+// metrics that were added, removed or changed are started or stopped. Every metric uses its own meter provider, so
+// stopping it removes it from the exported metrics and a changed metric (e.g. with different buckets) doesn't reuse
+// the instrument of its previous version.
+type Manager struct {
+	ctx              context.Context
+	newMeterProvider MeterProviderFactory
+	runtime          runtime.Runtime
+	logger           logger.Logger
+
+	mu      sync.Mutex
+	running map[string]*runningMetric
+}
+
+type runningMetric struct {
+	meterName   string
+	metric      Metric
+	cancel      context.CancelFunc
+	instruments *Instruments
+	release     func()
+}
+
+func (r *runningMetric) stop() {
+	r.cancel()
+	r.instruments.cleanup()
+	r.release()
+}
+
+func NewManager(ctx context.Context, newMeterProvider MeterProviderFactory, logger logger.Logger) *Manager {
+	return &Manager{
+		ctx:              ctx,
+		newMeterProvider: newMeterProvider,
+		runtime:          &local.Runtime{},
+		logger:           logger,
+		running:          make(map[string]*runningMetric),
+	}
+}
+
+// Apply updates the running metrics to match the given configuration, which needs to be validated using
+// ParseConfig before. Metrics that were removed or changed are stopped and new or changed ones are started. Errors
+// starting a metric are returned, but don't affect any other metric.
+func (m *Manager) Apply(config *Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	meterName := meterName(config)
+
+	wanted := make(map[string]Metric, len(config.Metrics))
+	for _, metric := range config.Metrics {
+		wanted[metric.Name] = metric
+	}
+
+	for name, r := range m.running {
+		if metric, ok := wanted[name]; ok && r.meterName == meterName && reflect.DeepEqual(copyMetric(metric), r.metric) {
+			continue
+		}
+		m.logger.Debugf("stopping metric %q", name)
+		r.stop()
+		delete(m.running, name)
+	}
+
+	var errs []string
+	for _, metric := range config.Metrics {
+		if _, ok := m.running[metric.Name]; ok {
+			continue
+		}
+
+		m.logger.Debugf("starting metric %q", metric.Name)
+
+		meterProvider, release, err := m.newMeterProvider(histogramViews(meterName, &metric)...)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("metric %q: creating meter provider: %s", metric.Name, err))
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(m.ctx)
+		r := &runningMetric{
+			meterName:   meterName,
+			metric:      copyMetric(metric),
+			cancel:      cancel,
+			instruments: &Instruments{},
+			release:     release,
+		}
+
+		// Creating the instrument can modify the metric, so hand over a copy
+		instrumentMetric := copyMetric(metric)
+		meter := meterProvider.Meter(meterName)
+		if err := r.instruments.create(ctx, m.runtime, &instrumentMetric, meter); err != nil {
+			r.stop()
+			errs = append(errs, fmt.Sprintf("metric %q: %s", metric.Name, err))
+			continue
+		}
+		m.running[metric.Name] = r
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("starting metrics: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Running returns the names of the running metrics
+func (m *Manager) Running() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.running))
+	for name := range m.running {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Close stops all metrics
+func (m *Manager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for name, r := range m.running {
+		r.stop()
+		delete(m.running, name)
+	}
+}
+
+func copyMetric(metric Metric) Metric {
+	metric.Labels = append([]string(nil), metric.Labels...)
+	metric.Selector = append([]string(nil), metric.Selector...)
+	metric.Buckets = append([]float64(nil), metric.Buckets...)
+	metric.Relabel = append([]RelabelConfig(nil), metric.Relabel...)
+	return metric
+}
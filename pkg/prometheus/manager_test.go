@@ -0,0 +1,222 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	otelmetric "go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
+)
+
+// stubMeterProviders hands out a new stub meter provider for every metric
+type stubMeterProviders struct {
+	t         *testing.T
+	providers []*stubMeterProvider
+	released  map[*stubMeterProvider]bool
+}
+
+func (s *stubMeterProviders) new(views ...sdkmetric.View) (otelmetric.MeterProvider, func(), error) {
+	p := NewStubMeterProvider(s.t)
+	s.providers = append(s.providers, p)
+	return p, func() { s.released[p] = true }, nil
+}
+
+// int64Counter returns the counter with the given name of a meter provider that wasn't released
+func (s *stubMeterProviders) int64Counter(meterName, name string) *stubInt64Counter {
+	for _, p := range s.providers {
+		if s.released[p] {
+			continue
+		}
+		if meter, ok := p.meters[meterName]; ok {
+			if counter, ok := meter.int64counters[name]; ok {
+				return counter
+			}
+		}
+	}
+	return nil
+}
+
+func TestManager(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	providers := &stubMeterProviders{t: t, released: map[*stubMeterProvider]bool{}}
+	manager := NewManager(ctx, providers.new, logger.DefaultLogger())
+	t.Cleanup(manager.Close)
+
+	counter := func(name string, labels ...string) Metric {
+		return Metric{
+			Name:     name,
+			Type:     "counter",
+			Category: "trace",
+			Gadget:   "stubtracer",
+			Labels:   labels,
+		}
+	}
+
+	config := &Config{
+		MetricsName: "manager",
+		Metrics: []Metric{
+			counter("unchanged", "comm"),
+			counter("changed"),
+			counter("removed"),
+		},
+	}
+	require.Nil(t, manager.Apply(config))
+	require.ElementsMatch(t, []string{"unchanged", "changed", "removed"}, manager.Running())
+
+	const meterName = "gadgets.inspektor-gadget.io/manager"
+	unchanged := providers.int64Counter(meterName, "unchanged")
+	changed := providers.int64Counter(meterName, "changed")
+	require.NotNil(t, unchanged)
+	require.NotNil(t, changed)
+
+	invalid := counter("invalid")
+	invalid.Gadget = "nonexisting"
+
+	config = &Config{
+		MetricsName: "manager",
+		Metrics: []Metric{
+			counter("unchanged", "comm"),
+			counter("changed", "uid"),
+			counter("added"),
+			invalid,
+		},
+	}
+
+	// Errors are reported, but other metrics are still updated
+	require.Error(t, manager.Apply(config))
+	require.ElementsMatch(t, []string{"unchanged", "changed", "added"}, manager.Running())
+
+	// Only the changed metric was created again, the removed one isn't exported anymore
+	require.Same(t, unchanged, providers.int64Counter(meterName, "unchanged"))
+	require.NotNil(t, providers.int64Counter(meterName, "changed"))
+	require.NotSame(t, changed, providers.int64Counter(meterName, "changed"))
+	require.Nil(t, providers.int64Counter(meterName, "removed"))
+
+	manager.Close()
+	require.Empty(t, manager.Running())
+	for _, p := range providers.providers {
+		require.True(t, providers.released[p], "meter provider not released")
+	}
+}
+
+// sdkMeterProviders hands out a new meter provider of the OpenTelemetry SDK for every metric
+type sdkMeterProviders struct {
+	mu      sync.Mutex
+	readers map[sdkmetric.Reader]bool
+}
+
+func (s *sdkMeterProviders) new(views ...sdkmetric.View) (otelmetric.MeterProvider, func(), error) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(views...))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readers[reader] = true
+
+	return provider, func() {
+		provider.Shutdown(context.Background())
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.readers, reader)
+	}, nil
+}
+
+// exported returns the names of all metrics exported by meter providers that weren't released together with the
+// bounds of histograms
+func (s *sdkMeterProviders) exported(t *testing.T) ([]string, map[string][]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	bounds := map[string][]float64{}
+	for reader := range s.readers {
+		rm := &metricdata.ResourceMetrics{}
+		require.Nil(t, reader.Collect(context.Background(), rm))
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				names = append(names, m.Name)
+				if histogram, ok := m.Data.(metricdata.Histogram[float64]); ok && len(histogram.DataPoints) > 0 {
+					bounds[m.Name] = histogram.DataPoints[0].Bounds
+				}
+			}
+		}
+	}
+	return names, bounds
+}
+
+func TestManagerReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// The stub tracer signals through wg once it generated its events
+	wg := &sync.WaitGroup{}
+	ctx = context.WithValue(ctx, valuekey, wg)
+
+	providers := &sdkMeterProviders{readers: map[sdkmetric.Reader]bool{}}
+	manager := NewManager(ctx, providers.new, logger.DefaultLogger())
+	t.Cleanup(manager.Close)
+
+	histogram := func(buckets ...float64) Metric {
+		return Metric{
+			Name:     "histogram",
+			Type:     "histogram",
+			Category: "trace",
+			Gadget:   "stubtracer",
+			Field:    "intval",
+			Buckets:  buckets,
+		}
+	}
+	removed := Metric{
+		Name:     "removed",
+		Type:     "counter",
+		Category: "trace",
+		Gadget:   "stubtracer",
+	}
+
+	wg.Add(2)
+	require.Nil(t, manager.Apply(&Config{
+		MetricsName: "reload",
+		Metrics:     []Metric{histogram(100, 200), removed},
+	}))
+	require.Nil(t, waitTimeout(wg, 5*time.Second))
+
+	names, bounds := providers.exported(t)
+	require.ElementsMatch(t, []string{"histogram", "removed"}, names)
+	require.Equal(t, []float64{100, 200}, bounds["histogram"])
+
+	// Changing the buckets creates the histogram again using the new ones, the removed counter isn't exported
+	// anymore
+	wg.Add(1)
+	require.Nil(t, manager.Apply(&Config{
+		MetricsName: "reload",
+		Metrics:     []Metric{histogram(300, 400, 500)},
+	}))
+	require.Nil(t, waitTimeout(wg, 5*time.Second))
+
+	names, bounds = providers.exported(t)
+	require.ElementsMatch(t, []string{"histogram"}, names)
+	require.Equal(t, []float64{300, 400, 500}, bounds["histogram"])
+}
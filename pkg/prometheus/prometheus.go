@@ -20,8 +20,13 @@ import (
 	"reflect"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
 	otelmetric "go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
 
+	columnsfilter "github.com/inspektor-gadget/inspektor-gadget/pkg/columns/filter"
 	gadgetcontext "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-context"
 	gadgetregistry "github.com/inspektor-gadget/inspektor-gadget/pkg/gadget-registry"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
@@ -39,6 +44,7 @@ const (
 	ParamContainerName = "containername"
 	ParamPodName       = "podname"
 	ParamNamespace     = "namespace"
+	ParamWorkload      = "workload"
 )
 
 type Counter struct {
@@ -51,43 +57,120 @@ type Gauge struct {
 	registration otelmetric.Registration
 }
 
+type Histogram struct {
+	Metric
+}
+
 type Instruments struct {
-	Counters []*Counter
-	Gauges   []*Gauge
+	Counters   []*Counter
+	Gauges     []*Gauge
+	Histograms []*Histogram
 }
 
-func CreateMetrics(ctx context.Context, config *Config, meterProvider otelmetric.MeterProvider) (func(), error) {
+// MeterProviderFactory returns a new meter provider using the given views together with a function releasing it.
+// Metrics created using a released provider aren't exported anymore.
+type MeterProviderFactory func(views ...sdkmetric.View) (otelmetric.MeterProvider, func(), error)
+
+// histogramViews returns the views applying the buckets configured for the given metric, if it's a histogram; they
+// need to be registered in the meter provider the histogram is created with, as views can't be changed later on
+func histogramViews(meterName string, metric *Metric) []sdkmetric.View {
+	if metric.Type != "histogram" || len(metric.Buckets) == 0 {
+		return nil
+	}
+	return []sdkmetric.View{
+		sdkmetric.NewView(
+			sdkmetric.Instrument{
+				Name:  metric.Name,
+				Kind:  sdkmetric.InstrumentKindHistogram,
+				Scope: instrumentation.Scope{Name: meterName},
+			},
+			sdkmetric.Stream{
+				Aggregation: aggregation.ExplicitBucketHistogram{
+					Boundaries: metric.Buckets,
+				},
+			},
+		),
+	}
+}
+
+func meterName(config *Config) string {
+	return fmt.Sprintf("gadgets.inspektor-gadget.io/%s", config.MetricsName)
+}
+
+// CreateMetrics creates all metrics of the given config using a single meter provider created by newMeterProvider;
+// the returned function stops them and releases the meter provider
+func CreateMetrics(ctx context.Context, config *Config, newMeterProvider MeterProviderFactory) (func(), error) {
 	runtime := &local.Runtime{}
 	instruments := &Instruments{}
 
-	meter := meterProvider.Meter(fmt.Sprintf("gadgets.inspektor-gadget.io/%s", config.MetricsName))
+	meterName := meterName(config)
 
+	var views []sdkmetric.View
 	for _, metric := range config.Metrics {
-		switch metric.Type {
-		case "counter":
-			counter, err := createCounter(ctx, runtime, &metric, meter)
-			if err != nil {
-				return nil, err
-			}
-			instruments.Counters = append(instruments.Counters, counter)
-		case "gauge":
-			gauge, err := createGauge(ctx, runtime, &metric, meter)
-			if err != nil {
-				return nil, err
-			}
-			instruments.Gauges = append(instruments.Gauges, gauge)
-		default:
-			return nil, fmt.Errorf("metric type %s not supported", metric.Type)
+		metric := metric
+		views = append(views, histogramViews(meterName, &metric)...)
+	}
+	meterProvider, release, err := newMeterProvider(views...)
+	if err != nil {
+		return nil, fmt.Errorf("creating meter provider: %w", err)
+	}
+	meter := meterProvider.Meter(meterName)
+
+	cleanup := func() {
+		instruments.cleanup()
+		release()
+	}
+
+	for _, metric := range config.Metrics {
+		metric := metric
+		if err := instruments.create(ctx, runtime, &metric, meter); err != nil {
+			cleanup()
+			return nil, err
 		}
 	}
 
-	return func() {
-		for _, gauge := range instruments.Gauges {
-			if gauge.registration != nil {
-				gauge.registration.Unregister()
-			}
+	return cleanup, nil
+}
+
+// create creates the instrument for the given metric and starts collecting it; gadgets of tracers run until ctx is
+// done
+func (instruments *Instruments) create(
+	ctx context.Context,
+	runtime runtime.Runtime,
+	metric *Metric,
+	meter otelmetric.Meter,
+) error {
+	switch metric.Type {
+	case "counter":
+		counter, err := createCounter(ctx, runtime, metric, meter)
+		if err != nil {
+			return err
 		}
-	}, nil
+		instruments.Counters = append(instruments.Counters, counter)
+	case "gauge":
+		gauge, err := createGauge(ctx, runtime, metric, meter)
+		if err != nil {
+			return err
+		}
+		instruments.Gauges = append(instruments.Gauges, gauge)
+	case "histogram":
+		histogram, err := createHistogram(ctx, runtime, metric, meter)
+		if err != nil {
+			return err
+		}
+		instruments.Histograms = append(instruments.Histograms, histogram)
+	default:
+		return fmt.Errorf("metric type %s not supported", metric.Type)
+	}
+	return nil
+}
+
+func (instruments *Instruments) cleanup() {
+	for _, gauge := range instruments.Gauges {
+		if gauge.registration != nil {
+			gauge.registration.Unregister()
+		}
+	}
 }
 
 func handleMetric(
@@ -111,6 +194,11 @@ func handleMetric(
 
 	// Handle namespace/pod/container filtering logic in the kubemanager and localmanager operators
 	for i, filter := range metricCommon.Selector {
+		// Expressions are always handled by the parser
+		if columnsfilter.IsExpression(filter) {
+			continue
+		}
+
 		parts := strings.Split(filter, ":")
 		if len(parts) != 2 {
 			return nil, nil, fmt.Errorf("invalid filter: %s", filter)
@@ -138,6 +226,12 @@ func handleMetric(
 		}
 	}
 
+	// The workload columns are only filled when requested to the kubemanager
+	// operator
+	if usesWorkloadLabels(metricCommon) {
+		operatorsParamCollection.Set(KubeManagerName, ParamWorkload, "true")
+	}
+
 	// FIXME: this is actually a no-op as the operators are only initialized once.
 	operatorsGlobalParamsCollection := operators.GlobalParamsCollection()
 	err := validOperators.Init(operatorsGlobalParamsCollection)
@@ -218,6 +312,12 @@ func createCounter(
 		return nil, err
 	}
 
+	labels, err := newLabelsProcessor(&counter.Metric, meter)
+	if err != nil {
+		return nil, err
+	}
+	labelsGetter := labels.wrapAttrsGetter(ctx, attrsGetter)
+
 	if isInt {
 		otelCounter, err := meter.Int64Counter(counter.Name)
 		if err != nil {
@@ -234,7 +334,10 @@ func createCounter(
 		}
 
 		cb = func(ev any) {
-			attrs := attrsGetter(ev)
+			attrs, ok := labelsGetter(ev)
+			if !ok {
+				return
+			}
 			incr := int64(1)
 			if fieldGetter != nil {
 				incr = fieldGetter(ev)
@@ -257,7 +360,10 @@ func createCounter(
 		}
 
 		cb = func(ev any) {
-			attrs := attrsGetter(ev)
+			attrs, ok := labelsGetter(ev)
+			if !ok {
+				return
+			}
 			incr := float64(1.0)
 			if fieldGetter != nil {
 				incr = fieldGetter(ev)
@@ -327,6 +433,11 @@ func createGauge(
 		}
 	}
 
+	labels, err := newLabelsProcessor(&gauge.Metric, meter)
+	if err != nil {
+		return nil, err
+	}
+
 	var intGauge otelmetric.Int64ObservableGauge
 	var floatGauge otelmetric.Float64ObservableGauge
 
@@ -356,12 +467,40 @@ func createGauge(
 			return err
 		}
 
+		// Series are reported again on each collection, so only the ones of this collection count for the limit.
+		// Relabeling can map different entries to the same series, their values are added up.
+		labels.reset()
+		type gaugeSeries struct {
+			attrs      []attribute.KeyValue
+			int64Val   int64
+			float64Val float64
+		}
+		series := make(map[attribute.Distinct]*gaugeSeries, len(gauges))
+		var order []attribute.Distinct
 		for _, gauge := range gauges {
-			attrs := otelmetric.WithAttributes(gauge.Attrs...)
+			attrs, ok := labels.process(ctx, gauge.Attrs)
+			if !ok {
+				continue
+			}
+			set := attribute.NewSet(attrs...)
+			key := set.Equivalent()
+			val, ok := series[key]
+			if !ok {
+				val = &gaugeSeries{attrs: attrs}
+				series[key] = val
+				order = append(order, key)
+			}
+			val.int64Val += gauge.Int64Val
+			val.float64Val += gauge.Float64Val
+		}
+
+		for _, key := range order {
+			val := series[key]
+			attrs := otelmetric.WithAttributes(val.attrs...)
 			if isInt {
-				obs.ObserveInt64(intGauge, gauge.Int64Val, attrs)
+				obs.ObserveInt64(intGauge, val.int64Val, attrs)
 			} else {
-				obs.ObserveFloat64(floatGauge, gauge.Float64Val, attrs)
+				obs.ObserveFloat64(floatGauge, val.float64Val, attrs)
 			}
 		}
 
@@ -381,3 +520,84 @@ func createGauge(
 
 	return gauge, nil
 }
+
+func createHistogram(
+	ctx context.Context,
+	runtime runtime.Runtime,
+	metric *Metric,
+	meter otelmetric.Meter,
+) (*Histogram, error) {
+	histogram := &Histogram{Metric: *metric}
+
+	gadgetCtx, parser, err := handleMetric(ctx, &histogram.Metric, runtime)
+	if err != nil {
+		return nil, err
+	}
+
+	if gadgetCtx.GadgetDesc().Type() != gadgets.TypeTrace {
+		return nil, fmt.Errorf("histogram %s: only tracer gadgets are supported", histogram.Name)
+	}
+
+	typ, err := parser.GetColKind(histogram.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	default:
+		return nil, fmt.Errorf("histogram %s: unsupported field type %s", histogram.Name, typ)
+	}
+
+	attrsGetter, err := parser.AttrsGetter(histogram.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := newLabelsProcessor(&histogram.Metric, meter)
+	if err != nil {
+		return nil, err
+	}
+	labelsGetter := labels.wrapAttrsGetter(ctx, attrsGetter)
+
+	fieldGetter, err := parser.ColFloatGetter(histogram.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	otelHistogram, err := meter.Float64Histogram(histogram.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	parser.SetEventCallback(func(ev any) {
+		attrs, ok := labelsGetter(ev)
+		if !ok {
+			return
+		}
+		otelHistogram.Record(ctx, fieldGetter(ev), otelmetric.WithAttributes(attrs...))
+	})
+
+	go func() {
+		if _, err = runtime.RunGadget(gadgetCtx); err != nil {
+			gadgetCtx.Logger().Errorf("running gadget: %s", err)
+		}
+	}()
+
+	return histogram, nil
+}
+
+func usesWorkloadLabels(metric *Metric) bool {
+	labels := append([]string{}, metric.Labels...)
+	for _, rule := range metric.Relabel {
+		labels = append(labels, rule.SourceLabels...)
+	}
+	for _, label := range labels {
+		if label == "workloadkind" || label == "workloadname" {
+			return true
+		}
+	}
+	return false
+}
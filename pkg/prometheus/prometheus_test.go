@@ -22,6 +22,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
 // events that are generated in the test. Counters are incremented based on them and the metric
@@ -45,6 +47,8 @@ func TestMetrics(t *testing.T) {
 		expectedFloat64Counters map[string]map[string]float64
 		expectedInt64Gauges     map[string]map[string]int64
 		expectedFloat64Gauges   map[string]map[string]float64
+		// inner value: observations in the order of the events
+		expectedFloat64Histograms map[string]map[string][]float64
 	}
 
 	tests := []testDefinition{
@@ -231,6 +235,24 @@ func TestMetrics(t *testing.T) {
 				"counter_filter_uid_greater_than_0": {"": 2},
 			},
 		},
+		{
+			name: "counter_filter_expression",
+			config: &Config{
+				MetricsName: "counter_filter_expression",
+				Metrics: []Metric{
+					{
+						Name:     "counter_filter_expression",
+						Type:     "counter",
+						Category: "trace",
+						Gadget:   "stubtracer",
+						Selector: []string{`uid == 0 && (comm == "cat" || comm == "ping")`},
+					},
+				},
+			},
+			expectedInt64Counters: map[string]map[string]int64{
+				"counter_filter_expression": {"": 3},
+			},
+		},
 		{
 			name: "counter_aggregate_by_comm",
 			config: &Config{
@@ -553,6 +575,172 @@ func TestMetrics(t *testing.T) {
 				"gauge_filter_only_root_events": {"": 3},
 			},
 		},
+		// Histograms
+		{
+			name: "histogram_wrong_gadget_type",
+			config: &Config{
+				MetricsName: "histogram_wrong_gadget_type",
+				Metrics: []Metric{
+					{
+						Name:     "histogram_wrong_gadget_type",
+						Type:     "histogram",
+						Category: "snapshot",
+						Gadget:   "stubsnapshotter",
+						Field:    "intval",
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "histogram_wrong_type_field",
+			config: &Config{
+				MetricsName: "histogram_wrong_type_field",
+				Metrics: []Metric{
+					{
+						Name:     "histogram_wrong_type_field",
+						Type:     "histogram",
+						Category: "trace",
+						Gadget:   "stubtracer",
+						Field:    "comm",
+					},
+				},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "histogram_int_field_by_comm",
+			config: &Config{
+				MetricsName: "histogram_int_field_by_comm",
+				Metrics: []Metric{
+					{
+						Name:     "histogram_int_field_by_comm",
+						Type:     "histogram",
+						Category: "trace",
+						Gadget:   "stubtracer",
+						Field:    "intval",
+						Labels:   []string{"comm"},
+						Buckets:  []float64{100, 200, 500},
+					},
+				},
+			},
+			expectedFloat64Histograms: map[string]map[string][]float64{
+				"histogram_int_field_by_comm": {
+					"comm=cat,":  {105, 216, 327},
+					"comm=ping,": {428},
+					"comm=ls,":   {429},
+				},
+			},
+		},
+		{
+			name: "histogram_float_field_filter_only_root_events",
+			config: &Config{
+				MetricsName: "histogram_float_field_filter_only_root_events",
+				Metrics: []Metric{
+					{
+						Name:     "histogram_float_field_filter_only_root_events",
+						Type:     "histogram",
+						Category: "trace",
+						Gadget:   "stubtracer",
+						Field:    "floatval",
+						Selector: []string{"uid:0"},
+					},
+				},
+			},
+			expectedFloat64Histograms: map[string]map[string][]float64{
+				"histogram_float_field_filter_only_root_events": {"": {201.2, 423.3, 867.5}},
+			},
+		},
+		// Labels
+		{
+			name: "counter_max_series",
+			config: &Config{
+				Metrics: []Metric{
+					{
+						Name:      "counter_max_series",
+						Type:      "counter",
+						Category:  "trace",
+						Gadget:    "stubtracer",
+						Labels:    []string{"comm"},
+						MaxSeries: 2,
+					},
+				},
+			},
+			expectedInt64Counters: map[string]map[string]int64{
+				"counter_max_series": {"comm=cat,": 3, "comm=ping,": 1, "otel.metric.overflow=true,": 1},
+				OverflowEventsMetric: {"metric=counter_max_series,": 1},
+			},
+		},
+		{
+			name: "counter_relabel",
+			config: &Config{
+				Metrics: []Metric{
+					{
+						Name:     "counter_relabel",
+						Type:     "counter",
+						Category: "trace",
+						Gadget:   "stubtracer",
+						Labels:   []string{"comm"},
+						Relabel: []RelabelConfig{
+							{SourceLabels: []string{"comm"}, Regex: "ls", Action: RelabelDrop},
+							{SourceLabels: []string{"comm"}, Regex: "c(.*)", TargetLabel: "comm", Replacement: "C$1"},
+						},
+					},
+				},
+			},
+			expectedInt64Counters: map[string]map[string]int64{
+				"counter_relabel":   {"comm=Cat,": 3, "comm=ping,": 1},
+				DroppedEventsMetric: {"metric=counter_relabel,": 1},
+			},
+		},
+		{
+			name: "gauge_relabel_merges_series",
+			config: &Config{
+				Metrics: []Metric{
+					{
+						Name:     "gauge_relabel_merges_series",
+						Type:     "gauge",
+						Category: "snapshot",
+						Gadget:   "stubsnapshotter",
+						Labels:   []string{"comm"},
+						Relabel: []RelabelConfig{
+							{SourceLabels: []string{"comm"}, Regex: "cat|ls", TargetLabel: "comm", Replacement: "files"},
+						},
+					},
+				},
+			},
+			expectedInt64Gauges: map[string]map[string]int64{
+				"gauge_relabel_merges_series": {"comm=files,": 4, "comm=ping,": 1},
+			},
+			expectedInt64Counters: map[string]map[string]int64{
+				DroppedEventsMetric: {},
+			},
+		},
+		{
+			name: "histogram_max_series",
+			config: &Config{
+				Metrics: []Metric{
+					{
+						Name:      "histogram_max_series",
+						Type:      "histogram",
+						Category:  "trace",
+						Gadget:    "stubtracer",
+						Field:     "intval",
+						Labels:    []string{"comm"},
+						MaxSeries: 1,
+					},
+				},
+			},
+			expectedInt64Counters: map[string]map[string]int64{
+				OverflowEventsMetric: {"metric=histogram_max_series,": 2},
+			},
+			expectedFloat64Histograms: map[string]map[string][]float64{
+				"histogram_max_series": {
+					"comm=cat,":                  {105, 216, 327},
+					"otel.metric.overflow=true,": {428, 429},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -571,7 +759,7 @@ func TestMetrics(t *testing.T) {
 
 			meterProvider := NewStubMeterProvider(t)
 
-			cleanup, err := CreateMetrics(ctx, test.config, meterProvider)
+			cleanup, err := CreateMetrics(ctx, test.config, meterProvider.Factory())
 			if test.expectedErr {
 				require.Error(t, err)
 				return
@@ -585,6 +773,7 @@ func TestMetrics(t *testing.T) {
 			require.Equal(t, len(test.expectedInt64Counters), len(meter.int64counters))
 			require.Equal(t, len(test.expectedFloat64Counters), len(meter.float64counters))
 			require.Equal(t, len(test.expectedInt64Gauges), len(meter.int64gauges))
+			require.Equal(t, len(test.expectedFloat64Histograms), len(meter.float64histograms))
 
 			// Collect metrics: Update gauges
 			err = meter.Collect(ctx)
@@ -627,10 +816,63 @@ func TestMetrics(t *testing.T) {
 				// require.Equal doesn't work because of float comparisons
 				require.InDeltaMapValues(t, expected, gauge.values, 0.01, "gauge values are wrong")
 			}
+
+			// float64 histograms
+			for name, expected := range test.expectedFloat64Histograms {
+				histogram, ok := meter.float64histograms[name]
+				require.True(t, ok, "float64 histogram %q not found", name)
+
+				require.Equal(t, len(expected), len(histogram.values), "histogram values are wrong")
+				for attrs, observations := range expected {
+					require.InDeltaSlice(t, observations, histogram.values[attrs], 0.01, "histogram values are wrong")
+				}
+			}
 		})
 	}
 }
 
+func TestHistogramViews(t *testing.T) {
+	metric := &Metric{Name: "with_buckets", Type: "histogram", Buckets: []float64{1, 10, 100}}
+	reader := sdkmetric.NewManualReader()
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithView(histogramViews("test_histogram_views", metric)...),
+	)
+	meter := meterProvider.Meter("test_histogram_views")
+
+	withBuckets, err := meter.Float64Histogram("with_buckets")
+	require.Nil(t, err)
+	withoutBuckets, err := meter.Float64Histogram("without_buckets")
+	require.Nil(t, err)
+
+	ctx := context.Background()
+	withBuckets.Record(ctx, 5)
+	withoutBuckets.Record(ctx, 5)
+
+	require.Equal(t, []float64{1, 10, 100}, histogramBounds(t, reader)["with_buckets"])
+	require.NotEqual(t, []float64{1, 10, 100}, histogramBounds(t, reader)["without_buckets"])
+
+	require.Empty(t, histogramViews("test_histogram_views", &Metric{Name: "counter", Type: "counter"}))
+}
+
+// histogramBounds collects the metrics of the reader and returns the bounds of all histograms by name
+func histogramBounds(t *testing.T, reader sdkmetric.Reader) map[string][]float64 {
+	rm := &metricdata.ResourceMetrics{}
+	require.Nil(t, reader.Collect(context.Background(), rm))
+
+	bounds := map[string][]float64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			histogram, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok || len(histogram.DataPoints) == 0 {
+				continue
+			}
+			bounds[m.Name] = histogram.DataPoints[0].Bounds
+		}
+	}
+	return bounds
+}
+
 // Based on https://github.com/embano1/waitgroup/blob/e5229ff7bc061f391c12f2be244bb50f030a6688/waitgroup.go#L27
 func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) error {
 	doneCh := make(chan struct{})
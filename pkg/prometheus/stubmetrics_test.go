@@ -23,6 +23,7 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/metric/embedded"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 )
 
 func NewStubMeterProvider(t *testing.T) *stubMeterProvider {
@@ -32,6 +33,13 @@ func NewStubMeterProvider(t *testing.T) *stubMeterProvider {
 	}
 }
 
+// Factory returns a MeterProviderFactory always handing out the stub meter provider
+func (s *stubMeterProvider) Factory() MeterProviderFactory {
+	return func(views ...sdkmetric.View) (metric.MeterProvider, func(), error) {
+		return s, func() {}, nil
+	}
+}
+
 type stubMeterProvider struct {
 	embedded.MeterProvider
 	t      *testing.T
@@ -50,6 +58,8 @@ func NewStubMeter(t *testing.T) *stubMeter {
 		float64counters: make(map[string]*stubFloat64Counter),
 		int64gauges:     make(map[string]*stubInt64ObservableGauge),
 		float64gauges:   make(map[string]*stubFloat64ObservableGauge),
+
+		float64histograms: make(map[string]*stubFloat64Histogram),
 	}
 }
 
@@ -63,6 +73,8 @@ type stubMeter struct {
 	float64gauges   map[string]*stubFloat64ObservableGauge
 	callbacks       []metric.Callback
 	mu              sync.Mutex
+
+	float64histograms map[string]*stubFloat64Histogram
 }
 
 func (s *stubMeter) Int64Counter(name string, options ...metric.Int64CounterOption) (metric.Int64Counter, error) {
@@ -119,7 +131,14 @@ func (s *stubMeter) Float64UpDownCounter(name string, options ...metric.Float64U
 }
 
 func (s *stubMeter) Float64Histogram(name string, options ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
-	return nil, nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h := &stubFloat64Histogram{
+		values: make(map[string][]float64),
+	}
+	s.float64histograms[name] = h
+	return h, nil
 }
 
 func (s *stubMeter) Float64ObservableCounter(name string, options ...metric.Float64ObservableCounterOption) (metric.Float64ObservableCounter, error) {
@@ -208,6 +227,22 @@ func (c *stubFloat64Counter) Add(ctx context.Context, incr float64, options ...m
 	c.values[attrsToString(attrs.ToSlice())] += incr
 }
 
+type stubFloat64Histogram struct {
+	embedded.Float64Histogram
+	values map[string][]float64
+	mu     sync.Mutex
+}
+
+// Record records an observation of the histogram.
+func (h *stubFloat64Histogram) Record(ctx context.Context, incr float64, options ...metric.RecordOption) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	attrs := metric.NewRecordConfig(options).Attributes()
+	key := attrsToString(attrs.ToSlice())
+	h.values[key] = append(h.values[key], incr)
+}
+
 type stubInt64ObservableGauge struct {
 	embedded.Int64ObservableGauge
 	metric.Int64Observable
@@ -0,0 +1,239 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package recording stores the raw event stream of a gadget run in a file and reads it back later on. Recordings
+are JSON lines: the first line is a Header describing the gadget run, every following line is a Record holding
+either a single event or an array of events (for gadgets of type TypeTraceIntervals).
+*/
+package recording
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+)
+
+// Version is the version of the recording format written by this package
+const Version = 1
+
+type RecordType string
+
+const (
+	RecordTypeHeader     RecordType = "header"
+	RecordTypeEvent      RecordType = "event"
+	RecordTypeEventArray RecordType = "eventArray"
+)
+
+// Header describes the gadget run that has been recorded
+type Header struct {
+	Type           RecordType         `json:"type"`
+	Version        int                `json:"version"`
+	GadgetCategory string             `json:"gadgetCategory"`
+	GadgetName     string             `json:"gadgetName"`
+	GadgetType     gadgets.GadgetType `json:"gadgetType"`
+	Params         map[string]string  `json:"params"`
+	Args           []string           `json:"args,omitempty"`
+	Start          time.Time          `json:"start"`
+}
+
+// Record holds a single recorded event or array of events
+type Record struct {
+	Type    RecordType      `json:"type"`
+	Time    time.Time       `json:"time"`
+	Node    string          `json:"node,omitempty"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Writer writes recordings; it is safe to be used from multiple goroutines
+type Writer struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+// NewWriter creates a new Writer and writes the given header to w
+func NewWriter(w io.Writer, header *Header) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	rw := &Writer{
+		w:   bw,
+		enc: json.NewEncoder(bw),
+	}
+
+	header.Type = RecordTypeHeader
+	header.Version = Version
+	if header.Start.IsZero() {
+		header.Start = time.Now()
+	}
+	if err := rw.enc.Encode(header); err != nil {
+		return nil, fmt.Errorf("writing header: %w", err)
+	}
+	return rw, nil
+}
+
+// Record writes a single event or an array of events; events must be JSON serializable
+func (rw *Writer) Record(node string, ev any) error {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshaling event: %w", err)
+	}
+
+	record := &Record{
+		Type:    RecordTypeEvent,
+		Time:    time.Now(),
+		Node:    node,
+		Payload: payload,
+	}
+	if reflect.ValueOf(ev).Kind() == reflect.Slice {
+		record.Type = RecordTypeEventArray
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.err != nil {
+		return rw.err
+	}
+	rw.err = rw.enc.Encode(record)
+	return rw.err
+}
+
+// RecordCallback returns a parser.RecordCallback that writes all events it receives using rw; errors are passed
+// to onError
+func (rw *Writer) RecordCallback(onError func(error)) parser.RecordCallback {
+	return func(key string, ev any) {
+		if err := rw.Record(key, ev); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Flush writes any buffered data to the underlying writer
+func (rw *Writer) Flush() error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if rw.err != nil {
+		return rw.err
+	}
+	return rw.w.Flush()
+}
+
+// Reader reads recordings written by Writer
+type Reader struct {
+	dec    *json.Decoder
+	header *Header
+}
+
+// NewReader creates a new Reader and reads the header from r
+func NewReader(r io.Reader) (*Reader, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	header := &Header{}
+	if err := dec.Decode(header); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if header.Type != RecordTypeHeader {
+		return nil, fmt.Errorf("invalid recording: expected header, got %q", header.Type)
+	}
+	if header.Version > Version {
+		return nil, fmt.Errorf("unsupported recording version %d (supported up to %d)", header.Version, Version)
+	}
+
+	return &Reader{
+		dec:    dec,
+		header: header,
+	}, nil
+}
+
+// Header returns the header of the recording
+func (r *Reader) Header() *Header {
+	return r.header
+}
+
+// Next returns the next record or io.EOF if no more records are available
+func (r *Reader) Next() (*Record, error) {
+	record := &Record{}
+	if err := r.dec.Decode(record); err != nil {
+		return nil, err
+	}
+	switch record.Type {
+	case RecordTypeEvent, RecordTypeEventArray:
+	default:
+		return nil, fmt.Errorf("invalid record type %q", record.Type)
+	}
+	return record, nil
+}
+
+// Replay reads all records from r and hands them over to the JSON handlers of p, as if they were coming from a live
+// gadget run. The original timing between records is kept, scaled by speed; a speed of 0 replays as fast as
+// possible.
+func (r *Reader) Replay(ctx context.Context, p parser.Parser, speed float64) error {
+	// Handlers are created on demand, as the parser only has callbacks set for the kind of events the gadget emits
+	var jsonHandler func([]byte)
+	jsonArrayHandlers := make(map[string]func([]byte))
+
+	var last time.Time
+	for {
+		record, err := r.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("reading record: %w", err)
+		}
+
+		if speed > 0 && !last.IsZero() {
+			delay := time.Duration(float64(record.Time.Sub(last)) / speed)
+			if delay > 0 {
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-time.After(delay):
+				}
+			}
+		}
+		last = record.Time
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		if record.Type == RecordTypeEventArray {
+			handler, ok := jsonArrayHandlers[record.Node]
+			if !ok {
+				handler = p.JSONHandlerFuncArray(record.Node)
+				jsonArrayHandlers[record.Node] = handler
+			}
+			handler(record.Payload)
+			continue
+		}
+		if jsonHandler == nil {
+			jsonHandler = p.JSONHandlerFunc()
+		}
+		jsonHandler(record.Payload)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package recording
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/columns"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/parser"
+)
+
+type testEvent struct {
+	Node string `json:"node" column:"node"`
+	Comm string `json:"comm" column:"comm"`
+	Pid  int    `json:"pid" column:"pid"`
+}
+
+func newTestParser(t *testing.T) parser.Parser {
+	cols, err := columns.NewColumns[testEvent]()
+	require.NoError(t, err)
+	return parser.NewParser(cols)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	w, err := NewWriter(buf, &Header{
+		GadgetCategory: "trace",
+		GadgetName:     "exec",
+		GadgetType:     gadgets.TypeTrace,
+		Params:         map[string]string{"foo": "bar"},
+	})
+	require.NoError(t, err)
+
+	// Record events coming through the parser, including the ones that get filtered out
+	p := newTestParser(t)
+	p.SetEventCallback(func(*testEvent) {})
+	require.NoError(t, p.SetFilters([]string{"comm:curl"}))
+	p.SetRecordCallback(w.RecordCallback(func(err error) {
+		t.Errorf("recording: %v", err)
+	}))
+
+	handler := p.EventHandlerFunc().(func(*testEvent))
+	handler(&testEvent{Node: "node1", Comm: "curl", Pid: 1})
+	handler(&testEvent{Node: "node1", Comm: "bash", Pid: 2})
+	handler(&testEvent{Node: "node2", Comm: "curl", Pid: 3})
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, RecordTypeHeader, r.Header().Type)
+	require.Equal(t, Version, r.Header().Version)
+	require.Equal(t, "exec", r.Header().GadgetName)
+	require.Equal(t, "bar", r.Header().Params["foo"])
+
+	// Replay using a new parser and a different filter
+	replayParser := newTestParser(t)
+	require.NoError(t, replayParser.SetFilters([]string{"node:node1"}))
+	var got []*testEvent
+	replayParser.SetEventCallback(func(ev *testEvent) {
+		got = append(got, ev)
+	})
+
+	require.NoError(t, r.Replay(context.Background(), replayParser, 0))
+	require.Equal(t, []*testEvent{
+		{Node: "node1", Comm: "curl", Pid: 1},
+		{Node: "node1", Comm: "bash", Pid: 2},
+	}, got)
+}
+
+func TestRecordAndReplayArrays(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	w, err := NewWriter(buf, &Header{
+		GadgetCategory: "top",
+		GadgetName:     "file",
+		GadgetType:     gadgets.TypeTraceIntervals,
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Record("node1", []*testEvent{{Comm: "a"}, {Comm: "b"}}))
+	require.NoError(t, w.Record("node2", []*testEvent{{Comm: "c"}}))
+	require.NoError(t, w.Flush())
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	p := newTestParser(t)
+	var got [][]*testEvent
+	p.SetEventCallback(func(evs []*testEvent) {
+		got = append(got, evs)
+	})
+
+	require.NoError(t, r.Replay(context.Background(), p, 0))
+	require.Equal(t, [][]*testEvent{
+		{{Comm: "a"}, {Comm: "b"}},
+		{{Comm: "c"}},
+	}, got)
+}
+
+func TestRecordDroppedEvents(t *testing.T) {
+	p := newTestParser(t)
+	require.NoError(t, p.SetFilters([]string{"comm:curl"}))
+
+	var recorded, got []*testEvent
+	p.SetRecordCallback(func(key string, ev any) {
+		recorded = append(recorded, ev.(*testEvent))
+	})
+	p.SetEventCallback(func(ev *testEvent) {
+		got = append(got, ev)
+	})
+
+	// The filtered enricher only sees events matching the filters
+	var filtered []int
+	p.SetFilteredEnricher(func(ev any) error {
+		filtered = append(filtered, ev.(*testEvent).Pid)
+		if ev.(*testEvent).Pid == 3 {
+			return parser.ErrDropEvent
+		}
+		return nil
+	})
+
+	dropPid2 := func(ev any) error {
+		if ev.(*testEvent).Pid == 2 {
+			return parser.ErrDropEvent
+		}
+		return nil
+	}
+	setNode := func(ev any) error {
+		ev.(*testEvent).Node = "node1"
+		return nil
+	}
+
+	handler := p.EventHandlerFunc(dropPid2, setNode).(func(*testEvent))
+	handler(&testEvent{Comm: "curl", Pid: 1})
+	handler(&testEvent{Comm: "curl", Pid: 2})
+	handler(&testEvent{Comm: "curl", Pid: 3})
+	handler(&testEvent{Comm: "bash", Pid: 4})
+
+	// Dropped events are recorded with the enrichment of all enrichers
+	require.Equal(t, []*testEvent{
+		{Node: "node1", Comm: "curl", Pid: 1},
+		{Node: "node1", Comm: "curl", Pid: 2},
+		{Node: "node1", Comm: "curl", Pid: 3},
+		{Node: "node1", Comm: "bash", Pid: 4},
+	}, recorded)
+	require.Equal(t, []int{1, 3}, filtered)
+	require.Equal(t, []*testEvent{{Node: "node1", Comm: "curl", Pid: 1}}, got)
+}
+
+func TestInvalidRecording(t *testing.T) {
+	_, err := NewReader(bytes.NewReader([]byte(`{"type":"event"}`)))
+	require.Error(t, err)
+
+	_, err = NewReader(bytes.NewReader([]byte(`{"type":"header","version":1000}`)))
+	require.Error(t, err)
+}
@@ -29,10 +29,12 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/inspektor-gadget/inspektor-gadget/cmd/kubectl-gadget/utils"
 	"github.com/inspektor-gadget/inspektor-gadget/internal/deployinfo"
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
 	pb "github.com/inspektor-gadget/inspektor-gadget/pkg/gadgettracermanager/api"
 	"github.com/inspektor-gadget/inspektor-gadget/pkg/logger"
@@ -42,7 +44,10 @@ import (
 )
 
 const (
-	ParamNode = "node"
+	ParamNode                  = "node"
+	ParamNodeSelector          = "node-selector"
+	ParamExcludeNodeTaints     = "exclude-node-taints"
+	ParamNodesWithMatchingPods = "nodes-with-matching-pods"
 
 	// ConnectTimeout is the time in seconds we wait for a connection to the pod to
 	// succeed
@@ -113,9 +118,9 @@ func (r *Runtime) ParamDescs() params.ParamDescs {
 	return params.ParamDescs{
 		{
 			Key:         ParamNode,
-			Description: "Comma-separated list of nodes to run the gadget on",
+			Description: "Comma-separated list of nodes to run the gadget on. Supports globs, /regular expressions/ and exclusions prefixed with '!'",
 			Validator: func(value string) error {
-				nodes := strings.Split(value, ",")
+				nodes := containercollection.SplitPatterns(value)
 				nodeMap := make(map[string]struct{})
 				for _, node := range nodes {
 					if _, ok := nodeMap[node]; ok {
@@ -123,9 +128,27 @@ func (r *Runtime) ParamDescs() params.ParamDescs {
 					}
 					nodeMap[node] = struct{}{}
 				}
-				return nil
+				return containercollection.ValidatePatterns(value)
+			},
+		},
+		{
+			Key:         ParamNodeSelector,
+			Description: "Labels selector of the nodes to run the gadget on (e.g. agentpool=pool1,kubernetes.io/os=linux)",
+			Validator: func(value string) error {
+				_, err := labels.Parse(value)
+				return err
 			},
 		},
+		{
+			Key:         ParamExcludeNodeTaints,
+			Description: "Comma-separated list of taints (key or key=value) of nodes the gadget must not run on",
+		},
+		{
+			Key:          ParamNodesWithMatchingPods,
+			Description:  "Only run the gadget on the nodes where pods matching the namespace, pod name, container name and labels selectors are scheduled",
+			TypeHint:     params.TypeBool,
+			DefaultValue: "false",
+		},
 	}
 }
 
@@ -138,7 +161,7 @@ type gadgetPod struct {
 	node string
 }
 
-func getGadgetPods(ctx context.Context, nodes []string) ([]gadgetPod, error) {
+func getGadgetPods(ctx context.Context, selection *nodeSelection) ([]gadgetPod, error) {
 	config, err := utils.KubernetesConfigFlags.ToRESTConfig()
 	if err != nil {
 		return nil, fmt.Errorf("creating RESTConfig: %w", err)
@@ -159,40 +182,23 @@ func getGadgetPods(ctx context.Context, nodes []string) ([]gadgetPod, error) {
 		return nil, fmt.Errorf("no gadget pods found. Is Inspektor Gadget deployed?")
 	}
 
-	if len(nodes) == 0 {
-		res := make([]gadgetPod, 0, len(pods.Items))
-
-		for _, pod := range pods.Items {
-			res = append(res, gadgetPod{name: pod.Name, node: pod.Spec.NodeName})
-		}
-
-		return res, nil
-	}
-
-	res := make([]gadgetPod, 0, len(nodes))
-nodesLoop:
-	for _, node := range nodes {
-		for _, pod := range pods.Items {
-			if node == pod.Spec.NodeName {
-				res = append(res, gadgetPod{name: pod.Name, node: node})
-				continue nodesLoop
-			}
-		}
-		return nil, fmt.Errorf("node %q does not have a gadget pod", node)
+	allowedNodes, err := selection.allowedNodes(ctx, client)
+	if err != nil {
+		return nil, err
 	}
 
-	return res, nil
+	return filterGadgetPods(pods.Items, selection.nodes, allowedNodes)
 }
 
 func (r *Runtime) RunGadget(gadgetCtx runtime.GadgetContext) (runtime.CombinedGadgetResult, error) {
 	// Get nodes to run on
-	nodes := gadgetCtx.RuntimeParams().Get(ParamNode).AsStringSlice()
-	pods, err := getGadgetPods(gadgetCtx.Context(), nodes)
+	selection := newNodeSelection(gadgetCtx.RuntimeParams(), gadgetCtx.OperatorsParamCollection())
+	pods, err := getGadgetPods(gadgetCtx.Context(), selection)
 	if err != nil {
 		return nil, fmt.Errorf("get gadget pods: %w", err)
 	}
 	if len(pods) == 0 {
-		return nil, fmt.Errorf("get gadget pods: Inspektor Gadget is not running on the requested node(s): %v", nodes) //nolint:all
+		return nil, fmt.Errorf("get gadget pods: Inspektor Gadget is not running on the requested node(s)") //nolint:all
 	}
 
 	if gadgetCtx.GadgetDesc().Type() == gadgets.TypeTraceIntervals {
@@ -220,6 +226,11 @@ func (r *Runtime) RunGadget(gadgetCtx runtime.GadgetContext) (runtime.CombinedGa
 		gadgetCtx.OperatorsParamCollection(),
 	)
 
+	// Filters that can be applied in eBPF need to be handed over to the remote side
+	if kernelFilters, ok := gadgetCtx.GadgetDesc().(gadgets.GadgetKernelFilters); ok && gadgetCtx.Parser() != nil {
+		gadgets.KernelFiltersToMap(allParams, gadgetCtx.Parser().EqualityFilters(kernelFilters.KernelFilterColumns()...))
+	}
+
 	gadgetCtx.Logger().Debugf("Params")
 	for k, v := range allParams {
 		gadgetCtx.Logger().Debugf("- %s: %q", k, v)
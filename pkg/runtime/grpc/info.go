@@ -33,7 +33,7 @@ func loadRemoteDeployInfo() (*deployinfo.DeployInfo, error) {
 	defer cancelDial()
 
 	// Get a random gadget pod and get the info from there
-	pods, err := getGadgetPods(ctx, []string{})
+	pods, err := getGadgetPods(ctx, &nodeSelection{})
 	if err != nil {
 		return nil, fmt.Errorf("get gadget pods: %w", err)
 	}
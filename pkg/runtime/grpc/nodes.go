@@ -0,0 +1,234 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcruntime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/gadgets"
+	"github.com/inspektor-gadget/inspektor-gadget/pkg/params"
+)
+
+// paramAllNamespaces is the key of the param of the KubeManager operator; it has no value hint to look it up
+const paramAllNamespaces = "all-namespaces"
+
+// nodeSelection restricts the nodes a gadget is run on
+type nodeSelection struct {
+	// nodes is a comma-separated list of node names or patterns, see containercollection.MatchPatterns
+	nodes string
+
+	// labelSelector selects nodes by their labels
+	labelSelector string
+
+	// excludeTaints holds taints, as key or key=value, of nodes to skip
+	excludeTaints []string
+
+	// pods restricts the nodes to the ones pods matching it are scheduled on
+	pods *podSelection
+}
+
+// podSelection holds the container selection given to the KubeManager operator
+type podSelection struct {
+	namespaces     string
+	allNamespaces  bool
+	podNames       string
+	containerNames string
+	labelSelector  string
+}
+
+// newNodeSelection gets the node selection from the runtime params. The selection of pods is looked up by value
+// hint in the operator params, as the runtime doesn't know the operators.
+func newNodeSelection(runtimeParams *params.Params, operatorParams params.Collection) *nodeSelection {
+	selection := &nodeSelection{
+		nodes:         runtimeParams.Get(ParamNode).AsString(),
+		labelSelector: runtimeParams.Get(ParamNodeSelector).AsString(),
+	}
+	if taints := runtimeParams.Get(ParamExcludeNodeTaints).AsString(); taints != "" {
+		selection.excludeTaints = strings.Split(taints, ",")
+	}
+
+	if !runtimeParams.Get(ParamNodesWithMatchingPods).AsBool() {
+		return selection
+	}
+
+	selection.pods = &podSelection{}
+	for _, operatorParams := range operatorParams {
+		for _, p := range *operatorParams {
+			switch {
+			case p.ValueHint == gadgets.K8SNamespace:
+				selection.pods.namespaces = p.AsString()
+			case p.ValueHint == gadgets.K8SPodName:
+				selection.pods.podNames = p.AsString()
+			case p.ValueHint == gadgets.K8SContainerName:
+				selection.pods.containerNames = p.AsString()
+			case p.ValueHint == gadgets.K8SLabels:
+				selection.pods.labelSelector = p.AsString()
+			case p.Key == paramAllNamespaces:
+				selection.pods.allNamespaces = p.AsBool()
+			}
+		}
+	}
+	return selection
+}
+
+// allowedNodes returns the names of the nodes matching the label selector, taints and pods of the selection, or nil
+// if the selection doesn't restrict them. Node names are handled by filterGadgetPods.
+func (s *nodeSelection) allowedNodes(ctx context.Context, client kubernetes.Interface) (map[string]struct{}, error) {
+	var allowed map[string]struct{}
+	restrict := func(nodes map[string]struct{}) {
+		if allowed == nil {
+			allowed = nodes
+			return
+		}
+		for node := range allowed {
+			if _, ok := nodes[node]; !ok {
+				delete(allowed, node)
+			}
+		}
+	}
+
+	if s.labelSelector != "" || len(s.excludeTaints) > 0 {
+		opts := metav1.ListOptions{LabelSelector: s.labelSelector}
+		nodes, err := client.CoreV1().Nodes().List(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("getting nodes: %w", err)
+		}
+
+		selected := make(map[string]struct{})
+		for _, node := range nodes.Items {
+			if !hasTaint(node.Spec.Taints, s.excludeTaints) {
+				selected[node.Name] = struct{}{}
+			}
+		}
+		restrict(selected)
+	}
+
+	if s.pods != nil {
+		nodes, err := s.pods.nodes(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		restrict(nodes)
+	}
+
+	return allowed, nil
+}
+
+// hasTaint tells if any of the taints matches one of the given ones, given as key or key=value
+func hasTaint(taints []corev1.Taint, keys []string) bool {
+	for _, taint := range taints {
+		for _, key := range keys {
+			k, v, hasValue := strings.Cut(key, "=")
+			if taint.Key == k && (!hasValue || taint.Value == v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodes returns the nodes the matching pods are scheduled on
+func (s *podSelection) nodes(ctx context.Context, client kubernetes.Interface) (map[string]struct{}, error) {
+	namespaces := s.namespaces
+	if s.allNamespaces {
+		// Exclusions still apply with all namespaces
+		var exclusions []string
+		for _, pattern := range containercollection.SplitPatterns(namespaces) {
+			if strings.HasPrefix(pattern, "!") {
+				exclusions = append(exclusions, pattern)
+			}
+		}
+		namespaces = strings.Join(exclusions, ",")
+	}
+
+	// Only list the pods of the namespace if it's a single one
+	listNamespace := ""
+	if namespaces != "" && !strings.ContainsAny(namespaces, ",!/*?[") {
+		listNamespace = namespaces
+	}
+
+	opts := metav1.ListOptions{LabelSelector: s.labelSelector}
+	pods, err := client.CoreV1().Pods(listNamespace).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("getting pods: %w", err)
+	}
+
+	nodes := make(map[string]struct{})
+	for _, pod := range pods.Items {
+		// Pods that aren't scheduled or already completed don't need the gadget
+		if pod.Spec.NodeName == "" ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if !containercollection.MatchPatterns(namespaces, pod.Namespace) ||
+			!containercollection.MatchPatterns(s.podNames, pod.Name) {
+			continue
+		}
+		if s.containerNames != "" {
+			found := false
+			for _, container := range pod.Spec.Containers {
+				if containercollection.MatchPatterns(s.containerNames, container.Name) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		nodes[pod.Spec.NodeName] = struct{}{}
+	}
+	return nodes, nil
+}
+
+// filterGadgetPods returns the gadget pods running on nodes matching the given patterns and part of allowedNodes,
+// unless it's nil. Nodes given by their exact name need to have a gadget pod.
+func filterGadgetPods(pods []corev1.Pod, nodes string, allowedNodes map[string]struct{}) ([]gadgetPod, error) {
+	if nodes != "" {
+	nodesLoop:
+		for _, node := range containercollection.SplitPatterns(nodes) {
+			if strings.ContainsAny(node, "!/*?[") {
+				continue
+			}
+			for _, pod := range pods {
+				if node == pod.Spec.NodeName {
+					continue nodesLoop
+				}
+			}
+			return nil, fmt.Errorf("node %q does not have a gadget pod", node)
+		}
+	}
+
+	res := make([]gadgetPod, 0, len(pods))
+	for _, pod := range pods {
+		if !containercollection.MatchPatterns(nodes, pod.Spec.NodeName) {
+			continue
+		}
+		if allowedNodes != nil {
+			if _, ok := allowedNodes[pod.Spec.NodeName]; !ok {
+				continue
+			}
+		}
+		res = append(res, gadgetPod{name: pod.Name, node: pod.Spec.NodeName})
+	}
+	return res, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcruntime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func gadgetPodOn(node string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "gadget-" + node, Namespace: "gadget"},
+		Spec:       corev1.PodSpec{NodeName: node},
+	}
+}
+
+func podNodes(pods []gadgetPod) []string {
+	nodes := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		nodes = append(nodes, pod.node)
+	}
+	return nodes
+}
+
+func TestFilterGadgetPods(t *testing.T) {
+	pods := []corev1.Pod{gadgetPodOn("pool1-a"), gadgetPodOn("pool1-b"), gadgetPodOn("pool2-a")}
+
+	res, err := filterGadgetPods(pods, "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool1-a", "pool1-b", "pool2-a"}, podNodes(res))
+
+	res, err = filterGadgetPods(pods, "pool2-a,pool1-b", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool1-b", "pool2-a"}, podNodes(res))
+
+	res, err = filterGadgetPods(pods, "pool1-*,!pool1-b", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool1-a"}, podNodes(res))
+
+	res, err = filterGadgetPods(pods, "/^pool[0-9]-a$/", map[string]struct{}{"pool2-a": {}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool2-a"}, podNodes(res))
+
+	_, err = filterGadgetPods(pods, "pool1-a,pool3-a", nil)
+	assert.Error(t, err, "exact node names need a gadget pod")
+
+	res, err = filterGadgetPods(pods, "/^pool[0-9]{1,2}-a$/", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pool1-a", "pool2-a"}, podNodes(res))
+
+	res, err = filterGadgetPods(pods, "pool3-*", nil)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func TestAllowedNodes(t *testing.T) {
+	node := func(name string, labels map[string]string, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       corev1.NodeSpec{Taints: taints},
+		}
+	}
+	pod := func(namespace, name, node, container string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Spec: corev1.PodSpec{
+				NodeName:   node,
+				Containers: []corev1.Container{{Name: container}},
+			},
+		}
+	}
+
+	completed := func(namespace, name, node string, phase corev1.PodPhase) *corev1.Pod {
+		p := pod(namespace, name, node, "nginx", map[string]string{"app": "nginx"})
+		p.Status.Phase = phase
+		return p
+	}
+
+	client := fake.NewSimpleClientset(
+		node("pool1-a", map[string]string{"pool": "pool1"}),
+		node("pool1-b", map[string]string{"pool": "pool1"}, corev1.Taint{Key: "spot", Value: "true"}),
+		node("pool2-a", map[string]string{"pool": "pool2"}),
+		pod("default", "nginx-1", "pool1-a", "nginx", map[string]string{"app": "nginx"}),
+		pod("default", "nginx-2", "pool2-a", "nginx", map[string]string{"app": "nginx"}),
+		pod("default", "redis", "pool1-b", "redis", map[string]string{"app": "redis"}),
+		pod("other", "nginx-3", "pool1-b", "nginx", map[string]string{"app": "nginx"}),
+		pod("default", "pending", "", "nginx", map[string]string{"app": "nginx"}),
+		completed("default", "nginx-job", "pool1-b", corev1.PodSucceeded),
+		completed("default", "nginx-crashed", "pool1-b", corev1.PodFailed),
+	)
+
+	tests := map[string]struct {
+		selection *nodeSelection
+		expected  map[string]struct{}
+	}{
+		"no restriction": {
+			selection: &nodeSelection{nodes: "pool1-a"},
+			expected:  nil,
+		},
+		"label selector": {
+			selection: &nodeSelection{labelSelector: "pool=pool1"},
+			expected:  map[string]struct{}{"pool1-a": {}, "pool1-b": {}},
+		},
+		"taints": {
+			selection: &nodeSelection{excludeTaints: []string{"spot=true"}},
+			expected:  map[string]struct{}{"pool1-a": {}, "pool2-a": {}},
+		},
+		"taint with other value": {
+			selection: &nodeSelection{excludeTaints: []string{"spot=false"}},
+			expected:  map[string]struct{}{"pool1-a": {}, "pool1-b": {}, "pool2-a": {}},
+		},
+		"pods in namespace": {
+			selection: &nodeSelection{pods: &podSelection{namespaces: "default", labelSelector: "app=nginx"}},
+			expected:  map[string]struct{}{"pool1-a": {}, "pool2-a": {}},
+		},
+		"pods in all namespaces": {
+			selection: &nodeSelection{pods: &podSelection{allNamespaces: true, podNames: "nginx-*"}},
+			expected:  map[string]struct{}{"pool1-a": {}, "pool1-b": {}, "pool2-a": {}},
+		},
+		"pods with excluded namespace": {
+			selection: &nodeSelection{pods: &podSelection{namespaces: "!default", allNamespaces: true}},
+			expected:  map[string]struct{}{"pool1-b": {}},
+		},
+		"pods with excluded namespace regex": {
+			selection: &nodeSelection{pods: &podSelection{namespaces: "!/^def.{1,5}$/", allNamespaces: true}},
+			expected:  map[string]struct{}{"pool1-b": {}},
+		},
+		"pods by container name": {
+			selection: &nodeSelection{pods: &podSelection{namespaces: "default", containerNames: "redis"}},
+			expected:  map[string]struct{}{"pool1-b": {}},
+		},
+		"label selector and pods": {
+			selection: &nodeSelection{
+				labelSelector: "pool=pool1",
+				pods:          &podSelection{namespaces: "default", labelSelector: "app=nginx"},
+			},
+			expected: map[string]struct{}{"pool1-a": {}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			allowed, err := test.selection.allowedNodes(context.Background(), client)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, allowed)
+		})
+	}
+}
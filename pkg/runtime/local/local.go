@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/cilium/ebpf"
 
@@ -92,6 +93,20 @@ func (r *Runtime) RunGadget(gadgetCtx runtime.GadgetContext) (runtime.CombinedGa
 		return nil, fmt.Errorf("instantiating gadget: %w", err)
 	}
 
+	// Push down filters, if supported
+	if kernelFilters, ok := gadgetCtx.GadgetDesc().(gadgets.GadgetKernelFilters); ok && gadgetCtx.Parser() != nil {
+		if setter, ok := gadgetInstance.(gadgets.KernelFiltersSetter); ok {
+			filters := gadgetCtx.Parser().EqualityFilters(kernelFilters.KernelFilterColumns()...)
+			if len(filters) > 0 {
+				log.Debugf("pushing down filters: %v", filters)
+				err = setter.SetKernelFilters(filters)
+				if err != nil {
+					return nil, fmt.Errorf("setting kernel filters: %w", err)
+				}
+			}
+		}
+	}
+
 	// Initialize gadgets, if needed
 	if initClose, ok := gadgetInstance.(gadgets.InitCloseGadget); ok {
 		log.Debugf("calling gadget.Init()")
@@ -115,10 +130,21 @@ func (r *Runtime) RunGadget(gadgetCtx runtime.GadgetContext) (runtime.CombinedGa
 		log.Debugf("  %s", operator.Name())
 	}
 
+	// Filtering and exporting operators see events once the parser filtered them
+	if gadgetCtx.Parser() != nil {
+		gadgetCtx.Parser().SetFilteredEnricher(operatorInstances.EnrichFiltered)
+	}
+
 	// Set event handler
 	if setter, ok := gadgetInstance.(gadgets.EventHandlerSetter); ok {
 		log.Debugf("set event handler")
 		setter.SetEventHandler(gadgetCtx.Parser().EventHandlerFunc(operatorInstances.Enrich))
+
+		// Allow operators to emit events on their own; those aren't enriched by the operators again
+		emitter := reflect.ValueOf(gadgetCtx.Parser().EventHandlerFunc())
+		operatorInstances.SetEventEmitter(func(ev any) {
+			emitter.Call([]reflect.Value{reflect.ValueOf(ev)})
+		})
 	}
 
 	// Set event handler for array results
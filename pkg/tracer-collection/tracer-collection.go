@@ -28,6 +28,7 @@ import (
 const (
 	MaxContainersPerNode = 1024
 	MountMapPrefix       = "mntnsset_"
+	CgroupMapPrefix      = "cgroupset_"
 )
 
 type TracerCollection struct {
@@ -43,6 +44,10 @@ type tracer struct {
 
 	mntnsSetMap *ebpf.Map
 
+	// cgroupIDSetMap is populated with the cgroup ID of the containers
+	// instead, for the gadgets filtering by cgroup
+	cgroupIDSetMap *ebpf.Map
+
 	gadgetStream *stream.GadgetStream
 }
 
@@ -76,32 +81,57 @@ func (tc *TracerCollection) TracerMapsUpdater() containercollection.FuncNotify {
 
 			for _, t := range tc.tracers {
 				if containercollection.ContainerSelectorMatches(&t.containerSelector, event.Container) {
-					mntnsC := uint64(event.Container.Mntns)
-					one := uint32(1)
-					if mntnsC != 0 {
-						t.mntnsSetMap.Put(mntnsC, one)
-					} else {
-						log.Errorf("new container with mntns=0")
-					}
+					addToSetMaps(t.mntnsSetMap, t.cgroupIDSetMap, event.Container)
 				}
 			}
 
 		case containercollection.EventTypeRemoveContainer:
 			for _, t := range tc.tracers {
 				if containercollection.ContainerSelectorMatches(&t.containerSelector, event.Container) {
-					mntnsC := uint64(event.Container.Mntns)
-					t.mntnsSetMap.Delete(mntnsC)
+					removeFromSetMaps(t.mntnsSetMap, t.cgroupIDSetMap, event.Container)
 				}
 			}
 		}
 	}
 }
 
+// setMap is the subset of *ebpf.Map used to update the maps of the tracers
+type setMap interface {
+	Put(key, value interface{}) error
+	Delete(key interface{}) error
+}
+
+// addToSetMaps adds the mount namespace and the cgroup ID of a container to
+// the maps of a tracer. Host cgroups share the mount namespace of the host, so
+// they're only added by cgroup ID: otherwise all host processes would be
+// selected.
+func addToSetMaps(mntnsSetMap, cgroupIDSetMap setMap, c *containercollection.Container) {
+	one := uint32(1)
+	if !c.HostCgroup {
+		if mntnsC := uint64(c.Mntns); mntnsC != 0 {
+			mntnsSetMap.Put(mntnsC, one)
+		} else {
+			log.Errorf("new container with mntns=0")
+		}
+	}
+	if c.CgroupID != 0 {
+		cgroupIDSetMap.Put(c.CgroupID, one)
+	}
+}
+
+// removeFromSetMaps undoes addToSetMaps
+func removeFromSetMaps(mntnsSetMap, cgroupIDSetMap setMap, c *containercollection.Container) {
+	if !c.HostCgroup {
+		mntnsSetMap.Delete(uint64(c.Mntns))
+	}
+	cgroupIDSetMap.Delete(c.CgroupID)
+}
+
 func (tc *TracerCollection) AddTracer(id string, containerSelector containercollection.ContainerSelector) error {
 	if _, ok := tc.tracers[id]; ok {
 		return fmt.Errorf("tracer id %q: %w", id, os.ErrExist)
 	}
-	var mntnsSetMap *ebpf.Map
+	var mntnsSetMap, cgroupIDSetMap *ebpf.Map
 	if !tc.testOnly {
 		mntnsSpec := &ebpf.MapSpec{
 			Name:       MountMapPrefix + id,
@@ -116,18 +146,28 @@ func (tc *TracerCollection) AddTracer(id string, containerSelector containercoll
 			return fmt.Errorf("creating mntnsset map: %w", err)
 		}
 
+		cgroupSpec := &ebpf.MapSpec{
+			Name:       CgroupMapPrefix + id,
+			Type:       ebpf.Hash,
+			KeySize:    8,
+			ValueSize:  4,
+			MaxEntries: MaxContainersPerNode,
+		}
+		cgroupIDSetMap, err = ebpf.NewMap(cgroupSpec)
+		if err != nil {
+			mntnsSetMap.Close()
+			return fmt.Errorf("creating cgroupset map: %w", err)
+		}
+
 		tc.containerCollection.ContainerRangeWithSelector(&containerSelector, func(c *containercollection.Container) {
-			one := uint32(1)
-			mntnsC := uint64(c.Mntns)
-			if mntnsC != 0 {
-				mntnsSetMap.Put(mntnsC, one)
-			}
+			addToSetMaps(mntnsSetMap, cgroupIDSetMap, c)
 		})
 	}
 	tc.tracers[id] = tracer{
 		tracerID:          id,
 		containerSelector: containerSelector,
 		mntnsSetMap:       mntnsSetMap,
+		cgroupIDSetMap:    cgroupIDSetMap,
 		gadgetStream:      stream.NewGadgetStream(),
 	}
 	return nil
@@ -146,6 +186,9 @@ func (tc *TracerCollection) RemoveTracer(id string) error {
 	if t.mntnsSetMap != nil {
 		t.mntnsSetMap.Close()
 	}
+	if t.cgroupIDSetMap != nil {
+		t.cgroupIDSetMap.Close()
+	}
 
 	t.gadgetStream.Close()
 
@@ -198,3 +241,12 @@ func (tc *TracerCollection) TracerMountNsMap(id string) (*ebpf.Map, error) {
 
 	return t.mntnsSetMap, nil
 }
+
+func (tc *TracerCollection) TracerCgroupIDMap(id string) (*ebpf.Map, error) {
+	t, ok := tc.tracers[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown tracer %q", id)
+	}
+
+	return t.cgroupIDSetMap, nil
+}
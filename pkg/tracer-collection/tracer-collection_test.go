@@ -0,0 +1,83 @@
+// Copyright 2023 The Inspektor Gadget authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracercollection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	containercollection "github.com/inspektor-gadget/inspektor-gadget/pkg/container-collection"
+)
+
+type fakeSetMap map[interface{}]interface{}
+
+func (m fakeSetMap) Put(key, value interface{}) error {
+	m[key] = value
+	return nil
+}
+
+func (m fakeSetMap) Delete(key interface{}) error {
+	delete(m, key)
+	return nil
+}
+
+func TestSetMapsHostCgroups(t *testing.T) {
+	const hostMntns = 4026531840
+
+	kubelet := &containercollection.Container{
+		ID:         "hostcgroup-100",
+		Mntns:      hostMntns,
+		CgroupID:   100,
+		HostCgroup: true,
+	}
+	containerd := &containercollection.Container{
+		ID:         "hostcgroup-200",
+		Mntns:      hostMntns,
+		CgroupID:   200,
+		HostCgroup: true,
+	}
+	container := &containercollection.Container{
+		ID:       "abcde",
+		Mntns:    4026532000,
+		CgroupID: 300,
+	}
+
+	mntnsSetMap := fakeSetMap{}
+	cgroupIDSetMap := fakeSetMap{}
+	for _, c := range []*containercollection.Container{kubelet, containerd, container} {
+		addToSetMaps(mntnsSetMap, cgroupIDSetMap, c)
+	}
+
+	// The host mount namespace must never be selected
+	require.Equal(t, fakeSetMap{uint64(4026532000): uint32(1)}, mntnsSetMap)
+	require.Equal(t, fakeSetMap{
+		uint64(100): uint32(1),
+		uint64(200): uint32(1),
+		uint64(300): uint32(1),
+	}, cgroupIDSetMap)
+
+	// Removing one of the units, e.g. because it restarted, keeps the other
+	removeFromSetMaps(mntnsSetMap, cgroupIDSetMap, kubelet)
+	require.Equal(t, fakeSetMap{uint64(4026532000): uint32(1)}, mntnsSetMap)
+	require.Equal(t, fakeSetMap{
+		uint64(200): uint32(1),
+		uint64(300): uint32(1),
+	}, cgroupIDSetMap)
+
+	removeFromSetMaps(mntnsSetMap, cgroupIDSetMap, container)
+	require.Empty(t, mntnsSetMap)
+	require.Equal(t, fakeSetMap{uint64(200): uint32(1)}, cgroupIDSetMap)
+}
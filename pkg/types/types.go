@@ -28,7 +28,7 @@ var node string
 
 func init() {
 	// Register column templates
-	columns.MustRegisterTemplate("timestamp", "width:35,maxWidth:35,hide")
+	columns.MustRegisterTemplate("timestamp", "width:35,maxWidth:35,hide,unit:timestamp")
 	columns.MustRegisterTemplate("node", "width:30,ellipsis:middle")
 	columns.MustRegisterTemplate("namespace", "width:30")
 	columns.MustRegisterTemplate("pod", "width:30,ellipsis:middle")
@@ -92,6 +92,20 @@ type CommonData struct {
 
 	// HostNetwork is true if the container uses the host network namespace
 	HostNetwork bool `json:"hostNetwork,omitempty" column:"hostnetwork,hide"`
+
+	// Image of the container where the event comes from
+	ImageName   string `json:"imageName,omitempty" column:"imagename,hide" columnTags:"kubernetes,runtime"`
+	ImageTag    string `json:"imageTag,omitempty" column:"imagetag,hide" columnTags:"kubernetes,runtime"`
+	ImageDigest string `json:"imageDigest,omitempty" column:"imagedigest,width:20,hide" columnTags:"kubernetes,runtime"`
+
+	// ContainerLabels are the selected labels of the container where the
+	// event comes from
+	ContainerLabels map[string]string `json:"containerLabels,omitempty" column:"containerlabels,hide"`
+
+	// Workload owning the pod where the event comes from, e.g. a Deployment
+	// instead of its ReplicaSet, or the pod itself if it has no owner
+	WorkloadKind string `json:"workloadKind,omitempty" column:"workloadkind,width:12,hide" columnTags:"kubernetes"`
+	WorkloadName string `json:"workloadName,omitempty" column:"workloadname,width:30,hide" columnTags:"kubernetes"`
 }
 
 func (c *CommonData) SetNode(node string) {
@@ -109,6 +123,21 @@ func (c *CommonData) SetContainerInfo(pod, namespace, container string) {
 	}
 }
 
+func (c *CommonData) SetContainerImage(name, tag, digest string) {
+	c.ImageName = name
+	c.ImageTag = tag
+	c.ImageDigest = digest
+}
+
+func (c *CommonData) SetContainerLabels(labels map[string]string) {
+	c.ContainerLabels = labels
+}
+
+func (c *CommonData) SetWorkload(kind, name string) {
+	c.WorkloadKind = kind
+	c.WorkloadName = name
+}
+
 func (c *CommonData) GetNode() string {
 	return c.Node
 }
@@ -292,6 +321,27 @@ func (e *WithMountNsID) GetMountNSID() uint64 {
 	return e.MountNsID
 }
 
+// WithProcessInfo is embedded by events that can be enriched with the
+// ancestry of the process that generated them, see the ProcessTree operator
+type WithProcessInfo struct {
+	// Ancestors of the process as "comm(pid)", starting with the oldest one
+	Ancestors []string `json:"ancestors,omitempty" column:"ancestors,width:40,hide"`
+
+	// Entrypoint is the executable of the oldest ancestor in the same mount
+	// namespace, typically the entrypoint of the container
+	Entrypoint string `json:"entrypoint,omitempty" column:"entrypoint,width:20,hide"`
+
+	ExePath string `json:"exepath,omitempty" column:"exepath,width:20,hide"`
+	Cwd     string `json:"cwd,omitempty" column:"cwd,width:20,hide"`
+}
+
+func (e *WithProcessInfo) SetProcessInfo(ancestors []string, entrypoint, exePath, cwd string) {
+	e.Ancestors = ancestors
+	e.Entrypoint = entrypoint
+	e.ExePath = exePath
+	e.Cwd = cwd
+}
+
 type WithNetNsID struct {
 	NetNsID uint64 `json:"netnsid,omitempty" column:"netns,template:ns"`
 }